@@ -2,6 +2,7 @@
 package main
 
 import (
+	"errors"
 	"os"
 	"strings"
 
@@ -20,12 +21,20 @@ import (
 
 	cmds "github.com/rancher/fleet/internal/cmd/cli"
 	fleetapply "github.com/rancher/fleet/internal/cmd/cli/apply"
+	fleetgit "github.com/rancher/fleet/pkg/git"
 )
 
 func main() {
 	ctx := signals.SetupSignalContext()
 	cmd := cmds.App()
 	if err := cmd.ExecuteContext(ctx); err != nil {
+		// a dedicated exit code lets the gitjob controller detect failed
+		// signature verifications
+		code := 1
+		if errors.Is(err, fleetgit.ErrVerificationFailed) {
+			code = fleetgit.VerificationFailedExitCode
+		}
+
 		if strings.ToLower(os.Getenv(fleetapply.JSONOutputEnvVar)) == "true" {
 			log := logrus.New()
 			log.SetFormatter(&logrus.JSONFormatter{})
@@ -33,9 +42,10 @@ func main() {
 			// are not considered.
 			log.WithFields(logrus.Fields{
 				"fleetErrorMessage": err.Error(),
-			}).Fatal("Fleet cli failed")
+			}).Error("Fleet cli failed")
 		} else {
-			logrus.Fatal(err)
+			logrus.Error(err)
 		}
+		os.Exit(code)
 	}
 }
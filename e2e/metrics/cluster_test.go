@@ -28,11 +28,11 @@ var (
 		"fleet_cluster_resources_count_ready":        {},
 		"fleet_cluster_resources_count_unknown":      {},
 		"fleet_cluster_resources_count_waitapplied":  {},
-		// Expects three metrics with name `fleet_cluster_state` and each of
+		// Expects four metrics with name `fleet_cluster_state` and each of
 		// these metrics is expected to have a "state" label with values
-		// "NotReady", "Ready" and "WaitCheckIn".
+		// "NotReady", "Ready", "WaitCheckIn" and "Offline".
 		"fleet_cluster_state": {
-			"state": []string{"NotReady", "Ready", "WaitCheckIn"},
+			"state": []string{"NotReady", "Ready", "WaitCheckIn", "Offline"},
 		},
 	}
 )
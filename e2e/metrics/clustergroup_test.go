@@ -26,6 +26,7 @@ var _ = Describe("Cluster Metrics", Label("clustergroup"), func() {
 		"fleet_cluster_group_bundle_ready":                 {},
 		"fleet_cluster_group_cluster_count":                {},
 		"fleet_cluster_group_non_ready_cluster_count":      {},
+		"fleet_cluster_group_offline_cluster_count":        {},
 		"fleet_cluster_group_resource_count_desired_ready": {},
 		"fleet_cluster_group_resource_count_missing":       {},
 		"fleet_cluster_group_resource_count_modified":      {},
@@ -165,12 +165,13 @@ var _ = BeforeSuite(func() {
 	store := manifest.NewStore(mgr.GetClient())
 	builder := target.New(mgr.GetClient(), mgr.GetAPIReader())
 	err = (&ctrlreconciler.BundleReconciler{
-		Client:  mgr.GetClient(),
-		Scheme:  mgr.GetScheme(),
-		Builder: builder,
-		Store:   store,
-		Query:   builder,
-		Workers: 50,
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("fleet-bundle-ctrl"),
+		Builder:  builder,
+		Store:    store,
+		Query:    builder,
+		Workers:  50,
 	}).SetupWithManager(mgr)
 	Expect(err).ToNot(HaveOccurred(), "failed to set up manager")
 
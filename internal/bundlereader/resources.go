@@ -10,6 +10,7 @@ import (
 	"sort"
 	"sync"
 
+	"github.com/rancher/fleet/internal/sops"
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/sync/semaphore"
@@ -185,6 +186,11 @@ func generateValues(base string, chart *fleet.HelmOptions) (valuesMap *fleet.Gen
 		if err != nil {
 			return nil, fmt.Errorf("reading values file: %s/%s: %w", base, value, err)
 		}
+		// Encrypted values files are decrypted and merged by the agent.
+		if sops.IsEncrypted(value, valuesByte) {
+			chart.EncryptedValues = append(chart.EncryptedValues, string(valuesByte))
+			continue
+		}
 		tmpDataOpt := &fleet.GenericMap{}
 		err = yaml.Unmarshal(valuesByte, tmpDataOpt)
 		if err != nil {
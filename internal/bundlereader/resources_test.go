@@ -2,6 +2,8 @@ package bundlereader
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
@@ -95,3 +97,34 @@ func TestValueMerge(t *testing.T) {
 		}
 	}
 }
+
+func TestGenerateValuesKeepsEncryptedFiles(t *testing.T) {
+	encrypted, err := os.ReadFile("../sops/testdata/values.enc.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := t.TempDir()
+	if err := os.WriteFile(filepath.Join(base, "values.yaml"), []byte(valuesTwoYaml), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(base, "secrets.json"), encrypted, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	chart := &v1alpha1.HelmOptions{}
+	chart.ValuesFiles = []string{"values.yaml", "secrets.json"}
+	values, err := generateValues(base, chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := values.Data["microService2"]; !ok {
+		t.Errorf("expected unencrypted values to be merged, got %v", values.Data)
+	}
+	if _, ok := values.Data["token"]; ok {
+		t.Errorf("expected encrypted values not to be merged, got %v", values.Data)
+	}
+	if len(chart.EncryptedValues) != 1 || chart.EncryptedValues[0] != string(encrypted) {
+		t.Errorf("expected the encrypted values file to be kept, got %v", chart.EncryptedValues)
+	}
+}
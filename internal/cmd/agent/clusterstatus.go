@@ -2,6 +2,7 @@ package agent
 
 import (
 	"context"
+	"os"
 	"time"
 
 	"k8s.io/client-go/rest"
@@ -49,6 +50,8 @@ func (cs *ClusterStatusRunnable) Start(ctx context.Context) error {
 			cs.namespace,
 			cs.agentInfo.ClusterNamespace,
 			cs.agentInfo.ClusterName,
+			// set by the agent bundle, see manageagent
+			os.Getenv("AGENT_REVISION"),
 			checkinInterval,
 		)
 
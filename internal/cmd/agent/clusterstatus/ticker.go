@@ -21,17 +21,19 @@ type handler struct {
 	agentNamespace   string
 	clusterName      string
 	clusterNamespace string
+	revision         string
 	client           client.Client
 	reported         fleet.AgentStatus
 }
 
-func Ticker(ctx context.Context, client client.Client, agentNamespace string, clusterNamespace string, clusterName string, checkinInterval time.Duration) {
+func Ticker(ctx context.Context, client client.Client, agentNamespace string, clusterNamespace string, clusterName string, revision string, checkinInterval time.Duration) {
 	logger := log.FromContext(ctx).WithName("clusterstatus").WithValues("cluster", clusterName, "interval", checkinInterval)
 
 	h := handler{
 		agentNamespace:   agentNamespace,
 		clusterName:      clusterName,
 		clusterNamespace: clusterNamespace,
+		revision:         revision,
 		client:           client,
 	}
 
@@ -60,6 +62,7 @@ func (h *handler) Update(ctx context.Context) error {
 	agentStatus := fleet.AgentStatus{
 		LastSeen:  metav1.Now(),
 		Namespace: h.agentNamespace,
+		Revision:  h.revision,
 	}
 
 	if equality.Semantic.DeepEqual(h.reported, agentStatus) {
@@ -78,6 +81,7 @@ func (h *handler) Update(ctx context.Context) error {
 	patch := `[{"op":"add","path":"/status/agent","value":{"lastSeen":"` +
 		agentStatus.LastSeen.Format(time.RFC3339) +
 		`","namespace":"` + agentStatus.Namespace +
+		`","revision":"` + agentStatus.Revision +
 		`"}}]`
 
 	err := h.client.Status().Patch(ctx, cluster, client.RawPatch(types.JSONPatchType, []byte(patch)))
@@ -95,7 +95,7 @@ var _ = Describe("ClusterStatus Ticker", func() {
 	})
 
 	It("should patch the cluster status after checkinInterval", func() {
-		Ticker(ctx, clt, agentNamespace, clusterNamespace, clusterName, checkinInterval)
+		Ticker(ctx, clt, agentNamespace, clusterNamespace, clusterName, "revision", checkinInterval)
 		<-ctx.Done()
 	})
 })
@@ -2,7 +2,9 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -11,6 +13,7 @@ import (
 	"github.com/rancher/fleet/internal/cmd/agent/deployer/driftdetect"
 	"github.com/rancher/fleet/internal/cmd/agent/deployer/monitor"
 	"github.com/rancher/fleet/internal/experimental"
+	"github.com/rancher/fleet/internal/helmdeployer"
 	"github.com/rancher/fleet/internal/helmvalues"
 	"github.com/rancher/fleet/internal/namespaces"
 	fleetv1 "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
@@ -23,6 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	errutil "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -50,6 +54,10 @@ type BundleDeploymentReconciler struct {
 
 	DefaultNamespace string
 
+	// IgnoreManagers are the field managers from the agent config, whose
+	// fields are ignored for all bundle deployments.
+	IgnoreManagers []string
+
 	// AgentInfo is the labelSuffix used by the helm deployer
 	AgentScope string
 
@@ -77,13 +85,17 @@ func (r *BundleDeploymentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				predicate.AnnotationChangedPredicate{},
 				predicate.LabelChangedPredicate{},
 				predicate.Funcs{
-					// except for changes to status.Refresh
+					// except for changes to status.Refresh and ready sync waves
 					UpdateFunc: func(e event.UpdateEvent) bool {
 						n := e.ObjectNew.(*fleetv1.BundleDeployment)
 						o := e.ObjectOld.(*fleetv1.BundleDeployment)
 						if n == nil || o == nil {
 							return false
 						}
+						if n.Status.SyncWavesPending && n.Status.Ready && !o.Status.Ready {
+							// the resources of the current sync wave became ready
+							return true
+						}
 						return n.Status.SyncGeneration != o.Status.SyncGeneration
 					},
 					DeleteFunc: func(e event.DeleteEvent) bool {
@@ -141,20 +153,13 @@ func (r *BundleDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	}
 
 	// load the bundledeployment options from the secret, if present
-	if bd.Spec.ValuesHash != "" {
-		secret := &corev1.Secret{}
-		if err := r.Reader.Get(ctx, client.ObjectKey{Namespace: bd.Namespace, Name: bd.Name}, secret); err != nil {
-			return ctrl.Result{}, err
-		}
-
-		h := helmvalues.HashOptions(secret.Data[helmvalues.ValuesKey], secret.Data[helmvalues.StagedValuesKey])
-		if h != bd.Spec.ValuesHash {
-			return ctrl.Result{}, fmt.Errorf("retrying, hash mismatch between secret and bundledeployment: actual %s != expected %s", h, bd.Spec.ValuesHash)
-		}
+	if err := helmvalues.LoadOptions(ctx, r.Reader, bd); err != nil {
+		return ctrl.Result{}, err
+	}
+	addIgnoreManagers(bd, r.IgnoreManagers)
 
-		if err := helmvalues.SetOptions(bd, secret.Data); err != nil {
-			return ctrl.Result{}, err
-		}
+	if ptr.Deref(bd.Spec.Options.DryRun, false) {
+		return r.dryRun(ctx, key, orig, bd)
 	}
 
 	forceDeploy, err := r.copyResourcesFromUpstream(ctx, bd, logger)
@@ -168,7 +173,12 @@ func (r *BundleDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	if status, err := r.Deployer.DeployBundle(ctx, bd, forceDeploy); err != nil {
 		logger.V(1).Info("Failed to deploy bundle", "status", status, "error", err)
 
-		// do not use the returned status, instead set the condition and possibly a timestamp
+		// do not use the returned status, instead set the condition and possibly a timestamp.
+		// Conflicts are the exception, their status names the owning bundle.
+		var conflict *helmdeployer.ConflictError
+		if errors.As(err, &conflict) {
+			bd.Status = status
+		}
 		bd.Status = setCondition(bd.Status, err, monitor.Cond(fleetv1.BundleDeploymentConditionDeployed))
 
 		merr = append(merr, fmt.Errorf("failed deploying bundle: %w", err))
@@ -229,6 +239,47 @@ func (r *BundleDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		merr = append(merr, fmt.Errorf("failed final update to bundledeployment status: %w", err))
 	}
 
+	// the resources of the current sync wave are ready already, apply the next one
+	if len(merr) == 0 && bd.Status.SyncWavesPending && bd.Status.Ready {
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	return ctrl.Result{}, errutil.NewAggregate(merr)
+}
+
+// dryRun reports the changes deploying the bundle deployment would apply in
+// its status, without applying anything. Drift detection is stopped, as the
+// deployed resources are not managed while in dry run mode.
+func (r *BundleDeploymentReconciler) dryRun(ctx context.Context, key string, orig *fleetv1.BundleDeployment, bd *fleetv1.BundleDeployment) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var merr []error
+	if err := r.DriftDetect.Clear(key); err != nil {
+		merr = append(merr, fmt.Errorf("failed clearing drift detection: %w", err))
+	}
+
+	resources, err := r.Deployer.DryRun(ctx, bd)
+	if err == nil {
+		var dryRun *fleetv1.DryRunStatus
+		dryRun, err = r.Monitor.DryRun(ctx, bd, resources)
+		bd.Status.DryRun = dryRun
+	}
+	if err != nil {
+		logger.V(1).Info("Failed to dry run bundle", "error", err)
+		bd.Status = setCondition(bd.Status, err, monitor.Cond(fleetv1.BundleDeploymentConditionDeployed))
+		merr = append(merr, fmt.Errorf("failed dry run of bundle: %w", err))
+	} else {
+		logger.V(1).Info("Bundle dry run finished", "create", bd.Status.DryRun.Create, "update", bd.Status.DryRun.Update, "delete", bd.Status.DryRun.Delete)
+		bd.Status = setCondition(bd.Status, nil, monitor.Cond(fleetv1.BundleDeploymentConditionDeployed))
+		monitor.Cond(fleetv1.BundleDeploymentConditionReady).SetError(&bd.Status, "", errors.New(bd.Status.DryRun.String()))
+	}
+
+	if err := r.updateStatus(ctx, orig, bd); apierrors.IsNotFound(err) {
+		merr = append(merr, fmt.Errorf("bundledeployment has been deleted: %w", err))
+	} else if err != nil {
+		merr = append(merr, fmt.Errorf("failed final update to bundledeployment status: %w", err))
+	}
+
 	return ctrl.Result{}, errutil.NewAggregate(merr)
 }
 
@@ -361,3 +412,19 @@ func ignoreConflict(err error) error {
 	}
 	return err
 }
+
+// addIgnoreManagers adds the field managers, which are ignored for all
+// bundle deployments, to the diff options of bd.
+func addIgnoreManagers(bd *fleetv1.BundleDeployment, managers []string) {
+	if len(managers) == 0 {
+		return
+	}
+	if bd.Spec.Options.Diff == nil {
+		bd.Spec.Options.Diff = &fleetv1.DiffOptions{}
+	}
+	for _, m := range managers {
+		if !slices.Contains(bd.Spec.Options.Diff.IgnoreManagers, m) {
+			bd.Spec.Options.Diff.IgnoreManagers = append(bd.Spec.Options.Diff.IgnoreManagers, m)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/rancher/fleet/internal/cmd/agent/deployer"
 	"github.com/rancher/fleet/internal/cmd/agent/deployer/driftdetect"
 	"github.com/rancher/fleet/internal/cmd/agent/deployer/monitor"
+	"github.com/rancher/fleet/internal/helmvalues"
 	fleetv1 "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
 
 	"github.com/go-logr/logr"
@@ -29,6 +30,8 @@ import (
 
 type DriftReconciler struct {
 	client.Client
+	Reader client.Reader
+
 	Scheme *runtime.Scheme
 
 	Deployer    *deployer.Deployer
@@ -37,6 +40,10 @@ type DriftReconciler struct {
 
 	DriftChan chan event.TypedGenericEvent[*fleetv1.BundleDeployment]
 
+	// IgnoreManagers are the field managers from the agent config, whose
+	// fields are ignored for all bundle deployments.
+	IgnoreManagers []string
+
 	Workers int
 }
 
@@ -93,6 +100,8 @@ func (r *DriftReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return ctrl.Result{}, err
 	}
 
+	addIgnoreManagers(bd, r.IgnoreManagers)
+
 	merr := []error{}
 
 	// retrieve the resources from the helm history.
@@ -118,6 +127,11 @@ func (r *DriftReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	// run drift correction
 	if len(bd.Status.ModifiedStatus) > 0 && bd.Spec.CorrectDrift != nil && bd.Spec.CorrectDrift.Enabled {
 		logger.V(1).Info("Removing external changes")
+		// removing external changes might redeploy the bundle, which
+		// requires its helm values
+		if err := helmvalues.LoadOptions(ctx, r.Reader, bd); err != nil {
+			return ctrl.Result{}, err
+		}
 		if release, err := r.Deployer.RemoveExternalChanges(ctx, bd); err != nil {
 			merr = append(merr, fmt.Errorf("failed reconciling drift: %w", err))
 			// Propagate drift correction error to bundle deployment status.
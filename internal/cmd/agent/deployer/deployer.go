@@ -2,12 +2,14 @@ package deployer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
 	"strings"
 
 	"github.com/rancher/fleet/internal/bundlereader"
+	"github.com/rancher/fleet/internal/dependson"
 	"github.com/rancher/fleet/internal/helmdeployer"
 	"github.com/rancher/fleet/internal/manifest"
 	"github.com/rancher/fleet/internal/ocistorage"
@@ -18,8 +20,8 @@ import (
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -48,7 +50,24 @@ func (d *Deployer) Resources(name string, releaseID string) (*helmdeployer.Resou
 	return d.helm.Resources(name, releaseID)
 }
 
+// RemoveExternalChanges reverts changes made to the deployed resources outside
+// of fleet. Usually this is a helm rollback. A rollback would also revert the
+// fields owned by ignored field managers, so instead the bundle is upgraded,
+// which keeps their live values. Bundles deployed with server-side apply,
+// have no helm release and are applied again.
 func (d *Deployer) RemoveExternalChanges(ctx context.Context, bd *fleet.BundleDeployment) (string, error) {
+	if ptr.Deref(bd.Spec.Options.ServerSideApply, false) {
+		logger := log.FromContext(ctx).WithName("remove-external-changes")
+		logger.Info("Drift correction: server-side apply")
+		releaseID, _, err := d.helmdeploy(ctx, logger, bd, true)
+		return releaseID, err
+	}
+	if bd.Spec.Options.Diff != nil && len(bd.Spec.Options.Diff.IgnoreManagers) > 0 {
+		logger := log.FromContext(ctx).WithName("remove-external-changes")
+		logger.Info("Drift correction: upgrade, keeping fields of ignored field managers")
+		releaseID, _, err := d.helmdeploy(ctx, logger, bd, true)
+		return releaseID, err
+	}
 	return d.helm.RemoveExternalChanges(ctx, bd)
 }
 
@@ -62,6 +81,7 @@ func (d *Deployer) DeployBundle(
 	force bool,
 ) (fleet.BundleDeploymentStatus, error) {
 	status := bd.Status
+	status.DryRun = nil
 	logger := log.FromContext(ctx).WithName("deploy-bundle").WithValues("deploymentID", bd.Spec.DeploymentID, "appliedDeploymentID", status.AppliedDeploymentID)
 
 	if err := d.checkDependency(ctx, bd); err != nil {
@@ -69,7 +89,14 @@ func (d *Deployer) DeployBundle(
 		return status, err
 	}
 
-	releaseID, err := d.helmdeploy(ctx, logger, bd, force)
+	releaseID, syncWave, err := d.helmdeploy(ctx, logger, bd, force)
+
+	// Conflicts are returned as an error, so deploying is retried until the
+	// owning bundle releases the resources.
+	var conflict *helmdeployer.ConflictError
+	if errors.As(err, &conflict) {
+		return conflictToStatus(conflict, status), err
+	}
 
 	if err != nil {
 		// When an error from DeployBundle is returned it causes DeployBundle
@@ -93,6 +120,9 @@ func (d *Deployer) DeployBundle(
 	}
 	status.Release = releaseID
 	status.AppliedDeploymentID = bd.Spec.DeploymentID
+	if syncWave != nil {
+		setSyncWave(&status, *syncWave)
+	}
 
 	if err := d.setNamespaceLabelsAndAnnotations(ctx, bd, releaseID); err != nil {
 		return fleet.BundleDeploymentStatus{}, err
@@ -100,6 +130,7 @@ func (d *Deployer) DeployBundle(
 
 	// Setting the error to nil clears any existing error
 	condition.Cond(fleet.BundleDeploymentConditionInstalled).SetError(&status, "", nil)
+	setConflictCondition(&status, nil)
 	return status, nil
 }
 
@@ -107,14 +138,56 @@ func (d *Deployer) DeployBundle(
 // This loads the manifest and the contents from the upstream cluster.
 // If force is true, checks on whether the bundle deployment exists will be skipped, leading to the bundle deployment
 // being updated even if its deployment ID has not changed.
-func (d *Deployer) helmdeploy(ctx context.Context, logger logr.Logger, bd *fleet.BundleDeployment, force bool) (string, error) {
-	if !force && bd.Spec.DeploymentID == bd.Status.AppliedDeploymentID {
-		if ok, err := d.helm.EnsureInstalled(bd.Name, bd.Status.Release); err != nil {
-			return "", err
-		} else if ok {
-			return bd.Status.Release, nil
+// New resources are applied sync wave by sync wave, the next wave is only
+// applied once the resources of the previous waves are ready. Resources,
+// which are already deployed, are updated right away. The returned sync wave
+// is nil if nothing was deployed.
+func (d *Deployer) helmdeploy(ctx context.Context, logger logr.Logger, bd *fleet.BundleDeployment, force bool) (string, *helmdeployer.SyncWave, error) {
+	var wave *int
+	if bd.Spec.DeploymentID == bd.Status.AppliedDeploymentID {
+		wave = bd.Status.SyncWave
+		if bd.Status.SyncWavesPending && bd.Status.Ready && wave != nil {
+			next := *wave + 1
+			wave = &next
+			logger.Info("Sync wave is ready, applying next sync wave", "syncWave", *bd.Status.SyncWave)
+		} else if !force {
+			if ok, err := d.helm.EnsureInstalled(bd.Name, bd.Status.Release); err != nil {
+				return "", nil, err
+			} else if ok {
+				return bd.Status.Release, nil, nil
+			}
 		}
 	}
+	m, err := d.manifest(ctx, bd)
+	if err != nil {
+		return "", nil, err
+	}
+
+	release, syncWave, err := d.helm.DeploySyncWave(ctx, bd.Name, m, bd.Spec.Options, wave)
+	if err != nil {
+		return "", nil, err
+	}
+
+	resourceID := helmdeployer.ReleaseToResourceID(release)
+
+	logger.Info("Deployed bundle", "release", resourceID, "DeploymentID", bd.Spec.DeploymentID, "syncWave", syncWave.Wave, "syncWavesPending", syncWave.Pending)
+
+	return resourceID, &syncWave, nil
+}
+
+// DryRun renders the bundle deployment without applying it. It returns the
+// resources the helm release would contain.
+func (d *Deployer) DryRun(ctx context.Context, bd *fleet.BundleDeployment) (*helmdeployer.Resources, error) {
+	m, err := d.manifest(ctx, bd)
+	if err != nil {
+		return nil, err
+	}
+	return d.helm.DryRun(ctx, bd.Name, m, bd.Spec.Options)
+}
+
+// manifest loads the manifest of the bundle deployment and its contents from
+// the upstream cluster.
+func (d *Deployer) manifest(ctx context.Context, bd *fleet.BundleDeployment) (*manifest.Manifest, error) {
 	manifestID, _ := kv.Split(bd.Spec.DeploymentID, ":")
 	var (
 		m   *manifest.Manifest
@@ -125,45 +198,49 @@ func (d *Deployer) helmdeploy(ctx context.Context, logger logr.Logger, bd *fleet
 		secretID := client.ObjectKey{Name: manifestID, Namespace: bd.Namespace}
 		opts, err := ocistorage.ReadOptsFromSecret(ctx, d.upstreamClient, secretID)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		m, err = oci.PullManifest(ctx, opts, manifestID)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		// Verify that the calculated manifestID for the manifest
 		// we just downloaded matches the expected one.
 		// Otherwise, the manifest will be considered incorrect or corrupted.
 		actualID, err := m.ID()
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		if actualID != manifestID {
-			return "", fmt.Errorf("invalid or corrupt manifest. Expecting id: %q, got %q", manifestID, actualID)
+			return nil, fmt.Errorf("invalid or corrupt manifest. Expecting id: %q, got %q", manifestID, actualID)
 		}
 	} else if bd.Spec.HelmChartOptions != nil {
 		m, err = bundlereader.GetManifestFromHelmChart(ctx, d.upstreamClient, bd)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 	} else {
 		m, err = d.lookup.Get(ctx, d.upstreamClient, manifestID)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 	}
 
 	m.Commit = bd.Labels[fleet.CommitLabel]
-	release, err := d.helm.Deploy(ctx, bd.Name, m, bd.Spec.Options)
-	if err != nil {
-		return "", err
-	}
-
-	resourceID := helmdeployer.ReleaseToResourceID(release)
-
-	logger.Info("Deployed bundle", "release", resourceID, "DeploymentID", bd.Spec.DeploymentID)
+	return m, nil
+}
 
-	return resourceID, nil
+// setSyncWave records the applied sync wave in the status. Bundles, whose
+// resources all belong to the same sync wave, do not report it.
+func setSyncWave(status *fleet.BundleDeploymentStatus, syncWave helmdeployer.SyncWave) {
+	if len(syncWave.Waves) < 2 {
+		status.SyncWave = nil
+		status.SyncWavesPending = false
+		return
+	}
+	wave := syncWave.Wave
+	status.SyncWave = &wave
+	status.SyncWavesPending = syncWave.Pending
 }
 
 // setNamespaceLabelsAndAnnotations updates the namespace for the release, applying all labels and annotations to that namespace as configured in the bundle spec.
@@ -306,45 +383,70 @@ func deployErrToStatus(err error, status fleet.BundleDeploymentStatus) (bool, fl
 	return false, status
 }
 
+// conflictToStatus converts a conflict with the resources of another bundle
+// into a status update.
+func conflictToStatus(conflict *helmdeployer.ConflictError, status fleet.BundleDeploymentStatus) fleet.BundleDeploymentStatus {
+	status.Ready = false
+	status.NonModified = true
+	setConflictCondition(&status, conflict)
+	condition.Cond(fleet.BundleDeploymentConditionReady).SetError(&status, "", fmt.Errorf("not ready: %w", conflict))
+	condition.Cond(fleet.BundleDeploymentConditionInstalled).SetError(&status, "", fmt.Errorf("not installed: %w", conflict))
+	return status
+}
+
+// setConflictCondition reflects the conflict in the Conflict condition. The
+// condition is only added to bundle deployments, which had a conflict at
+// least once.
+func setConflictCondition(status *fleet.BundleDeploymentStatus, conflict *helmdeployer.ConflictError) {
+	c := condition.Cond(fleet.BundleDeploymentConditionConflict)
+	if conflict == nil {
+		if c.GetStatus(status) != "" {
+			c.SetStatusBool(status, false)
+			c.Message(status, "")
+		}
+		return
+	}
+
+	c.SetStatusBool(status, true)
+	c.Message(status, conflict.Error())
+}
+
 func (d *Deployer) checkDependency(ctx context.Context, bd *fleet.BundleDeployment) error {
 	var depBundleList []string
 	bundleNamespace := bd.Labels[fleet.BundleNamespaceLabel]
 	for _, depend := range bd.Spec.DependsOn {
 		// skip empty BundleRef definitions. Possible if there is a typo in the yaml
-		if depend.Name != "" || depend.Selector != nil {
-			ls := &metav1.LabelSelector{}
-			if depend.Selector != nil {
-				ls = depend.Selector
-			}
-
-			// depend.Name is just a shortcut for matchLabels: {bundle-name: name}
-			if depend.Name != "" {
-				ls = metav1.AddLabelToSelector(ls, fleet.BundleLabel, depend.Name)
-				ls = metav1.AddLabelToSelector(ls, fleet.BundleNamespaceLabel, bundleNamespace)
-			}
+		if dependson.Empty(depend) {
+			continue
+		}
 
-			selector, err := metav1.LabelSelectorAsSelector(ls)
-			if err != nil {
-				return err
-			}
+		selector, err := dependson.Selector(depend, bundleNamespace)
+		if err != nil {
+			return err
+		}
 
-			bds := fleet.BundleDeploymentList{}
-			err = d.upstreamClient.List(ctx, &bds, client.MatchingLabelsSelector{Selector: selector}, client.InNamespace(bd.Namespace))
-			if err != nil {
-				return err
-			}
+		// bundle deployments of all bundle namespaces, which target this
+		// cluster, are in the cluster's namespace.
+		bds := fleet.BundleDeploymentList{}
+		err = d.upstreamClient.List(ctx, &bds, client.MatchingLabelsSelector{Selector: selector}, client.InNamespace(bd.Namespace))
+		if err != nil {
+			return err
+		}
 
-			if len(bds.Items) == 0 {
-				return fmt.Errorf("list bundledeployments: no bundles matching labels %s in namespace %s", selector.String(), bundleNamespace)
+		if len(bds.Items) == 0 {
+			ns := depend.Namespace
+			if ns == "" {
+				ns = bundleNamespace
 			}
+			return fmt.Errorf("list bundledeployments: no bundles matching labels %s in namespace %s", selector.String(), ns)
+		}
 
-			for _, depBundle := range bds.Items {
-				c := condition.Cond("Ready")
-				if c.IsTrue(depBundle) {
-					continue
-				} else {
-					depBundleList = append(depBundleList, depBundle.Name)
-				}
+		for _, depBundle := range bds.Items {
+			c := condition.Cond("Ready")
+			if c.IsTrue(depBundle) {
+				continue
+			} else {
+				depBundleList = append(depBundleList, depBundle.Name)
 			}
 		}
 	}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"testing"
 
+	"github.com/rancher/fleet/internal/helmdeployer"
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
 
+	"github.com/rancher/wrangler/v3/pkg/condition"
+
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -172,3 +175,53 @@ func TestSetNamespaceLabelsAndAnnotationsError(t *testing.T) {
 		t.Errorf("expected not found error: got %v", err)
 	}
 }
+
+func TestSetSyncWave(t *testing.T) {
+	wave := 3
+	status := fleet.BundleDeploymentStatus{SyncWave: &wave, SyncWavesPending: true}
+
+	setSyncWave(&status, helmdeployer.SyncWave{Wave: 0, Waves: []int{-1, 0, 5}, Pending: true})
+	if status.SyncWave == nil || *status.SyncWave != 0 || !status.SyncWavesPending {
+		t.Errorf("expected sync wave 0 to be pending, got %v %v", status.SyncWave, status.SyncWavesPending)
+	}
+	if wave != 3 {
+		t.Errorf("expected previous sync wave not to be modified")
+	}
+
+	setSyncWave(&status, helmdeployer.SyncWave{Wave: 5, Waves: []int{-1, 0, 5}})
+	if status.SyncWave == nil || *status.SyncWave != 5 || status.SyncWavesPending {
+		t.Errorf("expected sync wave 5 to be complete, got %v %v", status.SyncWave, status.SyncWavesPending)
+	}
+
+	setSyncWave(&status, helmdeployer.SyncWave{Wave: 0, Waves: []int{0}})
+	if status.SyncWave != nil || status.SyncWavesPending {
+		t.Errorf("expected no sync wave for a single wave, got %v %v", status.SyncWave, status.SyncWavesPending)
+	}
+}
+
+func TestConflictToStatus(t *testing.T) {
+	conflict := &helmdeployer.ConflictError{Owner: "other", Resources: []string{"ConfigMap default/cm"}}
+	status := conflictToStatus(conflict, fleet.BundleDeploymentStatus{Ready: true})
+
+	if status.Ready {
+		t.Errorf("expected bundle deployment not to be ready")
+	}
+	c := condition.Cond(fleet.BundleDeploymentConditionConflict)
+	if !c.IsTrue(&status) || c.GetMessage(&status) != conflict.Error() {
+		t.Errorf("expected conflict condition naming the owner, got %v", status.Conditions)
+	}
+	if !condition.Cond(fleet.BundleDeploymentConditionInstalled).IsFalse(&status) {
+		t.Errorf("expected installed condition to be false, got %v", status.Conditions)
+	}
+
+	setConflictCondition(&status, nil)
+	if !c.IsFalse(&status) || c.GetMessage(&status) != "" {
+		t.Errorf("expected conflict condition to be cleared, got %v", status.Conditions)
+	}
+
+	status = fleet.BundleDeploymentStatus{}
+	setConflictCondition(&status, nil)
+	if len(status.Conditions) != 0 {
+		t.Errorf("expected no conflict condition, got %v", status.Conditions)
+	}
+}
@@ -31,6 +31,11 @@ func Diff(plan Plan, bd *fleet.BundleDeployment, ns string, objs ...runtime.Obje
 		return plan, err
 	}
 
+	var ignoreManagers []string
+	if bd.Spec.Options.Diff != nil {
+		ignoreManagers = bd.Spec.Options.Diff.IgnoreManagers
+	}
+
 	var errs []error
 	if bd.Spec.Options.Diff != nil {
 		toIgnore := objectset.ObjectKeyByGVK{}
@@ -89,7 +94,8 @@ func Diff(plan Plan, bd *fleet.BundleDeployment, ns string, objs ...runtime.Obje
 
 			diffResult, err := diff.Diff(desiredObj.(*unstructured.Unstructured), actualObj.(*unstructured.Unstructured),
 				diff.WithNormalizer(norms),
-				diff.IgnoreAggregatedRoles(true))
+				diff.IgnoreAggregatedRoles(true),
+				diff.IgnoreManagers(ignoreManagers...))
 			if err != nil {
 				errs = append(errs, err)
 				continue
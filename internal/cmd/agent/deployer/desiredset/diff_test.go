@@ -1,11 +1,14 @@
 package desiredset_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/rancher/fleet/internal/cmd/agent/deployer/desiredset"
 	"github.com/rancher/fleet/internal/cmd/agent/deployer/objectset"
 	"github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
@@ -67,3 +70,75 @@ func Test_Diff_IgnoreResources(t *testing.T) {
 		t.Errorf("unexpected plan.Create length: expected %d, got %d", lenBefore-1, len(plan.Create[gvk]))
 	}
 }
+
+func Test_Diff_IgnoreManagers(t *testing.T) {
+	ns := "fleet-local"
+	gvk := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+
+	deployment := func(replicas int64, image string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":      "app",
+				"namespace": ns,
+			},
+			"spec": map[string]interface{}{
+				"replicas": replicas,
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{"name": "app", "image": image},
+						},
+					},
+				},
+			},
+		}}
+	}
+
+	tests := map[string]struct {
+		image    string
+		modified bool
+	}{
+		"only fields of ignored managers changed": {image: "nginx:1.27"},
+		"other fields changed":                    {image: "nginx:latest", modified: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			live := deployment(5, test.image)
+			live.SetManagedFields([]metav1.ManagedFieldsEntry{{
+				Manager:  "horizontal-pod-autoscaler",
+				FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{}}}`)},
+			}})
+
+			plan := desiredset.Plan{
+				Update:  desiredset.PatchByGVK{},
+				Objects: []runtime.Object{live},
+			}
+			plan.Update.Set(gvk, ns, "app", "{}")
+
+			bd := v1alpha1.BundleDeployment{
+				Spec: v1alpha1.BundleDeploymentSpec{
+					Options: v1alpha1.BundleDeploymentOptions{
+						Diff: &v1alpha1.DiffOptions{
+							IgnoreManagers: []string{"horizontal-pod-autoscaler"},
+						},
+					},
+				},
+			}
+
+			plan, err := desiredset.Diff(plan, &bd, ns, deployment(2, "nginx:1.27"))
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			patch, modified := plan.Update[gvk][objectset.ObjectKey{Namespace: ns, Name: "app"}]
+			if modified != test.modified {
+				t.Errorf("unexpected modification: expected %t, got %t with patch %q", test.modified, modified, patch)
+			}
+			if strings.Contains(patch, "replicas") {
+				t.Errorf("patch should not contain the ignored replicas field: %q", patch)
+			}
+		})
+	}
+}
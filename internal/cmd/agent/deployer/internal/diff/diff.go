@@ -24,6 +24,7 @@ import (
 	jsonutil "github.com/rancher/fleet/internal/cmd/agent/deployer/internal/diff/json"
 	"github.com/rancher/fleet/internal/cmd/agent/deployer/internal/diff/kubernetes_vendor/pkg/api/v1/endpoints"
 	kubescheme "github.com/rancher/fleet/internal/cmd/agent/deployer/internal/diff/scheme"
+	"github.com/rancher/fleet/internal/cmd/agent/deployer/managedfields"
 )
 
 const couldNotMarshalErrMsg = "Could not unmarshal to object of type %s: %v"
@@ -65,13 +66,19 @@ func GetNoopNormalizer() Normalizer {
 // "kubectl.kubernetes.io/last-applied-configuration", then perform a three way diff.
 func Diff(config, live *unstructured.Unstructured, opts ...Option) (*DiffResult, error) {
 	o := applyOptions(opts)
+	var ignored []managedfields.Path
+	if live != nil {
+		ignored = managedfields.Paths(live, o.ignoreManagers)
+	}
 	if config != nil {
 		config = remarshal(config, o)
 		Normalize(config, opts...)
+		removeFields(config, ignored)
 	}
 	if live != nil {
 		live = remarshal(live, o)
 		Normalize(live, opts...)
+		removeFields(live, ignored)
 	}
 	orig, err := GetLastAppliedConfigAnnotation(live)
 	if err != nil {
@@ -79,6 +86,7 @@ func Diff(config, live *unstructured.Unstructured, opts ...Option) (*DiffResult,
 	} else {
 		if orig != nil && config != nil {
 			Normalize(orig, opts...)
+			removeFields(orig, ignored)
 			dr, err := ThreeWayDiff(orig, config, live)
 			if err == nil {
 				return dr, nil
@@ -89,6 +97,14 @@ func Diff(config, live *unstructured.Unstructured, opts ...Option) (*DiffResult,
 	return TwoWayDiff(config, live)
 }
 
+// removeFields removes the fields at paths, e.g. the fields owned by ignored
+// field managers, from the object.
+func removeFields(un *unstructured.Unstructured, paths []managedfields.Path) {
+	for _, path := range paths {
+		managedfields.Remove(un.Object, path)
+	}
+}
+
 // TwoWayDiff performs a three-way diff and uses specified config as a recently applied config
 func TwoWayDiff(config, live *unstructured.Unstructured) (*DiffResult, error) {
 	if live != nil && config != nil {
@@ -14,6 +14,8 @@ type options struct {
 	ignoreAggregatedRoles bool
 	normalizer            Normalizer
 	log                   logr.Logger
+	// Fields owned by these field managers in the live object are ignored.
+	ignoreManagers []string
 }
 
 func applyOptions(opts []Option) options {
@@ -45,3 +47,9 @@ func WithLogr(log logr.Logger) Option {
 		o.log = log
 	}
 }
+
+func IgnoreManagers(managers ...string) Option {
+	return func(o *options) {
+		o.ignoreManagers = managers
+	}
+}
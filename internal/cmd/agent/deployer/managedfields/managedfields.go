@@ -0,0 +1,270 @@
+// Package managedfields resolves the fields owned by field managers, as
+// recorded in the managed fields of a resource, in unstructured objects.
+package managedfields
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Element is a step in a Path. Exactly one of its fields is set.
+type Element struct {
+	// Field is the key in a map.
+	Field *string
+	// Key selects the list item, whose fields have these values.
+	Key map[string]interface{}
+	// Value selects the item of a set, which is equal to the value.
+	Value interface{}
+	// Index selects a list item by its position.
+	Index *int
+}
+
+// Path is the path to a field, as used in the FieldsV1 format.
+type Path []Element
+
+// Paths returns the paths of the fields, which are owned by one of the
+// managers, according to the managed fields of obj. Fields owned by several
+// managers are returned once.
+func Paths(obj *unstructured.Unstructured, managers []string) []Path {
+	if len(managers) == 0 {
+		return nil
+	}
+
+	var result []Path
+	seen := map[string]struct{}{}
+	for _, entry := range obj.GetManagedFields() {
+		if !slices.Contains(managers, entry.Manager) || entry.FieldsV1 == nil {
+			continue
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			continue
+		}
+		collect(nil, fields, func(path Path, raw string) {
+			if _, ok := seen[raw]; ok {
+				return
+			}
+			seen[raw] = struct{}{}
+			result = append(result, path)
+		})
+	}
+	return result
+}
+
+// collect calls fn for each leaf of the field set. raw is a string
+// representation of the path, which is used to remove duplicates.
+func collect(path Path, fields map[string]interface{}, fn func(Path, string)) {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		if k != "." {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		e, ok := parseElement(k)
+		if !ok {
+			continue
+		}
+		p := append(append(Path{}, path...), e)
+		children, _ := fields[k].(map[string]interface{})
+		if isLeaf(children) {
+			fn(p, raw(p))
+			continue
+		}
+		collect(p, children, fn)
+	}
+}
+
+// isLeaf is true, if the field set does not contain any child fields.
+func isLeaf(fields map[string]interface{}) bool {
+	for k := range fields {
+		if k != "." {
+			return false
+		}
+	}
+	return true
+}
+
+func parseElement(s string) (Element, bool) {
+	prefix, value, ok := strings.Cut(s, ":")
+	if !ok {
+		return Element{}, false
+	}
+	switch prefix {
+	case "f":
+		return Element{Field: &value}, true
+	case "k":
+		var key map[string]interface{}
+		if err := json.Unmarshal([]byte(value), &key); err != nil {
+			return Element{}, false
+		}
+		return Element{Key: key}, true
+	case "v":
+		var v interface{}
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			return Element{}, false
+		}
+		return Element{Value: v}, true
+	case "i":
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return Element{}, false
+		}
+		return Element{Index: &i}, true
+	}
+	return Element{}, false
+}
+
+func raw(path Path) string {
+	data, _ := json.Marshal(path)
+	return string(data)
+}
+
+// Get returns the value at path in obj.
+func Get(obj map[string]interface{}, path Path) (interface{}, bool) {
+	var node interface{} = obj
+	for _, e := range path {
+		next, ok := child(node, e)
+		if !ok {
+			return nil, false
+		}
+		node = next
+	}
+	return node, true
+}
+
+// Set sets the value at path in obj. Nothing is changed, if the parent of
+// the field does not exist in obj.
+func Set(obj map[string]interface{}, path Path, value interface{}) bool {
+	if len(path) == 0 {
+		return false
+	}
+	parent, ok := Get(obj, path[:len(path)-1])
+	if !ok {
+		return false
+	}
+	e := path[len(path)-1]
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		if e.Field == nil {
+			return false
+		}
+		p[*e.Field] = value
+		return true
+	case []interface{}:
+		i := index(p, e)
+		if i < 0 {
+			return false
+		}
+		p[i] = value
+		return true
+	}
+	return false
+}
+
+// Remove removes the field at path from obj, if present.
+func Remove(obj map[string]interface{}, path Path) {
+	if len(path) == 0 {
+		return
+	}
+	parentPath := path[:len(path)-1]
+	parent, ok := Get(obj, parentPath)
+	if !ok {
+		return
+	}
+	e := path[len(path)-1]
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		if e.Field != nil {
+			delete(p, *e.Field)
+		}
+	case []interface{}:
+		i := index(p, e)
+		if i < 0 {
+			return
+		}
+		list := slices.Delete(p, i, i+1)
+		if len(parentPath) == 0 {
+			return
+		}
+		// the list is shortened, store it in its parent
+		Set(obj, parentPath, list)
+	}
+}
+
+func child(node interface{}, e Element) (interface{}, bool) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if e.Field == nil {
+			return nil, false
+		}
+		v, ok := n[*e.Field]
+		return v, ok
+	case []interface{}:
+		i := index(n, e)
+		if i < 0 {
+			return nil, false
+		}
+		return n[i], true
+	}
+	return nil, false
+}
+
+// index returns the position of the list item selected by e, or -1.
+func index(list []interface{}, e Element) int {
+	switch {
+	case e.Index != nil:
+		if *e.Index < 0 || *e.Index >= len(list) {
+			return -1
+		}
+		return *e.Index
+	case e.Key != nil:
+		for i, item := range list {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if matchesKey(m, e.Key) {
+				return i
+			}
+		}
+	case e.Value != nil:
+		for i, item := range list {
+			if reflect.DeepEqual(normalize(item), e.Value) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func matchesKey(item map[string]interface{}, key map[string]interface{}) bool {
+	for k, v := range key {
+		if !reflect.DeepEqual(normalize(item[k]), v) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalize converts a value to the types json.Unmarshal produces, so it can
+// be compared to the values from the managed fields.
+func normalize(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var result interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return v
+	}
+	return result
+}
@@ -0,0 +1,110 @@
+package managedfields
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newObject() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				"cert-manager.io/inject-ca-from": "ns/cert",
+			},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(5),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app", "image": "nginx", "args": []interface{}{"a", "b"}},
+						map[string]interface{}{"name": "sidecar", "image": "proxy"},
+					},
+				},
+			},
+		},
+	}}
+	obj.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{
+			Manager:  "helm",
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{},"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"app\"}":{".":{},"f:image":{},"f:name":{}}}}}}}`)},
+		},
+		{
+			Manager:  "horizontal-pod-autoscaler",
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{}}}`)},
+		},
+		{
+			Manager:  "mesh",
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:annotations":{"f:cert-manager.io/inject-ca-from":{}}},"f:spec":{"f:replicas":{},"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"app\"}":{"f:args":{"v:\"b\"":{}}},"k:{\"name\":\"sidecar\"}":{".":{},"f:image":{},"f:name":{}}}}}}}`)},
+		},
+	})
+	return obj
+}
+
+func TestPaths(t *testing.T) {
+	obj := newObject()
+
+	assert.Empty(t, Paths(obj, nil))
+	assert.Empty(t, Paths(obj, []string{"unknown"}))
+
+	paths := Paths(obj, []string{"horizontal-pod-autoscaler", "mesh"})
+	// replicas is owned by both managers, but only returned once
+	assert.Len(t, paths, 5)
+
+	var values []interface{}
+	for _, p := range paths {
+		v, ok := Get(obj.Object, p)
+		assert.True(t, ok)
+		values = append(values, v)
+	}
+	assert.Equal(t, []interface{}{int64(5), "ns/cert", "b", "proxy", "sidecar"}, values)
+}
+
+func TestRemove(t *testing.T) {
+	obj := newObject()
+	for _, p := range Paths(obj, []string{"mesh"}) {
+		Remove(obj.Object, p)
+	}
+
+	_, found, _ := unstructured.NestedFieldNoCopy(obj.Object, "spec", "replicas")
+	assert.False(t, found)
+	assert.Empty(t, obj.GetAnnotations())
+	containers, _, _ := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "app", "image": "nginx", "args": []interface{}{"a"}},
+		map[string]interface{}{},
+	}, containers)
+
+	// removing missing fields is a no-op
+	Remove(obj.Object, Paths(newObject(), []string{"mesh"})[0])
+}
+
+func TestSet(t *testing.T) {
+	live := newObject()
+	desired := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": int64(2),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app", "image": "nginx", "args": []interface{}{"a", "b"}},
+					},
+				},
+			},
+		},
+	}}
+
+	var set []bool
+	for _, p := range Paths(live, []string{"mesh"}) {
+		v, _ := Get(live.Object, p)
+		set = append(set, Set(desired.Object, p, v))
+	}
+
+	// the annotations and the sidecar container do not exist in desired
+	assert.Equal(t, []bool{false, true, true, false, false}, set)
+	assert.Equal(t, int64(5), desired.Object["spec"].(map[string]interface{})["replicas"])
+}
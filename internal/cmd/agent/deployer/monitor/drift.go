@@ -0,0 +1,209 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// limit the number of drifted fields reported per resource
+	driftMaxFields = 10
+	// limit the length of the values of drifted fields
+	driftValueMaxLength = 256
+	// replaces the values of secret data in reported patches
+	redactedValue = "<redacted>"
+)
+
+var identifier = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// fieldDrift returns the fields, which the JSON merge patch would change to
+// restore the desired state of the live object. For each field the manager,
+// which last wrote it, is looked up in the managed fields of the live object.
+// The values of secret data are not reported, only their paths.
+func fieldDrift(patch string, live *unstructured.Unstructured) ([]fleet.FieldDrift, error) {
+	var p map[string]interface{}
+	if err := json.Unmarshal([]byte(patch), &p); err != nil {
+		return nil, err
+	}
+
+	var paths [][]string
+	result := driftedFields(nil, p, live.Object, &paths)
+	if len(result) > driftMaxFields {
+		result = result[:driftMaxFields]
+		paths = paths[:driftMaxFields]
+	}
+
+	secret := isSecret(live)
+	managed := live.GetManagedFields()
+	for i := range result {
+		result[i].Manager = fieldManager(managed, paths[i])
+		if secret && isSecretData(paths[i]) {
+			result[i].Desired = ""
+			result[i].Live = ""
+		}
+	}
+
+	return result, nil
+}
+
+// isSecret returns true for core v1 secrets, whose values must not be
+// copied into the status of bundle deployments.
+func isSecret(obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+	return gvk.Group == "" && gvk.Kind == "Secret"
+}
+
+// isSecretData returns true if the path is the data of a secret or a field
+// of it.
+func isSecretData(path []string) bool {
+	return len(path) > 0 && (path[0] == "data" || path[0] == "stringData")
+}
+
+// redactSecretPatch replaces the values of the secret data in the patch of a
+// secret, so only the changed keys are reported. Patches, which cannot be
+// parsed, are not reported at all.
+func redactSecretPatch(patch string) string {
+	var p map[string]interface{}
+	if err := json.Unmarshal([]byte(patch), &p); err != nil {
+		return ""
+	}
+	for _, field := range []string{"data", "stringData"} {
+		switch data := p[field].(type) {
+		case nil:
+		case map[string]interface{}:
+			for k, v := range data {
+				// null removes the key
+				if v != nil {
+					data[k] = redactedValue
+				}
+			}
+		default:
+			p[field] = redactedValue
+		}
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// driftedFields walks the merge patch and the live object in parallel. Every
+// value of the patch, which is not merged into a map of the live object, is a
+// drifted field.
+func driftedFields(path []string, patch map[string]interface{}, live interface{}, paths *[][]string) []fleet.FieldDrift {
+	keys := make([]string, 0, len(patch))
+	for k := range patch {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	liveMap, _ := live.(map[string]interface{})
+
+	var result []fleet.FieldDrift
+	for _, k := range keys {
+		fieldPath := append(append([]string{}, path...), k)
+		desired := patch[k]
+		liveValue, exists := liveMap[k]
+
+		desiredMap, ok := desired.(map[string]interface{})
+		if _, isMap := liveValue.(map[string]interface{}); ok && isMap {
+			result = append(result, driftedFields(fieldPath, desiredMap, liveValue, paths)...)
+			continue
+		}
+
+		drift := fleet.FieldDrift{Path: jsonPath(fieldPath)}
+		// null removes the field in a merge patch
+		if desired != nil {
+			drift.Desired = encodeValue(desired)
+		}
+		if exists {
+			drift.Live = encodeValue(liveValue)
+		}
+		result = append(result, drift)
+		*paths = append(*paths, fieldPath)
+	}
+
+	return result
+}
+
+// fieldManager returns the manager, which owns the field at path or one of
+// its parents. If several managers own the field, the most recent one is
+// returned.
+func fieldManager(managed []metav1.ManagedFieldsEntry, path []string) string {
+	var (
+		manager string
+		latest  time.Time
+	)
+	for _, entry := range managed {
+		if entry.FieldsV1 == nil {
+			continue
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			continue
+		}
+		if !ownsField(fields, path) {
+			continue
+		}
+
+		var t time.Time
+		if entry.Time != nil {
+			t = entry.Time.Time
+		}
+		if manager == "" || t.After(latest) {
+			manager = entry.Manager
+			latest = t
+		}
+	}
+	return manager
+}
+
+// ownsField checks if the field set, in the FieldsV1 format, contains the
+// field at path. A field set ending in a parent of the field, owns the
+// parent as a whole.
+func ownsField(fields map[string]interface{}, path []string) bool {
+	for i, p := range path {
+		if i > 0 && len(fields) == 0 {
+			return true
+		}
+		next, ok := fields["f:"+p].(map[string]interface{})
+		if !ok {
+			return false
+		}
+		fields = next
+	}
+	return true
+}
+
+func jsonPath(path []string) string {
+	var b strings.Builder
+	for _, p := range path {
+		if identifier.MatchString(p) {
+			b.WriteString("." + p)
+		} else {
+			fmt.Fprintf(&b, "[%q]", p)
+		}
+	}
+	return b.String()
+}
+
+func encodeValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	if len(data) > driftValueMaxLength {
+		return string(data[:driftValueMaxLength])
+	}
+	return string(data)
+}
@@ -0,0 +1,118 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_fieldDrift(t *testing.T) {
+	live := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name": "app",
+			"labels": map[string]interface{}{
+				"app.kubernetes.io/name": "other",
+				"extra":                  "label",
+			},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(5),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app", "image": "nginx:latest"},
+					},
+				},
+			},
+		},
+	}}
+	older := metav1.NewTime(time.Unix(1700000000, 0))
+	newer := metav1.NewTime(time.Unix(1700001000, 0))
+	live.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{
+			Manager:  "helm",
+			Time:     &older,
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{".":{},"f:app.kubernetes.io/name":{}}},"f:spec":{"f:replicas":{},"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"app\"}":{"f:image":{}}}}}}}`)},
+		},
+		{
+			Manager:  "kubectl-edit",
+			Time:     &newer,
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{"f:extra":{}}},"f:spec":{"f:replicas":{}}}`)},
+		},
+	})
+
+	patch := `{"metadata":{"labels":{"app.kubernetes.io/name":"app","extra":null}},"spec":{"replicas":2,"strategy":{"type":"Recreate"},"template":{"spec":{"containers":[{"image":"nginx:1.27","name":"app"}]}}}}`
+	drift, err := fieldDrift(patch, live)
+	require.NoError(t, err)
+
+	assert.Equal(t, []fleet.FieldDrift{
+		{Path: `.metadata.labels["app.kubernetes.io/name"]`, Desired: `"app"`, Live: `"other"`, Manager: "helm"},
+		{Path: ".metadata.labels.extra", Live: `"label"`, Manager: "kubectl-edit"},
+		{Path: ".spec.replicas", Desired: "2", Live: "5", Manager: "kubectl-edit"},
+		{Path: ".spec.strategy", Desired: `{"type":"Recreate"}`},
+		{Path: ".spec.template.spec.containers", Desired: `[{"image":"nginx:1.27","name":"app"}]`, Live: `[{"image":"nginx:latest","name":"app"}]`, Manager: "helm"},
+	}, drift)
+
+	_, err = fieldDrift("not json", live)
+	assert.Error(t, err)
+}
+
+func Test_fieldDriftLimits(t *testing.T) {
+	data := map[string]interface{}{}
+	var patch []string
+	for _, k := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l"} {
+		data[k] = strings.Repeat("x", 300)
+		patch = append(patch, `"`+k+`":"y"`)
+	}
+	live := &unstructured.Unstructured{Object: map[string]interface{}{"data": data}}
+
+	drift, err := fieldDrift(`{"data":{`+strings.Join(patch, ",")+`}}`, live)
+	require.NoError(t, err)
+	assert.Len(t, drift, driftMaxFields)
+	assert.Len(t, drift[0].Live, driftValueMaxLength)
+	assert.Equal(t, ".data.j", drift[driftMaxFields-1].Path)
+}
+
+func Test_fieldDriftSecret(t *testing.T) {
+	live := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"name":   "db",
+			"labels": map[string]interface{}{"app": "other"},
+		},
+		"data": map[string]interface{}{
+			"password": "bGl2ZQ==",
+			"extra":    "ZXh0cmE=",
+		},
+	}}
+
+	patch := `{"data":{"extra":null,"password":"ZGVzaXJlZA=="},"metadata":{"labels":{"app":"db"}},"stringData":{"token":"abc"}}`
+	drift, err := fieldDrift(patch, live)
+	require.NoError(t, err)
+
+	assert.Equal(t, []fleet.FieldDrift{
+		{Path: ".data.extra"},
+		{Path: ".data.password"},
+		{Path: ".metadata.labels.app", Desired: `"db"`, Live: `"other"`},
+		{Path: ".stringData"},
+	}, drift)
+}
+
+func Test_redactSecretPatch(t *testing.T) {
+	assert.JSONEq(t,
+		`{"data":{"extra":null,"password":"<redacted>"},"metadata":{"labels":{"app":"db"}},"stringData":"<redacted>"}`,
+		redactSecretPatch(`{"data":{"extra":null,"password":"ZGVzaXJlZA=="},"metadata":{"labels":{"app":"db"}},"stringData":["invalid"]}`))
+	assert.JSONEq(t, `{"stringData":{"token":"<redacted>"}}`, redactSecretPatch(`{"stringData":{"token":"abc"}}`))
+	assert.Empty(t, redactSecretPatch("not json"))
+}
@@ -0,0 +1,71 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rancher/fleet/internal/cmd/agent/deployer/desiredset"
+	"github.com/rancher/fleet/internal/helmdeployer"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+)
+
+// limit the length of the patches in the dry run status
+const dryRunPatchMaxLength = 1024
+
+// DryRun compares the resources rendered by a dry run to the live state and
+// returns the changes deploying them would apply. Resources of the currently
+// deployed release, which are no longer rendered, would be deleted.
+func (m *Monitor) DryRun(ctx context.Context, bd *fleet.BundleDeployment, resources *helmdeployer.Resources) (*fleet.DryRunStatus, error) {
+	deployed, err := m.deployer.Resources(bd.Name, bd.Status.Release)
+	if errors.Is(err, helmdeployer.ErrNoResourceID) {
+		deployed = &helmdeployer.Resources{}
+	} else if err != nil {
+		return nil, err
+	}
+
+	ns := resources.DefaultNamespace
+	if ns == "" {
+		ns = m.defaultNamespace
+	}
+
+	plan, err := m.desiredset.Plan(ctx, ns, desiredset.GetSetID(bd.Name, m.labelPrefix, m.labelSuffix), resources.Objects...)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err = desiredset.Diff(plan, bd, resources.DefaultNamespace, resources.Objects...)
+	if err != nil {
+		return nil, err
+	}
+
+	return dryRunStatus(bd.Spec.DeploymentID, modified(ctx, m.client, plan, deployed)), nil
+}
+
+// dryRunStatus counts the changes and limits the size of the reported changes.
+func dryRunStatus(deploymentID string, changes []fleet.ModifiedStatus) *fleet.DryRunStatus {
+	status := &fleet.DryRunStatus{DeploymentID: deploymentID}
+	for _, c := range changes {
+		switch {
+		case c.Create:
+			status.Create++
+		case c.Delete:
+			status.Delete++
+		default:
+			status.Update++
+		}
+	}
+
+	if len(changes) > resourcesDetailsMaxLength {
+		status.Truncated = true
+		changes = changes[:resourcesDetailsMaxLength]
+	}
+	for i := range changes {
+		if len(changes[i].Patch) > dryRunPatchMaxLength {
+			status.Truncated = true
+			changes[i].Patch = changes[i].Patch[:dryRunPatchMaxLength]
+		}
+	}
+	status.Changes = changes
+
+	return status
+}
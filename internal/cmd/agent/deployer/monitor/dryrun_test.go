@@ -0,0 +1,47 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+)
+
+func Test_dryRunStatus(t *testing.T) {
+	changes := []fleet.ModifiedStatus{
+		{Kind: "ConfigMap", APIVersion: "v1", Namespace: "default", Name: "created", Create: true},
+		{Kind: "ConfigMap", APIVersion: "v1", Namespace: "default", Name: "deleted", Delete: true},
+		{Kind: "ConfigMap", APIVersion: "v1", Namespace: "default", Name: "updated", Patch: `{"data":{"key":"value"}}`},
+	}
+
+	status := dryRunStatus("manifest:options", changes)
+	assert.Equal(t, &fleet.DryRunStatus{
+		DeploymentID: "manifest:options",
+		Create:       1,
+		Update:       1,
+		Delete:       1,
+		Changes:      changes,
+	}, status)
+	assert.Equal(t, "dry run: 1 to create, 1 to update, 1 to delete", status.String())
+
+	t.Run("size-limited", func(t *testing.T) {
+		var many []fleet.ModifiedStatus
+		for i := 0; i < 15; i++ {
+			many = append(many, fleet.ModifiedStatus{Kind: "ConfigMap", APIVersion: "v1", Name: fmt.Sprintf("cm-%d", i), Create: true})
+		}
+		many = append(many, fleet.ModifiedStatus{Kind: "ConfigMap", APIVersion: "v1", Name: "large", Patch: strings.Repeat("a", 2000)})
+
+		status := dryRunStatus("id", many)
+		assert.Equal(t, 15, status.Create)
+		assert.Equal(t, 1, status.Update)
+		assert.True(t, status.Truncated)
+		assert.Len(t, status.Changes, resourcesDetailsMaxLength)
+
+		status = dryRunStatus("id", many[len(many)-1:])
+		assert.True(t, status.Truncated)
+		assert.Len(t, status.Changes[0].Patch, dryRunPatchMaxLength)
+	})
+}
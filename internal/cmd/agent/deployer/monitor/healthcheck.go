@@ -0,0 +1,147 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/lru"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	fleetsummary "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1/summary"
+)
+
+const (
+	// maxPrograms is the number of compiled CEL programs kept in the cache.
+	maxPrograms = 500
+	// costLimit limits the cost of evaluating an expression, it is the
+	// same as the per call limit of CEL validation rules in Kubernetes.
+	costLimit = 1000000
+	// evalTimeout limits the time to evaluate an expression. Comprehensions
+	// check for the timeout every interruptCheckFrequency iterations.
+	evalTimeout             = time.Second
+	interruptCheckFrequency = 100
+)
+
+// programs caches compiled CEL programs by expression, as the status of
+// bundle deployments is updated frequently. The least recently used programs
+// are evicted.
+var programs = lru.New(maxPrograms)
+
+// healthCheckFor returns the last health check matching the kind of the
+// object, or nil.
+func healthCheckFor(u *unstructured.Unstructured, checks []fleet.HealthCheck) *fleet.HealthCheck {
+	for i := len(checks) - 1; i >= 0; i-- {
+		if checks[i].APIVersion == u.GetAPIVersion() && checks[i].Kind == u.GetKind() {
+			return &checks[i]
+		}
+	}
+	return nil
+}
+
+// checkHealth evaluates the health check against the live object and returns
+// its summary. Errors in the expressions are reported as errors in the
+// summary, so they show up in the bundle deployment's status.
+func checkHealth(u *unstructured.Unstructured, check *fleet.HealthCheck) fleetsummary.Summary {
+	failed := false
+	if check.Failed != "" {
+		v, err := evaluate(check.Failed, u)
+		if err != nil {
+			return healthCheckError(err)
+		}
+		b, ok := v.(bool)
+		if !ok {
+			return healthCheckError(fmt.Errorf("expression %q must return a bool, got %T", check.Failed, v))
+		}
+		failed = b
+	}
+
+	ready := false
+	if !failed {
+		v, err := evaluate(check.Ready, u)
+		if err != nil {
+			return healthCheckError(err)
+		}
+		b, ok := v.(bool)
+		if !ok {
+			return healthCheckError(fmt.Errorf("expression %q must return a bool, got %T", check.Ready, v))
+		}
+		ready = b
+	}
+
+	if ready {
+		return fleetsummary.Summary{State: "active"}
+	}
+
+	sum := fleetsummary.Summary{State: "in-progress", Transitioning: true}
+	if failed {
+		sum = fleetsummary.Summary{State: "error", Error: true}
+	}
+	if check.Message != "" {
+		v, err := evaluate(check.Message, u)
+		if err != nil {
+			return healthCheckError(err)
+		}
+		sum.Message = []string{fmt.Sprint(v)}
+	}
+
+	return sum
+}
+
+func healthCheckError(err error) fleetsummary.Summary {
+	return fleetsummary.Summary{
+		State:   "error",
+		Error:   true,
+		Message: []string{fmt.Sprintf("health check failed: %v", err)},
+	}
+}
+
+// evaluate runs the CEL expression with the object bound to "self".
+func evaluate(expr string, u *unstructured.Unstructured) (any, error) {
+	prg, err := program(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), evalTimeout)
+	defer cancel()
+
+	out, _, err := prg.ContextEval(ctx, map[string]any{"self": u.Object})
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate %q: %w", expr, err)
+	}
+	if types.IsError(out) {
+		return nil, fmt.Errorf("failed to evaluate %q: %v", expr, out)
+	}
+
+	return out.Value(), nil
+}
+
+func program(expr string) (cel.Program, error) {
+	if prg, ok := programs.Get(expr); ok {
+		return prg.(cel.Program), nil
+	}
+
+	env, err := cel.NewEnv(cel.Variable("self", cel.DynType))
+	if err != nil {
+		return nil, err
+	}
+	ast, iss := env.Compile(expr)
+	if iss.Err() != nil {
+		return nil, fmt.Errorf("failed to compile %q: %w", expr, iss.Err())
+	}
+	prg, err := env.Program(ast,
+		cel.CostLimit(costLimit),
+		cel.InterruptCheckFrequency(interruptCheckFrequency),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile %q: %w", expr, err)
+	}
+
+	programs.Add(expr, prg)
+	return prg, nil
+}
@@ -0,0 +1,134 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/rancher/fleet/internal/cmd/agent/deployer/desiredset"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	fleetv1 "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1/summary"
+)
+
+func certificate(phase string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Certificate",
+		"metadata": map[string]interface{}{
+			"name":      "tls",
+			"namespace": "testns",
+			"uid":       "1",
+		},
+	}}
+	if phase != "" {
+		u.Object["status"] = map[string]interface{}{"phase": phase}
+	}
+	return u
+}
+
+var certificateCheck = fleet.HealthCheck{
+	APIVersion: "cert-manager.io/v1",
+	Kind:       "Certificate",
+	Ready:      `has(self.status) && self.status.phase == "Issued"`,
+	Failed:     `has(self.status) && self.status.phase == "Failed"`,
+	Message:    `has(self.status) ? "phase is " + self.status.phase : "waiting for status"`,
+}
+
+func Test_checkHealth(t *testing.T) {
+	// a million iterations of nested comprehensions
+	const expensive = `[0, 1, 2, 3, 4, 5, 6, 7, 8, 9].all(a, [0, 1, 2, 3, 4, 5, 6, 7, 8, 9].all(b, [0, 1, 2, 3, 4, 5, 6, 7, 8, 9].all(c, ` +
+		`[0, 1, 2, 3, 4, 5, 6, 7, 8, 9].all(d, [0, 1, 2, 3, 4, 5, 6, 7, 8, 9].all(e, [0, 1, 2, 3, 4, 5, 6, 7, 8, 9].all(f, true))))))`
+
+	tests := []struct {
+		name  string
+		obj   *unstructured.Unstructured
+		check fleet.HealthCheck
+		want  fleetv1.Summary
+	}{
+		{
+			name:  "ready",
+			obj:   certificate("Issued"),
+			check: certificateCheck,
+			want:  fleetv1.Summary{State: "active"},
+		},
+		{
+			name:  "in progress",
+			obj:   certificate("Pending"),
+			check: certificateCheck,
+			want:  fleetv1.Summary{State: "in-progress", Transitioning: true, Message: []string{"phase is Pending"}},
+		},
+		{
+			name:  "missing status",
+			obj:   certificate(""),
+			check: certificateCheck,
+			want:  fleetv1.Summary{State: "in-progress", Transitioning: true, Message: []string{"waiting for status"}},
+		},
+		{
+			name:  "failed",
+			obj:   certificate("Failed"),
+			check: certificateCheck,
+			want:  fleetv1.Summary{State: "error", Error: true, Message: []string{"phase is Failed"}},
+		},
+		{
+			name:  "expression not returning a bool",
+			obj:   certificate("Issued"),
+			check: fleet.HealthCheck{Ready: `self.status.phase`},
+			want: fleetv1.Summary{State: "error", Error: true, Message: []string{
+				`health check failed: expression "self.status.phase" must return a bool, got string`,
+			}},
+		},
+		{
+			name:  "expression exceeding the cost limit",
+			obj:   certificate("Issued"),
+			check: fleet.HealthCheck{Ready: expensive},
+			want: fleetv1.Summary{State: "error", Error: true, Message: []string{
+				`health check failed: failed to evaluate "` + expensive + `": operation cancelled: actual cost limit exceeded`,
+			}},
+		},
+		{
+			name:  "invalid expression",
+			obj:   certificate("Issued"),
+			check: fleet.HealthCheck{Ready: `self.status.phase ==`},
+			want:  fleetv1.Summary{State: "error", Error: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkHealth(tt.obj, &tt.check)
+			if tt.want.Message == nil && got.Error {
+				// compiler errors are verbose, only check for the prefix
+				assert.Len(t, got.Message, 1)
+				assert.Contains(t, got.Message[0], "health check failed: failed to compile")
+				got.Message = nil
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_nonReadyWithHealthChecks(t *testing.T) {
+	cm := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "cm", "namespace": "testns", "uid": "2"},
+	}}
+	plan := desiredset.Plan{Objects: []runtime.Object{certificate("Pending"), cm}}
+
+	// without health checks the certificate has no conditions and is
+	// considered ready
+	assert.Empty(t, nonReady(context.TODO(), plan, nil, nil))
+
+	// the last matching check takes precedence
+	checks := []fleet.HealthCheck{
+		{APIVersion: "cert-manager.io/v1", Kind: "Certificate", Ready: "true"},
+		certificateCheck,
+	}
+	result := nonReady(context.TODO(), plan, nil, checks)
+	assert.Len(t, result, 1)
+	assert.Equal(t, "Certificate", result[0].Kind)
+	assert.Equal(t, []string{"phase is Pending"}, result[0].Summary.Message)
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/rancher/fleet/internal/cmd/agent/deployer/summary"
 	"github.com/rancher/fleet/internal/helmdeployer"
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	fleetsummary "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1/summary"
 )
 
 // limit the length of nonReady and modified resources
@@ -135,6 +136,9 @@ func removePrivateFields(s1 *fleet.BundleDeploymentStatus) {
 // That error is non-nil if the status corresponds to a non-ready or modified state of the bundle deployment.
 func readyError(status fleet.BundleDeploymentStatus) error {
 	if status.Ready && status.NonModified {
+		if status.SyncWavesPending && status.SyncWave != nil {
+			return fmt.Errorf("waiting to apply sync waves after wave %d", *status.SyncWave)
+		}
 		return nil
 	}
 
@@ -182,7 +186,7 @@ func (m *Monitor) updateFromPreviousDeployment(ctx context.Context, bd *fleet.Bu
 		return err
 	}
 
-	nonReadyResources := nonReady(ctx, plan, bd.Spec.Options.IgnoreOptions)
+	nonReadyResources := nonReady(ctx, plan, bd.Spec.Options.IgnoreOptions, bd.Spec.Options.HealthChecks)
 	modifiedResources := modified(ctx, m.client, plan, resourcesPreviousRelease)
 	allResources, err := toBundleDeploymentResources(m.client, plan.Objects, resources.DefaultNamespace)
 	if err != nil {
@@ -312,7 +316,10 @@ func calculateDesiredReady(liveResourceKeys map[fleet.ResourceKey]struct{}, modi
 	return desired
 }
 
-func nonReady(ctx context.Context, plan desiredset.Plan, ignoreOptions *fleet.IgnoreOptions) (result []fleet.NonReadyStatus) {
+// nonReady returns the status of all objects in the plan which are not
+// ready. The readiness of objects matching a health check is computed by the
+// health check instead of the built-in summarizers.
+func nonReady(ctx context.Context, plan desiredset.Plan, ignoreOptions *fleet.IgnoreOptions, healthChecks []fleet.HealthCheck) (result []fleet.NonReadyStatus) {
 	logger := log.FromContext(ctx)
 	defer func() {
 		sort.Slice(result, func(i, j int) bool {
@@ -328,7 +335,12 @@ func nonReady(ctx context.Context, plan desiredset.Plan, ignoreOptions *fleet.Ig
 				}
 			}
 
-			sum := summary.Summarize(u)
+			var sum fleetsummary.Summary
+			if check := healthCheckFor(u, healthChecks); check != nil {
+				sum = checkHealth(u, check)
+			} else {
+				sum = summary.Summarize(u)
+			}
 			if !sum.IsReady() {
 				result = append(result, fleet.NonReadyStatus{
 					UID:        u.GetUID(),
@@ -347,7 +359,7 @@ func nonReady(ctx context.Context, plan desiredset.Plan, ignoreOptions *fleet.Ig
 
 // modified returns a list of modified statuses based on the provided plan and previous release resources.
 // The function iterates through the plan's create, delete, and update actions and constructs a modified status
-// for each resource.
+// for each resource. Updated resources report their drifted fields.
 // If the number of modified statuses exceeds 10, the function stops and returns the current result.
 func modified(ctx context.Context, c client.Client, plan desiredset.Plan, resourcesPreviousRelease *helmdeployer.Resources) (result []fleet.ModifiedStatus) {
 	logger := log.FromContext(ctx)
@@ -410,15 +422,29 @@ func modified(ctx context.Context, c client.Client, plan desiredset.Plan, resour
 		}
 	}
 
+	live := objectset.NewObjectSet(plan.Objects...).ObjectsByGVK()
 	for gvk, patches := range plan.Update {
 		apiVersion, kind := gvk.ToAPIVersionAndKind()
 		for key, patch := range patches {
+			var drift []fleet.FieldDrift
+			if obj, ok := live[gvk][key].(*unstructured.Unstructured); ok {
+				var err error
+				drift, err = fieldDrift(patch, obj)
+				if err != nil {
+					logger.V(1).Info("Failed to compute drifted fields", "resourceName", key.Name, "resourceKind", kind, "error", err)
+				}
+			}
+			// the patches of secrets can contain decrypted values
+			if gvk.Group == "" && gvk.Kind == "Secret" {
+				patch = redactSecretPatch(patch)
+			}
 			result = append(result, fleet.ModifiedStatus{
 				Kind:       kind,
 				APIVersion: apiVersion,
 				Namespace:  key.Namespace,
 				Name:       key.Name,
 				Patch:      patch,
+				Drift:      drift,
 			})
 		}
 	}
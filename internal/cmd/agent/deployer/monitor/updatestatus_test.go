@@ -243,3 +243,10 @@ func Test_updateFromResources(t *testing.T) {
 		})
 	}
 }
+
+func Test_readyError(t *testing.T) {
+	wave := 1
+	assert.NoError(t, readyError(fleet.BundleDeploymentStatus{Ready: true, NonModified: true}))
+	assert.EqualError(t, readyError(fleet.BundleDeploymentStatus{Ready: true, NonModified: true, SyncWave: &wave, SyncWavesPending: true}), "waiting to apply sync waves after wave 1")
+	assert.EqualError(t, readyError(fleet.BundleDeploymentStatus{NonModified: true, SyncWave: &wave, SyncWavesPending: true}), "not ready")
+}
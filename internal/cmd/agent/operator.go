@@ -141,6 +141,7 @@ func start(
 	// RawSource watches for all events from the driftdetect mini controller
 	driftReconciler := &controller.DriftReconciler{
 		Client: mgr.GetClient(),
+		Reader: mgr.GetAPIReader(),
 		Scheme: mgr.GetScheme(),
 
 		Deployer:    reconciler.Deployer,
@@ -149,6 +150,8 @@ func start(
 
 		DriftChan: driftChan,
 
+		IgnoreManagers: agentConfig.IgnoreManagers,
+
 		Workers: workersOpts.Drift,
 	}
 	if err = driftReconciler.SetupWithManager(mgr); err != nil {
@@ -290,6 +293,8 @@ func newReconciler(
 
 		DefaultNamespace: defaultNamespace,
 
+		IgnoreManagers: agentConfig.IgnoreManagers,
+
 		AgentScope: agentScope,
 
 		Workers: workers,
@@ -188,15 +188,18 @@ func runRegistration(ctx context.Context, k8s coreInterface, namespace string) (
 	cfg.Labels["fleet.cattle.io/created-by-agent-pod"] = os.Getenv("HOSTNAME")
 
 	logrus.Infof("Creating clusterregistration with id '%s' for new token", clientID)
+	data := values(secret.Data)
 	request, err := fc.Fleet().V1alpha1().ClusterRegistration().Create(&fleet.ClusterRegistration{
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: "request-",
 			Namespace:    ns,
 		},
 		Spec: fleet.ClusterRegistrationSpec{
-			ClientID:      clientID,
-			ClientRandom:  token,
-			ClusterLabels: cfg.Labels,
+			ClientID:       clientID,
+			ClientRandom:   token,
+			ClusterLabels:  cfg.Labels,
+			TokenName:      string(data[config.ClusterRegistrationTokenKey]),
+			TokenSignature: registration.TokenSignature(string(data[Token]), clientID, token),
 		},
 	})
 	if err != nil {
@@ -204,7 +207,7 @@ func runRegistration(ctx context.Context, k8s coreInterface, namespace string) (
 	}
 
 	secretName := registration.SecretName(request.Spec.ClientID, request.Spec.ClientRandom)
-	secretNamespace := string(values(secret.Data)["systemRegistrationNamespace"])
+	secretNamespace := string(data["systemRegistrationNamespace"])
 	timeout := time.After(durations.CreateClusterSecretTimeout)
 
 	for {
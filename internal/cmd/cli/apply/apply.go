@@ -15,6 +15,7 @@ import (
 	"strings"
 
 	"github.com/rancher/fleet/internal/bundlereader"
+	"github.com/rancher/fleet/internal/cmd/controller/target"
 	"github.com/rancher/fleet/internal/fleetyaml"
 	"github.com/rancher/fleet/internal/helmvalues"
 	"github.com/rancher/fleet/internal/manifest"
@@ -362,16 +363,20 @@ func newBundle(ctx context.Context, name, baseDir string, opts Options) (*fleet.
 //
 // name: the gitrepo name, passed to 'fleet apply' on the cli
 // basedir: a directory containing a Bundle, as observed by CreateBundles or CreateBundlesDriven
-func bundleFromDir(ctx context.Context, name, baseDir string, opts Options) (*fleet.Bundle, []*fleet.ImageScan, error) {
-	// The bundleID is a valid helm release name, it's used as a default if a release name is not specified in helm options.
-	// It's also used to create the bundle name.
+// BundleID returns the ID of the bundle created from the directory, with an
+// optional bundle file. The bundleID is a valid helm release name, it's used
+// as a default if a release name is not specified in helm options. It's also
+// used to create the bundle name.
+func BundleID(name, baseDir, bundleFile string) string {
 	bundleID := filepath.Join(name, baseDir)
-	if opts.BundleFile != "" {
-		bundleID = filepath.Join(bundleID, strings.TrimSuffix(opts.BundleFile, filepath.Ext(opts.BundleFile)))
+	if bundleFile != "" {
+		bundleID = filepath.Join(bundleID, strings.TrimSuffix(bundleFile, filepath.Ext(bundleFile)))
 	}
-	bundleID = names.HelmReleaseName(bundleID)
+	return names.HelmReleaseName(bundleID)
+}
 
-	bundle, scans, err := newBundle(ctx, bundleID, baseDir, opts)
+func bundleFromDir(ctx context.Context, name, baseDir string, opts Options) (*fleet.Bundle, []*fleet.ImageScan, error) {
+	bundle, scans, err := newBundle(ctx, BundleID(name, baseDir, opts.BundleFile), baseDir, opts)
 	if err != nil {
 		return nil, nil, err
 	} else if len(bundle.Spec.Resources) == 0 {
@@ -523,7 +528,7 @@ func save(ctx context.Context, c client.Client, bundle *fleet.Bundle) (*fleet.Bu
 		}
 
 		bundle.Spec = updated.Spec
-		bundle.Annotations = updated.Annotations
+		bundle.Annotations = target.KeepApprovals(bundle, updated.Annotations)
 		bundle.Labels = updated.Labels
 		return nil
 	})
@@ -583,7 +588,7 @@ func saveOCIBundle(ctx context.Context, c client.Client, r record.EventRecorder,
 		}
 
 		bundle.Spec = updated.Spec
-		bundle.Annotations = updated.Annotations
+		bundle.Annotations = target.KeepApprovals(bundle, updated.Annotations)
 		bundle.Labels = updated.Labels
 
 		// We don't store the resources in the bundle. Just keep the manifestID for
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	command "github.com/rancher/fleet/internal/cmd"
+	"github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// NewApprove returns a subcommand to approve or deny pending cluster
+// registrations.
+func NewApprove() *cobra.Command {
+	cmd := command.Command(&Approve{}, cobra.Command{
+		Use:   "approve [flags] CLUSTER_REGISTRATION...",
+		Short: "Approve pending cluster registrations",
+	})
+	cmd.SetOut(os.Stdout)
+
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	zopts.BindFlags(fs)
+	ctrl.RegisterFlags(fs)
+	cmd.Flags().AddGoFlagSet(fs)
+	return cmd
+}
+
+type Approve struct {
+	Namespace string `usage:"Namespace of the cluster registrations" default:"fleet-default" short:"n"`
+	Deny      bool   `usage:"Deny the cluster registrations instead"`
+}
+
+func (a *Approve) Run(cmd *cobra.Command, args []string) error {
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&zopts)))
+	ctx := log.IntoContext(cmd.Context(), ctrl.Log)
+
+	if len(args) == 0 {
+		return cmd.Help()
+	}
+
+	cfg := ctrl.GetConfigOrDie()
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range args {
+		if err := approve(ctx, c, a.Namespace, name, !a.Deny); err != nil {
+			return err
+		}
+		if a.Deny {
+			fmt.Fprintf(cmd.OutOrStdout(), "clusterregistration %s/%s denied\n", a.Namespace, name)
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "clusterregistration %s/%s approved\n", a.Namespace, name)
+		}
+	}
+	return nil
+}
+
+// approve sets the approval annotation on the cluster registration, which is
+// processed by the clusterregistration controller.
+func approve(ctx context.Context, c client.Client, namespace, name string, approved bool) error {
+	cr := &v1alpha1.ClusterRegistration{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, cr); err != nil {
+		return err
+	}
+	if cr.Status.Granted || cr.Status.State == v1alpha1.ClusterRegistrationStateDenied {
+		return fmt.Errorf("clusterregistration %s/%s is not pending", namespace, name)
+	}
+
+	orig := cr.DeepCopy()
+	if cr.Annotations == nil {
+		cr.Annotations = map[string]string{}
+	}
+	cr.Annotations[v1alpha1.ClusterRegistrationApprovedAnnotation] = strconv.FormatBool(approved)
+	return c.Patch(ctx, cr, client.MergeFrom(orig))
+}
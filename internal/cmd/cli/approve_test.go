@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestApprove(t *testing.T) {
+	cr := func(name string, status v1alpha1.ClusterRegistrationStatus) *v1alpha1.ClusterRegistration {
+		return &v1alpha1.ClusterRegistration{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-default", Name: name},
+			Status:     status,
+		}
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		cr("pending", v1alpha1.ClusterRegistrationStatus{State: v1alpha1.ClusterRegistrationStatePending}),
+		cr("denied", v1alpha1.ClusterRegistrationStatus{State: v1alpha1.ClusterRegistrationStateDenied}),
+		cr("granted", v1alpha1.ClusterRegistrationStatus{State: v1alpha1.ClusterRegistrationStateApproved, Granted: true}),
+	).Build()
+	ctx := context.TODO()
+
+	if err := approve(ctx, c, "fleet-default", "pending", true); err != nil {
+		t.Fatal(err)
+	}
+	result := &v1alpha1.ClusterRegistration{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "fleet-default", Name: "pending"}, result); err != nil {
+		t.Fatal(err)
+	}
+	if v := result.Annotations[v1alpha1.ClusterRegistrationApprovedAnnotation]; v != "true" {
+		t.Errorf("expected approval annotation to be true, got %q", v)
+	}
+
+	if err := approve(ctx, c, "fleet-default", "pending", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "fleet-default", Name: "pending"}, result); err != nil {
+		t.Fatal(err)
+	}
+	if v := result.Annotations[v1alpha1.ClusterRegistrationApprovedAnnotation]; v != "false" {
+		t.Errorf("expected approval annotation to be false, got %q", v)
+	}
+
+	for _, name := range []string{"denied", "granted"} {
+		if err := approve(ctx, c, "fleet-default", name, true); err == nil {
+			t.Errorf("expected error approving %s registration", name)
+		}
+	}
+	if err := approve(ctx, c, "fleet-default", "missing", true); err == nil {
+		t.Error("expected error approving missing registration")
+	}
+}
@@ -0,0 +1,238 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rancher/fleet/internal/bundlereader"
+	command "github.com/rancher/fleet/internal/cmd"
+	"github.com/rancher/fleet/internal/cmd/agent/deployer/kv"
+	"github.com/rancher/fleet/internal/cmd/cli/apply"
+	"github.com/rancher/fleet/internal/cmd/cli/diff"
+	"github.com/rancher/fleet/internal/cmd/controller/target"
+	"github.com/rancher/fleet/internal/content"
+	"github.com/rancher/fleet/internal/helmdeployer"
+	"github.com/rancher/fleet/internal/helmvalues"
+	"github.com/rancher/fleet/internal/manifest"
+	"github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/yaml"
+)
+
+// ErrDiffFound is returned by the diff command if --exit-code is set and
+// differences were found.
+var ErrDiffFound = errors.New("differences found")
+
+// errDeployedUnknown is returned, if the deployed resources of a bundle
+// deployment cannot be rendered from its content resource.
+var errDeployedUnknown = errors.New("deployed resources are unknown")
+
+// NewDiff returns a subcommand to preview the changes a bundle would cause on
+// each target cluster.
+func NewDiff() *cobra.Command {
+	cmd := command.Command(&Diff{}, cobra.Command{
+		Use:   "diff [flags] BUNDLE_NAME PATH",
+		Short: "Print a diff of the resources a bundle would change on each target cluster",
+	})
+	cmd.SetOut(os.Stdout)
+
+	// add command line flags from zap and controller-runtime, which use
+	// goflags and convert them to pflags
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	zopts.BindFlags(fs)
+	ctrl.RegisterFlags(fs)
+	cmd.Flags().AddGoFlagSet(fs)
+	return cmd
+}
+
+type Diff struct {
+	BundleInputArgs
+	Namespace   string `usage:"Namespace of the bundle. Targeting searches this namespace for clusters." default:"fleet-local" short:"n"`
+	InputList   string `usage:"Location of a YAML file with the targeting input, as dumped by 'fleet target --dump-input-list'. If not set, the live clusters are used." short:"l"`
+	Current     string `usage:"Location of a manifest with the currently deployed resources. If not set, the resources deployed by the live bundle are used." short:"c"`
+	KubeVersion string `usage:"Sets the Kubernetes version to assume when validating Chart Kubernetes version constraints."`
+	ExitCode    bool   `usage:"Exit with an error if differences were found" name:"exit-code"`
+}
+
+func (d *Diff) Run(cmd *cobra.Command, args []string) error {
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&zopts)))
+	ctx := log.IntoContext(cmd.Context(), ctrl.Log)
+
+	if len(args) != 2 {
+		return cmd.Help()
+	}
+	name, baseDir := args[0], args[1]
+
+	// use the same bundle name as 'fleet apply', so live bundle deployments are found
+	bundleID := apply.BundleID(name, baseDir, d.BundleFile)
+	bundle, _, err := bundlereader.NewBundle(ctx, bundleID, baseDir, d.File, &bundlereader.Options{BundleFile: d.BundleFile})
+	if err != nil {
+		return err
+	}
+	bundle.Namespace = d.Namespace
+
+	desiredManifest := manifest.FromBundle(bundle)
+	manifestID, err := desiredManifest.ID()
+	if err != nil {
+		return err
+	}
+
+	c, err := d.client()
+	if err != nil {
+		return err
+	}
+
+	matchedTargets, err := target.New(c, c).Targets(ctx, bundle, manifestID)
+	if err != nil {
+		return err
+	}
+
+	var current []byte
+	if d.Current != "" {
+		if current, err = os.ReadFile(d.Current); err != nil {
+			return err
+		}
+	}
+
+	changed := false
+	for _, t := range matchedTargets {
+		rel, err := helmdeployer.Template(ctx, bundle.Name, desiredManifest, t.Options, d.KubeVersion)
+		if err != nil {
+			return fmt.Errorf("failed to render bundle for cluster %s/%s: %w", t.Cluster.Namespace, t.Cluster.Name, err)
+		}
+		desired, err := diff.FromRelease(rel, defaultNamespaceOf(t.Options))
+		if err != nil {
+			return err
+		}
+
+		var deployed diff.Resources
+		if d.Current != "" {
+			if deployed, err = diff.FromManifest(current, defaultNamespaceOf(t.Options)); err != nil {
+				return fmt.Errorf("failed to read current resources: %w", err)
+			}
+		} else if deployed, err = d.deployed(ctx, c, t); errors.Is(err, errDeployedUnknown) {
+			fmt.Fprintf(cmd.ErrOrStderr(), "warning: skipping cluster %s/%s: %v, pass --current to compare against a manifest\n", t.Cluster.Namespace, t.Cluster.Name, err)
+			continue
+		} else if err != nil {
+			return fmt.Errorf("failed to render deployed resources for cluster %s/%s: %w", t.Cluster.Namespace, t.Cluster.Name, err)
+		}
+
+		found, err := diff.Write(cmd.OutOrStdout(), t.Cluster.Namespace+"/"+t.Cluster.Name, deployed, desired)
+		if err != nil {
+			return err
+		}
+		changed = changed || found
+	}
+
+	if changed && d.ExitCode {
+		return ErrDiffFound
+	}
+
+	return nil
+}
+
+// client returns a client for the live cluster, or a fake client populated
+// with the clusters and cluster groups from the input list.
+func (d *Diff) client() (client.Client, error) {
+	if d.InputList == "" {
+		return client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	}
+
+	b, err := os.ReadFile(d.InputList)
+	if err != nil {
+		return nil, err
+	}
+	var targets []*target.Target
+	if err := yaml.Unmarshal(b, &targets); err != nil {
+		return nil, fmt.Errorf("failed to read input list: %w", err)
+	}
+
+	seen := map[string]bool{}
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for _, t := range targets {
+		if t.Cluster == nil {
+			continue
+		}
+		objs := []client.Object{t.Cluster}
+		for _, cg := range t.ClusterGroups {
+			objs = append(objs, cg)
+		}
+		for _, obj := range objs {
+			key := fmt.Sprintf("%T/%s/%s", obj, obj.GetNamespace(), obj.GetName())
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			obj.SetResourceVersion("")
+			builder = builder.WithObjects(obj)
+		}
+	}
+
+	return builder.Build(), nil
+}
+
+// deployed renders the resources of the bundle deployment currently targeting
+// the cluster. It returns no resources if the bundle is not deployed to the
+// cluster yet, and errDeployedUnknown if its contents are not stored in a
+// content resource.
+func (d *Diff) deployed(ctx context.Context, c client.Client, t *target.Target) (diff.Resources, error) {
+	if t.Deployment == nil || t.Deployment.Spec.DeploymentID == "" {
+		return diff.Resources{}, nil
+	}
+	switch {
+	case t.Deployment.Spec.OCIContents:
+		return nil, fmt.Errorf("%w: contents are stored in an OCI registry", errDeployedUnknown)
+	case t.Deployment.Spec.HelmChartOptions != nil:
+		return nil, fmt.Errorf("%w: chart is downloaded by the agent", errDeployedUnknown)
+	}
+
+	// the controller moves helm values to the options secret
+	bd := t.Deployment.DeepCopy()
+	if err := helmvalues.LoadOptions(ctx, c, bd); err != nil {
+		return nil, err
+	}
+
+	contentID, _ := kv.Split(bd.Spec.DeploymentID, ":")
+	ct := &v1alpha1.Content{}
+	if err := c.Get(ctx, client.ObjectKey{Name: contentID}, ct); err != nil {
+		return nil, err
+	}
+
+	data, err := content.GUnzip(ct.Content)
+	if err != nil {
+		return nil, err
+	}
+	m, err := manifest.FromJSON(data, ct.SHA256Sum)
+	if err != nil {
+		return nil, err
+	}
+
+	rel, err := helmdeployer.Template(ctx, bd.Name, m, bd.Spec.Options, d.KubeVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return diff.FromRelease(rel, defaultNamespaceOf(bd.Spec.Options))
+}
+
+// defaultNamespaceOf returns the namespace the agent deploys resources
+// without a namespace to.
+func defaultNamespaceOf(opts v1alpha1.BundleDeploymentOptions) string {
+	if opts.TargetNamespace != "" {
+		return opts.TargetNamespace
+	}
+	if opts.DefaultNamespace != "" {
+		return opts.DefaultNamespace
+	}
+	return defaultNamespace
+}
@@ -0,0 +1,149 @@
+// Package diff renders the resources of a bundle per target cluster and
+// compares them to the currently deployed resources.
+//
+// It is used by the "diff" sub command of the fleet CLI, e.g. to preview the
+// changes of a pull request in a CI pipeline.
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/rancher/wrangler/v3/pkg/yaml"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	kyaml "sigs.k8s.io/yaml"
+)
+
+// Resources maps a resource key, "<kind>/<namespace>/<name>", to the YAML
+// representation of the resource.
+type Resources map[string]string
+
+// clusterScoped lists well-known kinds, which do not get a default namespace
+// in their resource key.
+var clusterScoped = map[string]bool{
+	"APIService":                     true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"CustomResourceDefinition":       true,
+	"MutatingWebhookConfiguration":   true,
+	"Namespace":                      true,
+	"PersistentVolume":               true,
+	"PriorityClass":                  true,
+	"StorageClass":                   true,
+	"ValidatingWebhookConfiguration": true,
+}
+
+// FromRelease returns the resources of a rendered Helm release, including its
+// hooks. Resources without a namespace are keyed with defaultNamespace, like
+// they would be deployed by the agent.
+func FromRelease(rel *release.Release, defaultNamespace string) (Resources, error) {
+	if rel == nil {
+		return Resources{}, nil
+	}
+
+	manifests := []string{rel.Manifest}
+	for _, h := range rel.Hooks {
+		manifests = append(manifests, h.Manifest)
+	}
+
+	return FromManifest([]byte(strings.Join(manifests, "\n---\n")), defaultNamespace)
+}
+
+// FromManifest returns the resources contained in a multi document YAML
+// manifest. Resources without a namespace are keyed with defaultNamespace.
+func FromManifest(data []byte, defaultNamespace string) (Resources, error) {
+	objs, err := yaml.ToObjects(bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return fromObjects(objs, defaultNamespace)
+}
+
+func fromObjects(objs []runtime.Object, defaultNamespace string) (Resources, error) {
+	resources := Resources{}
+	for _, obj := range objs {
+		m, err := meta.Accessor(obj)
+		if err != nil {
+			return nil, err
+		}
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		ns := m.GetNamespace()
+		if ns == "" && !clusterScoped[kind] {
+			ns = defaultNamespace
+		}
+		key := fmt.Sprintf("%s/%s/%s", kind, ns, m.GetName())
+
+		b, err := kyaml.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		resources[key] = string(b)
+	}
+
+	return resources, nil
+}
+
+// Write writes a unified diff between the current and the desired resources of
+// a cluster to w. It returns true if the resources differ.
+func Write(w io.Writer, cluster string, current, desired Resources) (bool, error) {
+	keys := map[string]struct{}{}
+	for k := range current {
+		keys[k] = struct{}{}
+	}
+	for k := range desired {
+		keys[k] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	changed := false
+	for _, key := range sorted {
+		from, to := "a/"+key, "b/"+key
+		if _, ok := current[key]; !ok {
+			from = "/dev/null"
+		}
+		if _, ok := desired[key]; !ok {
+			to = "/dev/null"
+		}
+
+		text, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(current[key]),
+			B:        difflib.SplitLines(desired[key]),
+			FromFile: from,
+			ToFile:   to,
+			Context:  3,
+		})
+		if err != nil {
+			return changed, err
+		}
+		if text == "" {
+			continue
+		}
+
+		if !changed {
+			if _, err := fmt.Fprintf(w, "# cluster %s\n", cluster); err != nil {
+				return changed, err
+			}
+		}
+		changed = true
+
+		if _, err := io.WriteString(w, text); err != nil {
+			return changed, err
+		}
+	}
+
+	return changed, nil
+}
@@ -0,0 +1,86 @@
+package diff_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rancher/fleet/internal/cmd/cli/diff"
+)
+
+const current = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+  namespace: default
+data:
+  replicas: "1"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: removed
+  namespace: default
+`
+
+const desired = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+  namespace: default
+data:
+  replicas: "3"
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: added
+`
+
+func TestWrite(t *testing.T) {
+	cur, err := diff.FromManifest([]byte(current), "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	des, err := diff.FromManifest([]byte(desired), "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	changed, err := diff.Write(&out, "fleet-default/downstream", cur, des)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Fatal("expected changes")
+	}
+
+	for _, want := range []string{
+		"# cluster fleet-default/downstream\n",
+		"--- a/ConfigMap/default/app\n+++ b/ConfigMap/default/app\n",
+		"-  replicas: \"1\"\n+  replicas: \"3\"\n",
+		"--- a/ConfigMap/default/removed\n+++ /dev/null\n",
+		"--- /dev/null\n+++ b/Service/default/added\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected diff to contain %q, got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestWriteUnchanged(t *testing.T) {
+	res, err := diff.FromManifest([]byte(current), "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	changed, err := diff.Write(&out, "fleet-default/downstream", res, res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed || out.Len() != 0 {
+		t.Errorf("expected no diff, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rancher/fleet/internal/cmd/controller/target"
+	"github.com/rancher/fleet/internal/content"
+	"github.com/rancher/fleet/internal/helmvalues"
+	"github.com/rancher/fleet/internal/manifest"
+	"github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDiffDeployed(t *testing.T) {
+	m := manifest.New([]v1alpha1.BundleResource{
+		{Name: "Chart.yaml", Content: "apiVersion: v2\nname: app\nversion: 0.1.0\n"},
+		{Name: "templates/cm.yaml", Content: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  replicas: {{ .Values.replicas | quote }}\n"},
+	})
+	data, err := m.Content()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz, err := content.Gzip(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := m.ID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	shasum, err := m.SHASum()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the controller moved the values to the options secret
+	values := map[string][]byte{helmvalues.ValuesKey: []byte(`{"replicas":3}`)}
+	bd := func(spec v1alpha1.BundleDeploymentSpec) *v1alpha1.BundleDeployment {
+		spec.DeploymentID = id + ":options"
+		spec.ValuesHash = helmvalues.HashOptionsSecret(values)
+		spec.Options.Helm = &v1alpha1.HelmOptions{}
+		return &v1alpha1.BundleDeployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "cluster-ns", Name: "app"},
+			Spec:       spec,
+		}
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&v1alpha1.Content{ObjectMeta: metav1.ObjectMeta{Name: id}, Content: gz, SHA256Sum: shasum},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "cluster-ns", Name: "app"}, Data: values},
+	).Build()
+	d := &Diff{}
+
+	deployed, err := d.deployed(context.TODO(), c, &target.Target{Deployment: bd(v1alpha1.BundleDeploymentSpec{})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deployed) != 1 {
+		t.Fatalf("expected one deployed resource, got %v", deployed)
+	}
+	for _, res := range deployed {
+		if !strings.Contains(res, `replicas: "3"`) {
+			t.Errorf("expected values from the options secret, got %s", res)
+		}
+	}
+
+	for name, spec := range map[string]v1alpha1.BundleDeploymentSpec{
+		"oci":        {OCIContents: true},
+		"helm chart": {HelmChartOptions: &v1alpha1.BundleHelmOptions{}},
+	} {
+		if _, err := d.deployed(context.TODO(), c, &target.Target{Deployment: bd(spec)}); !errors.Is(err, errDeployedUnknown) {
+			t.Errorf("%s: expected unknown deployed resources, got %v", name, err)
+		}
+	}
+}
@@ -16,6 +16,7 @@ import (
 
 	fleetgithub "github.com/rancher/fleet/internal/github"
 	fleetssh "github.com/rancher/fleet/internal/ssh"
+	fleetgit "github.com/rancher/fleet/pkg/git"
 	giturls "github.com/rancher/fleet/pkg/git-urls"
 )
 
@@ -62,24 +63,28 @@ func (c *Cloner) CloneRepo(opts *GitCloner) error {
 	if err != nil {
 		return fmt.Errorf("failed to read CA bundle from file for %s: %w", repo(opts), err)
 	}
+	verifier, err := createVerifierFromOpts(opts)
+	if err != nil {
+		return fmt.Errorf("failed to create signature verifier for %s: %w", repo(opts), err)
+	}
 
 	if opts.Branch == "" && opts.Revision == "" {
 		opts.Branch = defaultBranch
-		return cloneBranch(opts, auth, caBundle)
+		return cloneBranch(opts, auth, caBundle, verifier)
 	}
 
 	if opts.Branch != "" {
 		if opts.Revision != "" {
 			logrus.Warn("Using branch for cloning the repo. Revision will be skipped.")
 		}
-		return cloneBranch(opts, auth, caBundle)
+		return cloneBranch(opts, auth, caBundle, verifier)
 	}
 
-	return cloneRevision(opts, auth, caBundle)
+	return cloneRevision(opts, auth, caBundle, verifier)
 }
 
-func cloneBranch(opts *GitCloner, auth transport.AuthMethod, caBundle []byte) error {
-	_, err := plainClone(opts.Path, false, &git.CloneOptions{
+func cloneBranch(opts *GitCloner, auth transport.AuthMethod, caBundle []byte, verifier *fleetgit.Verifier) error {
+	r, err := plainClone(opts.Path, false, &git.CloneOptions{
 		URL:               opts.Repo,
 		Auth:              auth,
 		InsecureSkipTLS:   opts.InsecureSkipTLS,
@@ -92,10 +97,20 @@ func cloneBranch(opts *GitCloner, auth transport.AuthMethod, caBundle []byte) er
 	if err != nil {
 		return fmt.Errorf("failed to clone repo from branch %s: %w", repo(opts), err)
 	}
+
+	if verifier != nil {
+		head, err := r.Head()
+		if err != nil {
+			return fmt.Errorf("failed to get HEAD of %s: %w", repo(opts), err)
+		}
+		if err := verifier.VerifyObject(r, head.Hash()); err != nil {
+			return fmt.Errorf("failed to verify %s: %w", repo(opts), err)
+		}
+	}
 	return nil
 }
 
-func cloneRevision(opts *GitCloner, auth transport.AuthMethod, caBundle []byte) error {
+func cloneRevision(opts *GitCloner, auth transport.AuthMethod, caBundle []byte, verifier *fleetgit.Verifier) error {
 	r, err := plainClone(opts.Path, false, &git.CloneOptions{
 		URL:               opts.Repo,
 		Auth:              auth,
@@ -110,6 +125,11 @@ func cloneRevision(opts *GitCloner, auth transport.AuthMethod, caBundle []byte)
 	if err != nil {
 		return fmt.Errorf("failed to resolve revision %s: %w", repo(opts), err)
 	}
+	if verifier != nil {
+		if err := verifyRevision(r, opts.Revision, *h, verifier); err != nil {
+			return fmt.Errorf("failed to verify %s: %w", repo(opts), err)
+		}
+	}
 	w, err := r.Worktree()
 	if err != nil {
 		return fmt.Errorf("failed to get filesystem worktree for %s: %w", repo(opts), err)
@@ -122,6 +142,40 @@ func cloneRevision(opts *GitCloner, auth transport.AuthMethod, caBundle []byte)
 	return nil
 }
 
+// verifyRevision verifies the tag, if the revision is an annotated tag,
+// otherwise the resolved commit.
+func verifyRevision(r *git.Repository, revision string, commit plumbing.Hash, verifier *fleetgit.Verifier) error {
+	if tag, err := r.Tag(revision); err == nil {
+		return verifier.VerifyObject(r, tag.Hash())
+	}
+	return verifier.VerifyObject(r, commit)
+}
+
+// createVerifierFromOpts returns a verifier for the trusted keys in opts, or
+// nil if signatures are not verified.
+func createVerifierFromOpts(opts *GitCloner) (*fleetgit.Verifier, error) {
+	if opts.GPGKeysFile == "" && opts.AllowedSignersFile == "" {
+		return nil, nil
+	}
+
+	var gpgKeys, allowedSigners []byte
+	if opts.GPGKeysFile != "" {
+		data, err := readFile(opts.GPGKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		gpgKeys = data
+	}
+	if opts.AllowedSignersFile != "" {
+		data, err := readFile(opts.AllowedSignersFile)
+		if err != nil {
+			return nil, err
+		}
+		allowedSigners = data
+	}
+	return fleetgit.NewVerifier(gpgKeys, allowedSigners)
+}
+
 func getCABundleFromFile(path string) ([]byte, error) {
 	if path == "" {
 		return nil, nil
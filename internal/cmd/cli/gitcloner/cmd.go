@@ -21,6 +21,8 @@ type GitCloner struct {
 	GitHubAppID           int64
 	GitHubAppInstallation int64
 	GitHubAppKeyFile      string
+	GPGKeysFile           string
+	AllowedSignersFile    string
 }
 
 var opts *GitCloner
@@ -48,6 +50,8 @@ func NewCmd(gitCloner CloneGit) *cobra.Command {
 	cmd.Flags().Int64Var(&opts.GitHubAppID, "github-app-id", 0, "GitHub App ID")
 	cmd.Flags().Int64Var(&opts.GitHubAppInstallation, "github-app-installation-id", 0, "GitHub App installation ID")
 	cmd.Flags().StringVar(&opts.GitHubAppKeyFile, "github-app-key-file", "", "path to GitHub App private-key PEM")
+	cmd.Flags().StringVar(&opts.GPGKeysFile, "verify-gpg-keys-file", "", "path to ASCII armored GPG public keys trusted to sign commits")
+	cmd.Flags().StringVar(&opts.AllowedSignersFile, "verify-allowed-signers-file", "", "path to SSH allowed signers trusted to sign commits")
 
 	return cmd
 }
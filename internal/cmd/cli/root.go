@@ -28,6 +28,8 @@ func App() *cobra.Command {
 
 		NewTarget(),
 		NewDeploy(),
+		NewDiff(),
+		NewApprove(),
 		gitcloner.NewCmd(gitcloner.New()),
 	)
 
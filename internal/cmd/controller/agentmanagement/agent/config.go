@@ -16,6 +16,7 @@ type ConfigOptions struct {
 	ClientID                  string
 	AgentTLSMode              string
 	GarbageCollectionInterval metav1.Duration
+	IgnoreManagers            []string
 }
 
 func agentConfig(ctx context.Context, agentNamespace, controllerNamespace string, cg *client.Getter, opts *ConfigOptions) ([]runtime.Object, error) {
@@ -43,6 +44,7 @@ func configObjects(controllerNamespace string, co *ConfigOptions) ([]runtime.Obj
 		ClientID:                  co.ClientID,
 		AgentTLSMode:              co.AgentTLSMode,
 		GarbageCollectionInterval: co.GarbageCollectionInterval,
+		IgnoreManagers:            co.IgnoreManagers,
 	})
 	if err != nil {
 		return nil, err
@@ -47,6 +47,9 @@ type ManifestOptions struct {
 	DriftWorkers            string
 	cmd.LeaderElectionOptions
 	PriorityClassName string
+	// AgentRevision is reported by the agent, when it checks in. It is set
+	// by manageagent to track agent rollouts.
+	AgentRevision string
 }
 
 // Manifest builds and returns a deployment manifest for the fleet-agent with a
@@ -304,6 +307,10 @@ func agentApp(namespace string, agentScope string, opts ManifestOptions) *appsv1
 			container.Env = append(container.Env, opts.AgentEnvVars...)
 		}
 
+		if opts.AgentRevision != "" {
+			container.Env = append(container.Env, corev1.EnvVar{Name: "AGENT_REVISION", Value: opts.AgentRevision})
+		}
+
 		if DebugEnabled {
 			container.Command = append(container.Command, "--debug", "--debug-level", strconv.Itoa(DebugLevel))
 		}
@@ -420,6 +420,7 @@ func (i *importHandler) importCluster(cluster *fleet.Cluster, status fleet.Clust
 				Labels:                    clusterLabels,
 				AgentTLSMode:              cfg.AgentTLSMode,
 				GarbageCollectionInterval: cfg.GarbageCollectionInterval,
+				IgnoreManagers:            cfg.IgnoreManagers,
 			},
 			// keep in sync with manageagent.go
 			ManifestOptions: agent.ManifestOptions{
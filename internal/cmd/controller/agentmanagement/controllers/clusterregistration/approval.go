@@ -0,0 +1,206 @@
+package clusterregistration
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/rancher/fleet/internal/config"
+	"github.com/rancher/fleet/internal/registration"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/yaml"
+)
+
+// createdByAgentPodLabel is added by the agent to the requested cluster
+// labels, it is not subject to the label restrictions of the token.
+const createdByAgentPodLabel = "fleet.cattle.io/created-by-agent-pod"
+
+// admit checks the registration against the restrictions of its token and
+// whether it needs to be approved. It returns true, if the registration is
+// approved and the cluster can be registered. The state of the registration
+// is set in status.
+func (h *handler) admit(request *fleet.ClusterRegistration, status *fleet.ClusterRegistrationStatus) (bool, error) {
+	switch status.State {
+	case fleet.ClusterRegistrationStateApproved:
+		return true, nil
+	case fleet.ClusterRegistrationStateDenied:
+		return false, nil
+	}
+
+	token, reason, err := h.registrationToken(request)
+	if err != nil {
+		return false, err
+	}
+	if reason == "" && token != nil {
+		reason = checkToken(token, request, time.Now())
+	}
+	if reason == "" && request.Annotations[fleet.ClusterRegistrationApprovedAnnotation] == "false" {
+		reason = "registration was denied"
+	}
+	if reason != "" {
+		deny(request, status, reason)
+		return false, nil
+	}
+
+	if request.Annotations[fleet.ClusterRegistrationApprovedAnnotation] != "true" {
+		required, err := h.approvalRequired(request, token)
+		if err != nil {
+			return false, err
+		}
+		if required {
+			if status.State != fleet.ClusterRegistrationStatePending {
+				logrus.Infof("Cluster registration request '%s/%s' is pending approval", request.Namespace, request.Name)
+			}
+			status.State = fleet.ClusterRegistrationStatePending
+			status.Message = "waiting for approval"
+			return false, nil
+		}
+	}
+
+	if token != nil {
+		if token.Spec.MaxUses > 0 && token.Status.Uses >= token.Spec.MaxUses {
+			deny(request, status, fmt.Sprintf("cluster registration token '%s' was used %d times already", token.Name, token.Status.Uses))
+			return false, nil
+		}
+		token = token.DeepCopy()
+		token.Status.Uses++
+		if _, err := h.tokens.UpdateStatus(token); err != nil {
+			return false, err
+		}
+	}
+
+	status.State = fleet.ClusterRegistrationStateApproved
+	status.Message = ""
+	return true, nil
+}
+
+func deny(request *fleet.ClusterRegistration, status *fleet.ClusterRegistrationStatus, reason string) {
+	logrus.Infof("Cluster registration request '%s/%s' denied: %s", request.Namespace, request.Name, reason)
+	status.State = fleet.ClusterRegistrationStateDenied
+	status.Message = reason
+}
+
+// registrationToken returns the token named by the registration. If the token
+// does not exist, the registration is not signed with the token's secret, or
+// the registration does not name its token while tokens with restrictions
+// exist, the registration is denied for the returned reason.
+//
+// The token name is set by the agent, so it is only trusted after checking
+// the signature. Otherwise an agent could name a token with fewer
+// restrictions than its own.
+func (h *handler) registrationToken(request *fleet.ClusterRegistration) (*fleet.ClusterRegistrationToken, string, error) {
+	if request.Spec.TokenName == "" {
+		// agents before the token restrictions do not name their token
+		tokens, err := h.tokenCache.List(request.Namespace, labels.Everything())
+		if err != nil {
+			return nil, "", err
+		}
+		for _, token := range tokens {
+			if isRestricted(token) {
+				return nil, "registration does not name its cluster registration token, but tokens have restrictions", nil
+			}
+		}
+		return nil, "", nil
+	}
+
+	token, err := h.tokenCache.Get(request.Namespace, request.Spec.TokenName)
+	if apierrors.IsNotFound(err) {
+		return nil, fmt.Sprintf("cluster registration token '%s' not found", request.Spec.TokenName), nil
+	} else if err != nil {
+		return nil, "", err
+	}
+
+	secret, err := h.tokenSecret(token)
+	if err != nil {
+		return nil, "", err
+	}
+	expected := registration.TokenSignature(secret, request.Spec.ClientID, request.Spec.ClientRandom)
+	if secret == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(request.Spec.TokenSignature)) != 1 {
+		return nil, fmt.Sprintf("registration is not signed with cluster registration token '%s'", token.Name), nil
+	}
+	return token, "", nil
+}
+
+// tokenSecret returns the service account token, which agents registering
+// with the cluster registration token use. It is read from the values
+// secret of the token. An empty string is returned if the secret does not
+// exist yet.
+func (h *handler) tokenSecret(token *fleet.ClusterRegistrationToken) (string, error) {
+	if token.Status.SecretName == "" {
+		return "", nil
+	}
+	secret, err := h.secretsCache.Get(token.Namespace, token.Status.SecretName)
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	var values struct {
+		Token string `json:"token"`
+	}
+	if err := yaml.Unmarshal(secret.Data[config.ImportTokenSecretValuesKey], &values); err != nil {
+		return "", err
+	}
+	return values.Token, nil
+}
+
+func isRestricted(token *fleet.ClusterRegistrationToken) bool {
+	return token.Spec.MaxUses > 0 || len(token.Spec.AllowedClusterLabels) > 0 || token.Spec.RequireApproval
+}
+
+// checkToken returns why the token does not allow the registration, or an
+// empty string.
+func checkToken(token *fleet.ClusterRegistrationToken, request *fleet.ClusterRegistration, now time.Time) string {
+	if token.Status.Expires != nil && now.After(token.Status.Expires.Time) {
+		return fmt.Sprintf("cluster registration token '%s' expired", token.Name)
+	}
+
+	if len(token.Spec.AllowedClusterLabels) > 0 && !config.Get().IgnoreClusterRegistrationLabels &&
+		!allowedLabels(token.Spec.AllowedClusterLabels, request.Spec.ClusterLabels) {
+		return fmt.Sprintf("cluster labels are not allowed by cluster registration token '%s'", token.Name)
+	}
+
+	return ""
+}
+
+// allowedLabels returns true if the requested labels are a subset of one of
+// the allowed label sets.
+func allowedLabels(allowed []map[string]string, requested map[string]string) bool {
+	requested = maps.Clone(requested)
+	delete(requested, createdByAgentPodLabel)
+
+	for _, set := range allowed {
+		if labels.SelectorFromSet(requested).Matches(labels.Set(set)) {
+			return true
+		}
+	}
+	return false
+}
+
+// approvalRequired returns true if the registration needs to be approved,
+// because approval is required by the config or by its token. Clusters
+// created by the manager, which have a kubeconfig secret, do not need
+// approval.
+func (h *handler) approvalRequired(request *fleet.ClusterRegistration, token *fleet.ClusterRegistrationToken) (bool, error) {
+	if !config.Get().ClusterRegistrationApproval && (token == nil || !token.Spec.RequireApproval) {
+		return false, nil
+	}
+
+	clusters, err := h.clusterCache.GetByIndex(clusterByClientID, fmt.Sprintf("%s/%s", request.Namespace, request.Spec.ClientID))
+	if err != nil {
+		return false, err
+	}
+	for _, cluster := range clusters {
+		if cluster.Spec.KubeConfigSecret != "" {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,272 @@
+package clusterregistration
+
+import (
+	"time"
+
+	"github.com/rancher/wrangler/v3/pkg/generic/fake"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/rancher/fleet/internal/config"
+	"github.com/rancher/fleet/internal/registration"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ClusterRegistration admit", func() {
+	var (
+		request *fleet.ClusterRegistration
+		status  fleet.ClusterRegistrationStatus
+		token   *fleet.ClusterRegistrationToken
+
+		clusterCache *fake.MockCacheInterface[*fleet.Cluster]
+		secretCache  *fake.MockCacheInterface[*corev1.Secret]
+		tokenCache   *fake.MockCacheInterface[*fleet.ClusterRegistrationToken]
+		tokenClient  *fake.MockClientInterface[*fleet.ClusterRegistrationToken, *fleet.ClusterRegistrationTokenList]
+		h            *handler
+	)
+
+	BeforeEach(func() {
+		ctrl := gomock.NewController(GinkgoT())
+		clusterCache = fake.NewMockCacheInterface[*fleet.Cluster](ctrl)
+		secretCache = fake.NewMockCacheInterface[*corev1.Secret](ctrl)
+		tokenCache = fake.NewMockCacheInterface[*fleet.ClusterRegistrationToken](ctrl)
+		tokenClient = fake.NewMockClientInterface[*fleet.ClusterRegistrationToken, *fleet.ClusterRegistrationTokenList](ctrl)
+
+		h = &handler{
+			clusterCache: clusterCache,
+			secretsCache: secretCache,
+			tokenCache:   tokenCache,
+			tokens:       tokenClient,
+		}
+
+		request = &fleet.ClusterRegistration{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "request-1",
+				Namespace: "fleet-default",
+			},
+			Spec: fleet.ClusterRegistrationSpec{
+				ClientID:       "client-id",
+				ClientRandom:   "client-random",
+				ClusterLabels:  map[string]string{"env": "dev", createdByAgentPodLabel: "fleet-agent-0"},
+				TokenName:      "token",
+				TokenSignature: registration.TokenSignature("sa-token", "client-id", "client-random"),
+			},
+		}
+		status = fleet.ClusterRegistrationStatus{}
+		token = &fleet.ClusterRegistrationToken{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "token",
+				Namespace: "fleet-default",
+			},
+			Status: fleet.ClusterRegistrationTokenStatus{SecretName: "token"},
+		}
+	})
+
+	expectTokenLookup := func() {
+		tokenCache.EXPECT().Get("fleet-default", "token").Return(token, nil)
+		secretCache.EXPECT().Get("fleet-default", "token").Return(&corev1.Secret{
+			Data: map[string][]byte{config.ImportTokenSecretValuesKey: []byte("token: sa-token\n")},
+		}, nil)
+	}
+
+	expectUse := func(uses int) {
+		tokenClient.EXPECT().UpdateStatus(gomock.Any()).DoAndReturn(func(t *fleet.ClusterRegistrationToken) (*fleet.ClusterRegistrationToken, error) {
+			Expect(t.Status.Uses).To(Equal(uses))
+			return t, nil
+		})
+	}
+
+	It("approves registrations with an unrestricted token and counts the use", func() {
+		expectTokenLookup()
+		expectUse(1)
+
+		approved, err := h.admit(request, &status)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(approved).To(BeTrue())
+		Expect(status.State).To(Equal(fleet.ClusterRegistrationStateApproved))
+	})
+
+	It("does not check approved registrations again", func() {
+		status.State = fleet.ClusterRegistrationStateApproved
+
+		approved, err := h.admit(request, &status)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(approved).To(BeTrue())
+	})
+
+	It("does not approve denied registrations", func() {
+		status.State = fleet.ClusterRegistrationStateDenied
+		request.Annotations = map[string]string{fleet.ClusterRegistrationApprovedAnnotation: "true"}
+
+		approved, err := h.admit(request, &status)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(approved).To(BeFalse())
+	})
+
+	It("denies registrations with a missing token", func() {
+		tokenCache.EXPECT().Get("fleet-default", "token").Return(nil, errors.NewNotFound(schema.GroupResource{}, "token"))
+
+		approved, err := h.admit(request, &status)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(approved).To(BeFalse())
+		Expect(status.State).To(Equal(fleet.ClusterRegistrationStateDenied))
+		Expect(status.Message).To(Equal("cluster registration token 'token' not found"))
+	})
+
+	It("denies registrations with an expired token", func() {
+		token.Status.Expires = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+		expectTokenLookup()
+
+		approved, err := h.admit(request, &status)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(approved).To(BeFalse())
+		Expect(status.Message).To(Equal("cluster registration token 'token' expired"))
+	})
+
+	It("denies registrations, which exceed the maximum uses", func() {
+		token.Spec.MaxUses = 2
+		token.Status.Uses = 2
+		expectTokenLookup()
+
+		approved, err := h.admit(request, &status)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(approved).To(BeFalse())
+		Expect(status.State).To(Equal(fleet.ClusterRegistrationStateDenied))
+		Expect(status.Message).To(Equal("cluster registration token 'token' was used 2 times already"))
+	})
+
+	It("approves registrations within the maximum uses", func() {
+		token.Spec.MaxUses = 2
+		token.Status.Uses = 1
+		expectTokenLookup()
+		expectUse(2)
+
+		approved, err := h.admit(request, &status)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(approved).To(BeTrue())
+	})
+
+	It("denies registrations with labels, which are not allowed", func() {
+		token.Spec.AllowedClusterLabels = []map[string]string{{"env": "prod"}}
+		expectTokenLookup()
+
+		approved, err := h.admit(request, &status)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(approved).To(BeFalse())
+		Expect(status.Message).To(Equal("cluster labels are not allowed by cluster registration token 'token'"))
+	})
+
+	It("approves registrations with allowed labels", func() {
+		token.Spec.AllowedClusterLabels = []map[string]string{{"env": "prod"}, {"env": "dev", "region": "eu"}}
+		expectTokenLookup()
+		expectUse(1)
+
+		approved, err := h.admit(request, &status)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(approved).To(BeTrue())
+	})
+
+	It("denies registrations, which are not signed with the named token", func() {
+		request.Spec.TokenSignature = registration.TokenSignature("other-sa-token", "client-id", "client-random")
+		expectTokenLookup()
+
+		approved, err := h.admit(request, &status)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(approved).To(BeFalse())
+		Expect(status.State).To(Equal(fleet.ClusterRegistrationStateDenied))
+		Expect(status.Message).To(Equal("registration is not signed with cluster registration token 'token'"))
+	})
+
+	It("denies registrations, whose signature was copied from another registration", func() {
+		request.Spec.ClientRandom = "other-client-random"
+		expectTokenLookup()
+
+		approved, err := h.admit(request, &status)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(approved).To(BeFalse())
+		Expect(status.State).To(Equal(fleet.ClusterRegistrationStateDenied))
+	})
+
+	It("denies registrations without a token name, if tokens have restrictions", func() {
+		request.Spec.TokenName = ""
+		token.Spec.MaxUses = 1
+		tokenCache.EXPECT().List("fleet-default", gomock.Any()).Return([]*fleet.ClusterRegistrationToken{token}, nil)
+
+		approved, err := h.admit(request, &status)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(approved).To(BeFalse())
+		Expect(status.State).To(Equal(fleet.ClusterRegistrationStateDenied))
+	})
+
+	When("the token requires approval", func() {
+		BeforeEach(func() {
+			token.Spec.RequireApproval = true
+			expectTokenLookup()
+		})
+
+		It("keeps the registration pending", func() {
+			clusterCache.EXPECT().GetByIndex(clusterByClientID, "fleet-default/client-id").Return(nil, nil)
+
+			approved, err := h.admit(request, &status)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(approved).To(BeFalse())
+			Expect(status.State).To(Equal(fleet.ClusterRegistrationStatePending))
+		})
+
+		It("approves annotated registrations", func() {
+			request.Annotations = map[string]string{fleet.ClusterRegistrationApprovedAnnotation: "true"}
+			expectUse(1)
+
+			approved, err := h.admit(request, &status)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(approved).To(BeTrue())
+			Expect(status.State).To(Equal(fleet.ClusterRegistrationStateApproved))
+		})
+
+		It("denies annotated registrations", func() {
+			status.State = fleet.ClusterRegistrationStatePending
+			request.Annotations = map[string]string{fleet.ClusterRegistrationApprovedAnnotation: "false"}
+
+			approved, err := h.admit(request, &status)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(approved).To(BeFalse())
+			Expect(status.State).To(Equal(fleet.ClusterRegistrationStateDenied))
+		})
+	})
+
+	When("approval is required by the config", func() {
+		BeforeEach(func() {
+			config.Set(&config.Config{ClusterRegistrationApproval: true})
+			DeferCleanup(func() {
+				config.Set(&config.Config{})
+			})
+			expectTokenLookup()
+		})
+
+		It("keeps the registration pending", func() {
+			clusterCache.EXPECT().GetByIndex(clusterByClientID, "fleet-default/client-id").Return(nil, nil)
+
+			approved, err := h.admit(request, &status)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(approved).To(BeFalse())
+			Expect(status.State).To(Equal(fleet.ClusterRegistrationStatePending))
+		})
+
+		It("approves registrations of clusters created by the manager", func() {
+			clusterCache.EXPECT().GetByIndex(clusterByClientID, "fleet-default/client-id").Return([]*fleet.Cluster{{
+				Spec: fleet.ClusterSpec{ClientID: "client-id", KubeConfigSecret: "kubeconfig"},
+			}}, nil)
+			expectUse(1)
+
+			approved, err := h.admit(request, &status)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(approved).To(BeTrue())
+		})
+	})
+})
@@ -46,6 +46,8 @@ type handler struct {
 	clusterRegistration         fleetcontrollers.ClusterRegistrationController
 	clusterCache                fleetcontrollers.ClusterCache
 	clusters                    fleetcontrollers.ClusterClient
+	tokenCache                  fleetcontrollers.ClusterRegistrationTokenCache
+	tokens                      fleetcontrollers.ClusterRegistrationTokenClient
 	serviceAccountCache         corecontrollers.ServiceAccountCache
 	secretsCache                corecontrollers.SecretCache
 	secrets                     corecontrollers.SecretController
@@ -60,13 +62,16 @@ func Register(ctx context.Context,
 	role rbaccontrollers.RoleController,
 	roleBinding rbaccontrollers.RoleBindingController,
 	clusterRegistration fleetcontrollers.ClusterRegistrationController,
-	clusters fleetcontrollers.ClusterController) {
+	clusters fleetcontrollers.ClusterController,
+	tokens fleetcontrollers.ClusterRegistrationTokenController) {
 	h := &handler{
 		systemNamespace:             systemNamespace,
 		systemRegistrationNamespace: systemRegistrationNamespace,
 		clusterRegistration:         clusterRegistration,
 		clusterCache:                clusters.Cache(),
 		clusters:                    clusters,
+		tokenCache:                  tokens.Cache(),
+		tokens:                      tokens,
 		serviceAccountCache:         serviceAccount.Cache(),
 		secrets:                     secret,
 		secretsCache:                secret.Cache(),
@@ -165,6 +170,7 @@ func skipClusterRegistration(cr *fleet.ClusterRegistration) bool {
 }
 
 // OnChange creates the service account and roles for a cluster registration.
+// Registrations, which need approval, stay pending until they are approved.
 // The service account's token is deployed to the downstream cluster, via the
 // fleet-secret. It allows the downstream fleet-agent to list
 // bundledeployments and update their status in its own cluster namespace on upstream.
@@ -179,6 +185,10 @@ func (h *handler) OnChange(request *fleet.ClusterRegistration, status fleet.Clus
 		return nil, status, generic.ErrSkip
 	}
 
+	if approved, err := h.admit(request, &status); err != nil || !approved {
+		return nil, status, err
+	}
+
 	cluster, err := h.createOrGetCluster(request)
 	if err != nil || cluster == nil {
 		return nil, status, err
@@ -30,6 +30,7 @@ var _ = Describe("ClusterRegistration OnChange", func() {
 		clusterClient                 *fake.MockClientInterface[*fleet.Cluster, *fleet.ClusterList]
 		clusterRegistrationController *fake.MockControllerInterface[*fleet.ClusterRegistration, *fleet.ClusterRegistrationList]
 		clusterCache                  *fake.MockCacheInterface[*fleet.Cluster]
+		tokenCache                    *fake.MockCacheInterface[*fleet.ClusterRegistrationToken]
 		h                             *handler
 		notFound                      = errors.NewNotFound(schema.GroupResource{}, "")
 		anError                       = fmt.Errorf("an error occurred")
@@ -43,6 +44,9 @@ var _ = Describe("ClusterRegistration OnChange", func() {
 		clusterClient = fake.NewMockClientInterface[*fleet.Cluster, *fleet.ClusterList](ctrl)
 		clusterRegistrationController = fake.NewMockControllerInterface[*fleet.ClusterRegistration, *fleet.ClusterRegistrationList](ctrl)
 		clusterCache = fake.NewMockCacheInterface[*fleet.Cluster](ctrl)
+		tokenCache = fake.NewMockCacheInterface[*fleet.ClusterRegistrationToken](ctrl)
+		// registrations do not name a token and no token has restrictions
+		tokenCache.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
 
 		h = &handler{
 			systemNamespace:             "fleet-system",
@@ -50,6 +54,7 @@ var _ = Describe("ClusterRegistration OnChange", func() {
 			clusterRegistration:         clusterRegistrationController,
 			clusterCache:                clusterCache,
 			clusters:                    clusterClient,
+			tokenCache:                  tokenCache,
 			secretsCache:                secretCache,
 			secrets:                     secretController,
 			serviceAccountCache:         saCache,
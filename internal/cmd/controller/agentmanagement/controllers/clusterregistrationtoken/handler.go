@@ -228,11 +228,12 @@ func (h *handler) clusterRegistrationSecret(token *fleet.ClusterRegistrationToke
 	}
 
 	values := map[string]interface{}{
-		"clusterNamespace":            token.Namespace,
-		config.APIServerURLKey:        config.Get().APIServerURL,
-		config.APIServerCAKey:         string(config.Get().APIServerCA),
-		"token":                       string(secret.Data["token"]), // from service account
-		"systemRegistrationNamespace": h.systemRegistrationNamespace,
+		"clusterNamespace":                 token.Namespace,
+		config.APIServerURLKey:             config.Get().APIServerURL,
+		config.APIServerCAKey:              string(config.Get().APIServerCA),
+		"token":                            string(secret.Data["token"]), // from service account
+		"systemRegistrationNamespace":      h.systemRegistrationNamespace,
+		config.ClusterRegistrationTokenKey: token.Name,
 	}
 
 	if h.systemNamespace != config.DefaultNamespace {
@@ -120,7 +120,8 @@ func Register(ctx context.Context, appCtx *AppContext, systemNamespace string, d
 		appCtx.RBAC.Role(),
 		appCtx.RBAC.RoleBinding(),
 		appCtx.ClusterRegistration(),
-		appCtx.Cluster())
+		appCtx.Cluster(),
+		appCtx.ClusterRegistrationToken())
 
 	clusterregistrationtoken.Register(ctx,
 		systemNamespace,
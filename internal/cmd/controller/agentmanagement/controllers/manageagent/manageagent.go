@@ -11,6 +11,8 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -28,6 +30,7 @@ import (
 	"github.com/rancher/wrangler/v3/pkg/yaml"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -44,6 +47,8 @@ type handler struct {
 	clusterCache    fleetcontrollers.ClusterCache
 	bundleCache     fleetcontrollers.BundleCache
 	namespaces      corecontrollers.NamespaceController
+	// rollouts records the namespaces with an unfinished agent rollout
+	rollouts sync.Map
 }
 
 func Register(ctx context.Context,
@@ -53,7 +58,7 @@ func Register(ctx context.Context,
 	clusters fleetcontrollers.ClusterController,
 	bundle fleetcontrollers.BundleController,
 ) {
-	h := handler{
+	h := &handler{
 		systemNamespace: systemNamespace,
 		clusterCache:    clusters.Cache(),
 		bundleCache:     bundle.Cache(),
@@ -218,7 +223,15 @@ func (h *handler) updateClusterStatus(cluster *fleet.Cluster, status fleet.Clust
 // resolveNS is a handler that enqueues the cluster registration namespace (e.g. fleet-default) for a changed cluster
 func (h *handler) resolveNS(namespace, _ string, obj runtime.Object) ([]relatedresource.Key, error) {
 	if cluster, ok := obj.(*fleet.Cluster); ok {
-		if _, err := h.bundleCache.Get(namespace, names.SafeConcatName(AgentBundleName, cluster.Name)); err != nil {
+		bundle, err := h.bundleCache.Get(namespace, names.SafeConcatName(AgentBundleName, cluster.Name))
+		if err != nil {
+			return []relatedresource.Key{{Name: namespace}}, nil
+		}
+		// continue the agent rollout, when an agent checks in
+		if config.Get().AgentRolloutStrategy == nil {
+			return nil, nil
+		}
+		if _, ok := h.rollouts.Load(namespace); ok || bundle.Annotations[agentRevisionAnnotation] != cluster.Status.Agent.Revision {
 			return []relatedresource.Key{{Name: namespace}}, nil
 		}
 	}
@@ -228,6 +241,7 @@ func (h *handler) resolveNS(namespace, _ string, obj runtime.Object) ([]relatedr
 // OnNamespace updates agent bundles for all clusters in the namespace
 func (h *handler) OnNamespace(key string, namespace *corev1.Namespace) (*corev1.Namespace, error) {
 	if namespace == nil {
+		h.rollouts.Delete(key)
 		return nil, nil
 	}
 
@@ -246,7 +260,7 @@ func (h *handler) OnNamespace(key string, namespace *corev1.Namespace) (*corev1.
 		return namespace, nil
 	}
 
-	var objs []runtime.Object
+	var updates []agentUpdate
 
 	for _, cluster := range clusters {
 		if SkipCluster(cluster) {
@@ -258,6 +272,29 @@ func (h *handler) OnNamespace(key string, namespace *corev1.Namespace) (*corev1.
 			logrus.Errorf("Failed to update agent bundle for cluster %s/%s", cluster.Namespace, cluster.Name)
 			return nil, err
 		}
+
+		current, err := h.bundleCache.Get(namespace.Name, bundle.Name)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		updates = append(updates, agentUpdate{cluster: cluster, current: current, desired: bundle})
+	}
+
+	bundles, status, err := rollout(cfg.AgentRolloutStrategy, updates, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if status.inProgress {
+		h.rollouts.Store(namespace.Name, true)
+	} else {
+		h.rollouts.Delete(namespace.Name)
+	}
+	if status.requeueAfter > 0 {
+		h.namespaces.EnqueueAfter(namespace.Name, status.requeueAfter)
+	}
+
+	objs := make([]runtime.Object, 0, len(bundles))
+	for _, bundle := range bundles {
 		objs = append(objs, bundle)
 	}
 
@@ -268,7 +305,7 @@ func (h *handler) OnNamespace(key string, namespace *corev1.Namespace) (*corev1.
 		ApplyObjects(objs...)
 }
 
-func (h *handler) newAgentBundle(ns string, cluster *fleet.Cluster) (runtime.Object, error) {
+func (h *handler) newAgentBundle(ns string, cluster *fleet.Cluster) (*fleet.Bundle, error) {
 	cfg := config.Get()
 	agentNamespace := h.systemNamespace
 	if cluster.Spec.AgentNamespace != "" {
@@ -286,32 +323,42 @@ func (h *handler) newAgentBundle(ns string, cluster *fleet.Cluster) (runtime.Obj
 		priorityClassName = scheduling.FleetAgentPriorityClassName
 	}
 
+	opts := agent.ManifestOptions{
+		// keep in sync with cluster/import.go
+		AgentEnvVars:     cluster.Spec.AgentEnvVars,
+		AgentTolerations: cluster.Spec.AgentTolerations,
+		PrivateRepoURL:   cluster.Spec.PrivateRepoURL,
+		AgentAffinity:    cluster.Spec.AgentAffinity,
+		AgentResources:   cluster.Spec.AgentResources,
+		HostNetwork:      *cmp.Or(cluster.Spec.HostNetwork, ptr.To(false)),
+
+		// keep in sync with agent/agent.go
+		AgentImage:              cfg.AgentImage,
+		AgentImagePullPolicy:    cfg.AgentImagePullPolicy,
+		CheckinInterval:         cfg.AgentCheckinInterval.Duration.String(),
+		SystemDefaultRegistry:   cfg.SystemDefaultRegistry,
+		BundleDeploymentWorkers: cfg.AgentWorkers.BundleDeployment,
+		DriftWorkers:            cfg.AgentWorkers.Drift,
+		AgentReplicas:           agentReplicas,
+		LeaderElectionOptions:   leaderElectionOptions,
+		PriorityClassName:       priorityClassName,
+	}
+
 	// Notice we only set the agentScope when it's a non-default agentNamespace. This is for backwards compatibility
 	// for when we didn't have agent scope before
-	objs := agent.Manifest(
-		agentNamespace, cluster.Spec.AgentNamespace,
-		agent.ManifestOptions{
-			// keep in sync with cluster/import.go
-			AgentEnvVars:     cluster.Spec.AgentEnvVars,
-			AgentTolerations: cluster.Spec.AgentTolerations,
-			PrivateRepoURL:   cluster.Spec.PrivateRepoURL,
-			AgentAffinity:    cluster.Spec.AgentAffinity,
-			AgentResources:   cluster.Spec.AgentResources,
-			HostNetwork:      *cmp.Or(cluster.Spec.HostNetwork, ptr.To(false)),
-
-			// keep in sync with agent/agent.go
-			AgentImage:              cfg.AgentImage,
-			AgentImagePullPolicy:    cfg.AgentImagePullPolicy,
-			CheckinInterval:         cfg.AgentCheckinInterval.Duration.String(),
-			SystemDefaultRegistry:   cfg.SystemDefaultRegistry,
-			BundleDeploymentWorkers: cfg.AgentWorkers.BundleDeployment,
-			DriftWorkers:            cfg.AgentWorkers.Drift,
-			AgentReplicas:           agentReplicas,
-			LeaderElectionOptions:   leaderElectionOptions,
-			PriorityClassName:       priorityClassName,
-		},
-	)
-	agentYAML, err := yaml.Export(objs...)
+	// The revision identifies the agent manifest, agents report it when
+	// checking in.
+	agentYAML, err := yaml.Export(agent.Manifest(agentNamespace, cluster.Spec.AgentNamespace, opts)...)
+	if err != nil {
+		return nil, err
+	}
+	revision, err := hashStatusField(string(agentYAML))
+	if err != nil {
+		return nil, err
+	}
+	opts.AgentRevision = revision
+
+	agentYAML, err = yaml.Export(agent.Manifest(agentNamespace, cluster.Spec.AgentNamespace, opts)...)
 	if err != nil {
 		return nil, err
 	}
@@ -320,6 +367,9 @@ func (h *handler) newAgentBundle(ns string, cluster *fleet.Cluster) (runtime.Obj
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      names.SafeConcatName(AgentBundleName, cluster.Name),
 			Namespace: ns,
+			Annotations: map[string]string{
+				agentRevisionAnnotation: revision,
+			},
 		},
 		Spec: fleet.BundleSpec{
 			BundleDeploymentOptions: fleet.BundleDeploymentOptions{
@@ -339,7 +389,7 @@ func (h *handler) newAgentBundle(ns string, cluster *fleet.Cluster) (runtime.Obj
 					ClusterSelector: &metav1.LabelSelector{
 						MatchExpressions: []metav1.LabelSelectorRequirement{
 							{
-								Key:      "fleet.cattle.io/non-managed-agent",
+								Key:      nonManagedAgentLabel,
 								Operator: metav1.LabelSelectorOpDoesNotExist,
 							},
 						},
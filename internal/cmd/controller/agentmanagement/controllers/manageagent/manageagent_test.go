@@ -2,13 +2,24 @@ package manageagent
 
 import (
 	"testing"
+	"time"
 
+	fakeapply "github.com/rancher/wrangler/v3/pkg/apply/fake"
 	"github.com/rancher/wrangler/v3/pkg/generic/fake"
+	"github.com/rancher/wrangler/v3/pkg/schemes"
 	"go.uber.org/mock/gomock"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/utils/ptr"
 
+	"github.com/rancher/fleet/internal/config"
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
 )
 
@@ -328,3 +339,115 @@ func TestOnClusterChangeHostNetwork(t *testing.T) {
 		})
 	}
 }
+
+func init() {
+	// register the kinds of the agent manifest, like the controller does
+	utilruntime.Must(schemes.Register(clientgoscheme.AddToScheme))
+}
+
+func TestAgentRolloutContinuesOnCheckin(t *testing.T) {
+	t.Setenv("FLEET_AGENT_ELECTION_LEASE_DURATION", "30s")
+	t.Setenv("FLEET_AGENT_ELECTION_RENEW_DEADLINE", "10s")
+	t.Setenv("FLEET_AGENT_ELECTION_RETRY_PERIOD", "2s")
+	config.Set(&config.Config{AgentRolloutStrategy: &config.AgentRolloutStrategy{
+		Partitions: []config.AgentRolloutPartition{{
+			Name:            "canary",
+			ClusterSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "dev"}},
+			MaxUnavailable:  &intstr.IntOrString{Type: intstr.Int, IntVal: 1},
+		}},
+	}})
+	defer config.Set(&config.Config{})
+
+	const ns = "fleet-default"
+	ctrl := gomock.NewController(t)
+	clusterCache := fake.NewMockCacheInterface[*fleet.Cluster](ctrl)
+	bundleCache := fake.NewMockCacheInterface[*fleet.Bundle](ctrl)
+	namespaces := fake.NewMockNonNamespacedControllerInterface[*corev1.Namespace, *corev1.NamespaceList](ctrl)
+	apply := &fakeapply.FakeApply{}
+	h := &handler{
+		apply:        apply,
+		clusterCache: clusterCache,
+		bundleCache:  bundleCache,
+		namespaces:   namespaces,
+	}
+
+	dev := &fleet.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: ns, Labels: map[string]string{"env": "dev"}}}
+	prod := &fleet.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: ns, Labels: map[string]string{"env": "prod"}}}
+	clusters := []*fleet.Cluster{dev, prod}
+	clusterCache.EXPECT().List(ns, labels.Everything()).Return(clusters, nil).AnyTimes()
+
+	// the agent bundles are deployed with an old revision, which the
+	// agents reported
+	current := map[string]*fleet.Bundle{}
+	for _, c := range clusters {
+		b, err := h.newAgentBundle(ns, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.Annotations[agentRevisionAnnotation] = "old"
+		setUpdated(b, time.Now().Add(-time.Hour))
+		current[b.Name] = b
+		c.Status.Agent.Revision = "old"
+	}
+	bundleCache.EXPECT().Get(ns, gomock.Any()).DoAndReturn(func(_, name string) (*fleet.Bundle, error) {
+		if b, ok := current[name]; ok {
+			return b, nil
+		}
+		return nil, apierrors.NewNotFound(schema.GroupResource{}, name)
+	}).AnyTimes()
+
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}}
+	// onNamespace applies the agent bundles and returns their revisions
+	onNamespace := func() map[string]string {
+		t.Helper()
+		if _, err := h.OnNamespace(ns, namespace); err != nil {
+			t.Fatal(err)
+		}
+		revisions := map[string]string{}
+		for _, obj := range apply.Objects[len(apply.Objects)-1].All() {
+			b := obj.(*fleet.Bundle)
+			revisions[b.Spec.Targets[0].ClusterName] = b.Annotations[agentRevisionAnnotation]
+			current[b.Name] = b
+		}
+		return revisions
+	}
+	// checkin lets the agent of the cluster report its deployed revision
+	checkin := func(c *fleet.Cluster) {
+		c.Status.Agent.Revision = current[AgentBundleName+"-"+c.Name].Annotations[agentRevisionAnnotation]
+	}
+
+	// the canary partition is updated first, the rollout is rechecked
+	// once the agent exceeds its check-in timeout
+	namespaces.EXPECT().EnqueueAfter(ns, defaultAgentCheckinTimeout)
+	revisions := onNamespace()
+	if revisions["dev"] == "old" || revisions["prod"] != "old" {
+		t.Fatalf("expecting only the canary agent to be updated, got %v", revisions)
+	}
+
+	// the check-in of the updated agent continues the rollout
+	checkin(dev)
+	keys, err := h.resolveNS(ns, "", dev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0].Name != ns {
+		t.Fatalf("expecting the namespace to be enqueued, got %v", keys)
+	}
+
+	namespaces.EXPECT().EnqueueAfter(ns, defaultAgentCheckinTimeout)
+	revisions = onNamespace()
+	if revisions["prod"] == "old" {
+		t.Fatalf("expecting the prod agent to be updated, got %v", revisions)
+	}
+
+	// the rollout is finished, once all agents checked in
+	checkin(prod)
+	onNamespace()
+	keys, err = h.resolveNS(ns, "", prod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 0 {
+		t.Errorf("expecting no enqueue after the rollout finished, got %v", keys)
+	}
+}
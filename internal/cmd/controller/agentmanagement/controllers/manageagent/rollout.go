@@ -0,0 +1,299 @@
+package manageagent
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/rancher/fleet/internal/config"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"github.com/rancher/wrangler/v3/pkg/condition"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// agentRevisionAnnotation is the revision of the agent deployed by an
+	// agent bundle. Agents report the revision when they check in.
+	agentRevisionAnnotation = "fleet.cattle.io/agent-revision"
+	// agentUpdatedAnnotation is the time the agent bundle was updated to
+	// its revision.
+	agentUpdatedAnnotation = "fleet.cattle.io/agent-updated"
+	// nonManagedAgentLabel excludes a cluster from the agent bundle's
+	// targets.
+	nonManagedAgentLabel = "fleet.cattle.io/non-managed-agent"
+
+	defaultAgentMaxUnavailable = "10%"
+	defaultAgentCheckinTimeout = 15 * time.Minute
+)
+
+// agentUpdate is the agent bundle of a cluster, which is currently deployed
+// and which should be deployed.
+type agentUpdate struct {
+	cluster *fleet.Cluster
+	// current is nil, if the agent bundle does not exist yet
+	current *fleet.Bundle
+	desired *fleet.Bundle
+}
+
+func (u agentUpdate) outdated() bool {
+	return u.current != nil && u.current.Annotations[agentRevisionAnnotation] != u.desired.Annotations[agentRevisionAnnotation]
+}
+
+// updating returns true, if the agent bundle was updated, but the agent did
+// not check in with the new revision yet.
+func (u agentUpdate) updating() bool {
+	return !u.outdated() && u.cluster.Status.Agent.Revision != u.desired.Annotations[agentRevisionAnnotation]
+}
+
+// rolloutStatus reports whether an agent rollout is unfinished and when it
+// has to be checked again.
+type rolloutStatus struct {
+	// inProgress is true, while agent bundles are outdated or agents did
+	// not check in with their new revision.
+	inProgress bool
+	// requeueAfter is the time until the next agent exceeds its check-in
+	// timeout. It is zero, if no agent is waited for.
+	requeueAfter time.Duration
+}
+
+// recheck sets requeueAfter to d, unless it is already earlier.
+func (s *rolloutStatus) recheck(d time.Duration) {
+	if s.requeueAfter == 0 || d < s.requeueAfter {
+		s.requeueAfter = d
+	}
+}
+
+// rollout returns the agent bundles to apply. New agent bundles are always
+// created, outdated agent bundles are updated as allowed by the strategy and
+// keep their current content otherwise. If strategy is nil, all agent bundles
+// are updated.
+func rollout(strategy *config.AgentRolloutStrategy, updates []agentUpdate, now time.Time) ([]*fleet.Bundle, rolloutStatus, error) {
+	var (
+		bundles []*fleet.Bundle
+		tracked []agentUpdate
+	)
+	for _, u := range updates {
+		switch {
+		case !u.outdated():
+			keepUpdated(u, now)
+			bundles = append(bundles, u.desired)
+		case strategy == nil || !isTracked(strategy, u.cluster):
+			// the agent cannot check in or is not deployed by the bundle
+			setUpdated(u.desired, now)
+			bundles = append(bundles, u.desired)
+		default:
+			tracked = append(tracked, u)
+		}
+	}
+	if strategy == nil {
+		return bundles, rolloutStatus{}, nil
+	}
+
+	// agents which did not check in yet are unavailable
+	timeout := strategy.CheckinTimeout.Duration
+	if timeout <= 0 {
+		timeout = defaultAgentCheckinTimeout
+	}
+	var status rolloutStatus
+	unavailable := 0
+	paused := false
+	for _, u := range updates {
+		if u.current == nil || u.outdated() || !u.updating() || !isTracked(strategy, u.cluster) {
+			continue
+		}
+		unavailable++
+		deadline := updated(u.current).Add(timeout)
+		if deadline.Before(now) {
+			logrus.Warnf("Agent of cluster %s/%s did not check in with revision %s within %s, pausing agent rollout",
+				u.cluster.Namespace, u.cluster.Name, u.desired.Annotations[agentRevisionAnnotation], timeout)
+			paused = true
+			continue
+		}
+		// pause the rollout, if the agent does not check in in time
+		status.recheck(deadline.Sub(now))
+	}
+
+	partitions, err := agentPartitions(strategy, updates)
+	if err != nil {
+		return nil, rolloutStatus{}, err
+	}
+
+	maxUnavailable := limit(len(updates), strategy.MaxUnavailable)
+	for _, p := range partitions {
+		if paused {
+			break
+		}
+
+		partitionMax := limit(len(p.updates), p.maxUnavailable, strategy.MaxUnavailable)
+		partitionUnavailable := 0
+		for _, u := range p.updates {
+			if u.current != nil && !u.outdated() && u.updating() && isTracked(strategy, u.cluster) {
+				partitionUnavailable++
+			}
+		}
+
+		done := true
+		for _, u := range p.updates {
+			if !u.outdated() || !isTracked(strategy, u.cluster) {
+				continue
+			}
+			if unavailable >= maxUnavailable || partitionUnavailable >= partitionMax {
+				done = false
+				break
+			}
+			logrus.Infof("Updating agent of cluster %s/%s to revision %s in partition %s",
+				u.cluster.Namespace, u.cluster.Name, u.desired.Annotations[agentRevisionAnnotation], p.name)
+			setUpdated(u.desired, now)
+			status.recheck(timeout)
+			unavailable++
+			partitionUnavailable++
+		}
+
+		// the next partition is updated, once all agents of this
+		// partition checked in with their new revision
+		if !done || partitionUnavailable > 0 {
+			break
+		}
+	}
+
+	for _, u := range tracked {
+		if u.desired.Annotations[agentUpdatedAnnotation] == "" {
+			// not updated yet, keep the current agent bundle
+			bundles = append(bundles, keep(u))
+			continue
+		}
+		bundles = append(bundles, u.desired)
+	}
+
+	status.inProgress = unavailable > 0 || len(tracked) > 0
+	return bundles, status, nil
+}
+
+// isTracked returns true if the rollout waits for the agent of the cluster to
+// check in.
+func isTracked(strategy *config.AgentRolloutStrategy, cluster *fleet.Cluster) bool {
+	if _, ok := cluster.Labels[nonManagedAgentLabel]; ok {
+		return false
+	}
+	if strategy.OfflineClusterPolicy != fleet.OfflineClusterPolicyUnavailable &&
+		condition.Cond(fleet.ClusterConditionOffline).IsTrue(cluster) {
+		return false
+	}
+	return true
+}
+
+type agentPartition struct {
+	name           string
+	maxUnavailable *intstr.IntOrString
+	updates        []*agentUpdate
+}
+
+// agentPartitions assigns the agent updates to the partitions of the
+// strategy. Updates, which match no partition, are added to a last partition.
+func agentPartitions(strategy *config.AgentRolloutStrategy, updates []agentUpdate) ([]agentPartition, error) {
+	partitions := make([]agentPartition, 0, len(strategy.Partitions)+1)
+	selectors := make([]labels.Selector, 0, len(strategy.Partitions))
+	for i, p := range strategy.Partitions {
+		name := p.Name
+		if name == "" {
+			name = "partition-" + strconv.Itoa(i)
+		}
+		partitions = append(partitions, agentPartition{name: name, maxUnavailable: p.MaxUnavailable})
+
+		selector := labels.Nothing()
+		if p.ClusterSelector != nil {
+			var err error
+			selector, err = metav1.LabelSelectorAsSelector(p.ClusterSelector)
+			if err != nil {
+				return nil, err
+			}
+		}
+		selectors = append(selectors, selector)
+	}
+	partitions = append(partitions, agentPartition{name: "default"})
+
+	for i := range updates {
+		u := &updates[i]
+		idx := len(partitions) - 1
+		for j, selector := range selectors {
+			if selector.Matches(labels.Set(u.cluster.Labels)) {
+				idx = j
+				break
+			}
+		}
+		partitions[idx].updates = append(partitions[idx].updates, u)
+	}
+
+	for _, p := range partitions {
+		sort.Slice(p.updates, func(i, j int) bool {
+			return p.updates[i].cluster.Name < p.updates[j].cluster.Name
+		})
+	}
+	return partitions, nil
+}
+
+// limit returns the number of unavailable clusters allowed for count
+// clusters, from the first non-nil value. It is at least one.
+func limit(count int, vals ...*intstr.IntOrString) int {
+	val := intstr.FromString(defaultAgentMaxUnavailable)
+	for _, v := range vals {
+		if v != nil {
+			val = *v
+			break
+		}
+	}
+
+	n, err := intstr.GetScaledValueFromIntOrPercent(&val, count, true)
+	if err != nil {
+		logrus.Errorf("Invalid agent rollout maxUnavailable %s, using %s: %v", val.String(), defaultAgentMaxUnavailable, err)
+		v := intstr.FromString(defaultAgentMaxUnavailable)
+		n, _ = intstr.GetScaledValueFromIntOrPercent(&v, count, true)
+	}
+	return max(n, 1)
+}
+
+func updated(b *fleet.Bundle) time.Time {
+	t, err := time.Parse(time.RFC3339, b.Annotations[agentUpdatedAnnotation])
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func setUpdated(b *fleet.Bundle, now time.Time) {
+	if b.Annotations == nil {
+		b.Annotations = map[string]string{}
+	}
+	b.Annotations[agentUpdatedAnnotation] = now.UTC().Format(time.RFC3339)
+}
+
+// keepUpdated keeps the update time of the current agent bundle, so the
+// desired agent bundle does not change on every reconcile.
+func keepUpdated(u agentUpdate, now time.Time) {
+	if u.current == nil || u.current.Annotations[agentUpdatedAnnotation] == "" {
+		setUpdated(u.desired, now)
+		return
+	}
+	u.desired.Annotations[agentUpdatedAnnotation] = u.current.Annotations[agentUpdatedAnnotation]
+}
+
+// keep returns the current agent bundle for applying it again.
+func keep(u agentUpdate) *fleet.Bundle {
+	return &fleet.Bundle{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      u.current.Name,
+			Namespace: u.current.Namespace,
+			Annotations: map[string]string{
+				agentRevisionAnnotation: u.current.Annotations[agentRevisionAnnotation],
+				agentUpdatedAnnotation:  u.current.Annotations[agentUpdatedAnnotation],
+			},
+		},
+		Spec: *u.current.Spec.DeepCopy(),
+	}
+}
@@ -0,0 +1,171 @@
+package manageagent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rancher/wrangler/v3/pkg/genericcondition"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/rancher/fleet/internal/config"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+)
+
+func TestRollout(t *testing.T) {
+	now := time.Now()
+
+	cluster := func(name, env, reported string) *fleet.Cluster {
+		return &fleet.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"env": env}},
+			Status:     fleet.ClusterStatus{Agent: fleet.AgentStatus{Revision: reported}},
+		}
+	}
+	offline := func(c *fleet.Cluster) *fleet.Cluster {
+		c.Status.Conditions = []genericcondition.GenericCondition{{Type: fleet.ClusterConditionOffline, Status: corev1.ConditionTrue}}
+		return c
+	}
+	bundle := func(name, revision string, updated time.Time) *fleet.Bundle {
+		b := &fleet.Bundle{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{agentRevisionAnnotation: revision},
+		}}
+		if !updated.IsZero() {
+			setUpdated(b, updated)
+		}
+		return b
+	}
+	// update returns an agent update from revision "old" to "new", or an
+	// up to date agent bundle, if current is "new"
+	update := func(c *fleet.Cluster, current string) agentUpdate {
+		u := agentUpdate{cluster: c, desired: bundle(c.Name, "new", time.Time{})}
+		if current != "" {
+			u.current = bundle(c.Name, current, now.Add(-time.Minute))
+		}
+		return u
+	}
+
+	strategy := &config.AgentRolloutStrategy{
+		Partitions: []config.AgentRolloutPartition{{
+			Name:            "canary",
+			ClusterSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "dev"}},
+			MaxUnavailable:  &intstr.IntOrString{Type: intstr.Int, IntVal: 1},
+		}},
+	}
+
+	tests := map[string]struct {
+		strategy *config.AgentRolloutStrategy
+		updates  []agentUpdate
+		expected map[string]string
+	}{
+		"without strategy all agents are updated": {
+			updates: []agentUpdate{
+				update(cluster("dev-a", "dev", "old"), "old"),
+				update(cluster("prod-a", "prod", "old"), "old"),
+			},
+			expected: map[string]string{"dev-a": "new", "prod-a": "new"},
+		},
+		"first partition is updated first": {
+			strategy: strategy,
+			updates: []agentUpdate{
+				update(cluster("dev-a", "dev", "old"), "old"),
+				update(cluster("dev-b", "dev", "old"), "old"),
+				update(cluster("prod-a", "prod", "old"), "old"),
+			},
+			expected: map[string]string{"dev-a": "new", "dev-b": "old", "prod-a": "old"},
+		},
+		"partition continues, when updated agents checked in": {
+			strategy: strategy,
+			updates: []agentUpdate{
+				update(cluster("dev-a", "dev", "new"), "new"),
+				update(cluster("dev-b", "dev", "old"), "old"),
+				update(cluster("prod-a", "prod", "old"), "old"),
+			},
+			expected: map[string]string{"dev-a": "new", "dev-b": "new", "prod-a": "old"},
+		},
+		"next partition waits for agents to check in": {
+			strategy: strategy,
+			updates: []agentUpdate{
+				update(cluster("dev-a", "dev", "new"), "new"),
+				update(cluster("dev-b", "dev", "old"), "new"),
+				update(cluster("prod-a", "prod", "old"), "old"),
+			},
+			expected: map[string]string{"dev-a": "new", "dev-b": "new", "prod-a": "old"},
+		},
+		"next partition is updated up to max unavailable": {
+			strategy: strategy,
+			updates: []agentUpdate{
+				update(cluster("dev-a", "dev", "new"), "new"),
+				update(cluster("prod-a", "prod", "old"), "old"),
+				update(cluster("prod-b", "prod", "old"), "old"),
+			},
+			expected: map[string]string{"dev-a": "new", "prod-a": "new", "prod-b": "old"},
+		},
+		"rollout pauses if agents do not check in": {
+			strategy: &config.AgentRolloutStrategy{
+				MaxUnavailable: &intstr.IntOrString{Type: intstr.String, StrVal: "100%"},
+				CheckinTimeout: metav1.Duration{Duration: 30 * time.Second},
+			},
+			updates: []agentUpdate{
+				update(cluster("dev-a", "dev", "old"), "new"),
+				update(cluster("dev-b", "dev", "old"), "old"),
+			},
+			expected: map[string]string{"dev-a": "new", "dev-b": "old"},
+		},
+		"offline clusters are ignored": {
+			strategy: strategy,
+			updates: []agentUpdate{
+				update(offline(cluster("dev-a", "dev", "old")), "old"),
+				update(cluster("dev-b", "dev", "old"), "old"),
+				update(cluster("dev-c", "dev", "old"), "old"),
+			},
+			expected: map[string]string{"dev-a": "new", "dev-b": "new", "dev-c": "old"},
+		},
+		"offline clusters can be unavailable": {
+			strategy: &config.AgentRolloutStrategy{
+				Partitions:           strategy.Partitions,
+				OfflineClusterPolicy: fleet.OfflineClusterPolicyUnavailable,
+			},
+			updates: []agentUpdate{
+				update(offline(cluster("dev-a", "dev", "old")), "old"),
+				update(cluster("dev-b", "dev", "old"), "old"),
+			},
+			expected: map[string]string{"dev-a": "new", "dev-b": "old"},
+		},
+		"new clusters and non-managed agents are not held back": {
+			strategy: strategy,
+			updates: []agentUpdate{
+				update(cluster("dev-a", "dev", "old"), "old"),
+				update(cluster("dev-b", "dev", ""), ""),
+				func() agentUpdate {
+					c := cluster("dev-c", "dev", "old")
+					c.Labels[nonManagedAgentLabel] = "true"
+					return update(c, "old")
+				}(),
+			},
+			expected: map[string]string{"dev-a": "new", "dev-b": "new", "dev-c": "new"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			bundles, _, err := rollout(tt.strategy, tt.updates, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			revisions := map[string]string{}
+			for _, b := range bundles {
+				revisions[b.Name] = b.Annotations[agentRevisionAnnotation]
+				if b.Annotations[agentUpdatedAnnotation] == "" {
+					t.Errorf("expected bundle %s to have an update time", b.Name)
+				}
+			}
+			if diff := cmp.Diff(tt.expected, revisions); diff != "" {
+				t.Errorf("unexpected agent revisions (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
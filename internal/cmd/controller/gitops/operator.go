@@ -156,10 +156,21 @@ func (g *GitOperator) Run(cmd *cobra.Command, args []string) error {
 	}
 
 	statusReconciler := &reconciler.StatusReconciler{
-		Client:  mgr.GetClient(),
-		Scheme:  mgr.GetScheme(),
-		ShardID: g.ShardID,
-		Workers: workers,
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		ShardID:  g.ShardID,
+		Workers:  workers,
+		Recorder: mgr.GetEventRecorderFor(fmt.Sprintf("fleet-gitops-status%s", shardIDSuffix)),
+		NewForge: reconciler.NewForge,
+	}
+
+	pullRequestReconciler := &reconciler.PullRequestReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		ShardID:  g.ShardID,
+		Workers:  workers,
+		Recorder: mgr.GetEventRecorderFor(fmt.Sprintf("fleet-gitops-pullrequests%s", shardIDSuffix)),
+		NewForge: reconciler.NewForge,
 	}
 
 	configReconciler := &fcreconciler.ConfigReconciler{
@@ -194,6 +205,11 @@ func (g *GitOperator) Run(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
+		setupLog.Info("starting gitops pull request controller")
+		if err = pullRequestReconciler.SetupWithManager(mgr); err != nil {
+			return err
+		}
+
 		return mgr.Start(ctx)
 	})
 
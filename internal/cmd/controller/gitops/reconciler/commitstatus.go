@@ -0,0 +1,103 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rancher/fleet/internal/forge"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// maxCommitStatusDescription is the shortest limit of the providers, GitHub
+// rejects longer descriptions.
+const maxCommitStatusDescription = 140
+
+// reportCommitStatus posts the deployment state of the gitrepo's commit to
+// the git hosting provider, if it changed since it was last reported.
+func (r *StatusReconciler) reportCommitStatus(ctx context.Context, gitrepo *fleet.GitRepo, list *fleet.BundleDeploymentList) error {
+	opts := gitrepo.Spec.CommitStatus
+	// while a gitjob is running or after it failed, status.commit is
+	// still the previous commit
+	if opts == nil || gitrepo.Status.Commit == "" || gitrepo.Status.GitJobStatus != "Current" {
+		return nil
+	}
+
+	state, description := commitState(gitrepo, list)
+	reported := gitrepo.Status.Commit + ":" + string(state)
+	if gitrepo.Status.CommitStatus == reported {
+		return nil
+	}
+
+	secretName := opts.SecretName
+	if secretName == "" {
+		secretName = gitrepo.Spec.ClientSecretName
+	}
+	creds, err := forge.LoadCredentials(ctx, r.Client, gitrepo.Namespace, secretName, gitrepo.Spec.Repo)
+	if err != nil {
+		return err
+	}
+
+	fc, err := r.NewForge(opts.Provider, opts.APIURL, gitrepo.Spec.Repo, creds)
+	if err != nil {
+		return err
+	}
+
+	statusContext := opts.Context
+	if statusContext == "" {
+		statusContext = "fleet/" + gitrepo.Name
+	}
+	if err := fc.SetCommitStatus(ctx, gitrepo.Status.Commit, forge.CommitStatus{
+		State:       state,
+		Context:     statusContext,
+		Description: description,
+		TargetURL:   opts.TargetURL,
+	}); err != nil {
+		return fmt.Errorf("failed to report commit status: %w", err)
+	}
+
+	gitrepo.Status.CommitStatus = reported
+	return nil
+}
+
+// commitState returns the state of the gitrepo's commit from its aggregated
+// bundle deployments and a description for the commit status.
+func commitState(gitrepo *fleet.GitRepo, list *fleet.BundleDeploymentList) (forge.CommitState, string) {
+	summary := gitrepo.Status.Summary
+
+	if summary.ErrApplied > 0 {
+		return forge.CommitStateFailure, fmt.Sprintf("%d/%d bundle deployments failed to deploy", summary.ErrApplied, summary.DesiredReady)
+	}
+
+	// bundles which fail to render have no bundle deployments, their
+	// error is copied to the gitrepo's ready condition
+	if len(list.Items) == 0 {
+		for _, c := range gitrepo.Status.Conditions {
+			if c.Type == string(fleet.Ready) && c.Status == corev1.ConditionFalse && c.Message != "" {
+				return forge.CommitStateFailure, truncate(c.Message, maxCommitStatusDescription)
+			}
+		}
+	}
+
+	for _, bd := range list.Items {
+		if bd.Labels[fleet.CommitLabel] != gitrepo.Status.Commit {
+			return forge.CommitStatePending, "Waiting for bundle deployments to be updated"
+		}
+	}
+
+	ready := fmt.Sprintf("%d/%d bundle deployments ready", summary.Ready, summary.DesiredReady)
+	if summary.Ready < summary.DesiredReady {
+		return forge.CommitStatePending, ready
+	}
+
+	return forge.CommitStateSuccess, ready
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
@@ -0,0 +1,152 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rancher/wrangler/v3/pkg/genericcondition"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/rancher/fleet/internal/forge"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+)
+
+func bundleDeployments(commits ...string) *fleet.BundleDeploymentList {
+	list := &fleet.BundleDeploymentList{}
+	for _, commit := range commits {
+		list.Items = append(list.Items, fleet.BundleDeployment{ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{fleet.CommitLabel: commit},
+		}})
+	}
+	return list
+}
+
+func TestCommitState(t *testing.T) {
+	tests := []struct {
+		name      string
+		summary   fleet.BundleSummary
+		condition *genericcondition.GenericCondition
+		list      *fleet.BundleDeploymentList
+		want      forge.CommitState
+		wantDesc  string
+	}{
+		{
+			name:     "all ready",
+			summary:  fleet.BundleSummary{Ready: 2, DesiredReady: 2},
+			list:     bundleDeployments("abc", "abc"),
+			want:     forge.CommitStateSuccess,
+			wantDesc: "2/2 bundle deployments ready",
+		},
+		{
+			name:     "not ready",
+			summary:  fleet.BundleSummary{Ready: 1, NotReady: 1, DesiredReady: 2},
+			list:     bundleDeployments("abc", "abc"),
+			want:     forge.CommitStatePending,
+			wantDesc: "1/2 bundle deployments ready",
+		},
+		{
+			name:     "previous commit still deployed",
+			summary:  fleet.BundleSummary{Ready: 2, DesiredReady: 2},
+			list:     bundleDeployments("abc", "old"),
+			want:     forge.CommitStatePending,
+			wantDesc: "Waiting for bundle deployments to be updated",
+		},
+		{
+			name:     "failed to deploy",
+			summary:  fleet.BundleSummary{Ready: 1, ErrApplied: 1, DesiredReady: 2},
+			list:     bundleDeployments("abc", "abc"),
+			want:     forge.CommitStateFailure,
+			wantDesc: "1/2 bundle deployments failed to deploy",
+		},
+		{
+			name:    "failed to render",
+			summary: fleet.BundleSummary{},
+			condition: &genericcondition.GenericCondition{
+				Type:    string(fleet.Ready),
+				Status:  corev1.ConditionFalse,
+				Message: "helm chart not found",
+			},
+			list:     bundleDeployments(),
+			want:     forge.CommitStateFailure,
+			wantDesc: "helm chart not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitrepo := &fleet.GitRepo{}
+			gitrepo.Status.Commit = "abc"
+			gitrepo.Status.Summary = tt.summary
+			if tt.condition != nil {
+				gitrepo.Status.Conditions = []genericcondition.GenericCondition{*tt.condition}
+			}
+
+			state, desc := commitState(gitrepo, tt.list)
+			assert.Equal(t, tt.want, state)
+			assert.Equal(t, tt.wantDesc, desc)
+		})
+	}
+}
+
+func TestReportCommitStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-local", Name: "creds"},
+		Type:       corev1.SecretTypeBasicAuth,
+		Data: map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte("fleet"),
+			corev1.BasicAuthPasswordKey: []byte("secret"),
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+
+	fc := &fakeForge{}
+	var creds forge.Credentials
+	r := &StatusReconciler{
+		Client:   c,
+		Recorder: record.NewFakeRecorder(10),
+		NewForge: func(provider, apiURL, repoURL string, c forge.Credentials) (forge.Client, error) {
+			creds = c
+			return fc, nil
+		},
+	}
+
+	gitrepo := &fleet.GitRepo{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-local", Name: "apps"},
+		Spec: fleet.GitRepoSpec{
+			Repo:             "https://github.com/rancher/fleet-examples",
+			ClientSecretName: "creds",
+			CommitStatus:     &fleet.CommitStatusReporting{Provider: forge.GitHub},
+		},
+	}
+	gitrepo.Status.Commit = "abc"
+	gitrepo.Status.GitJobStatus = "InProgress"
+	gitrepo.Status.Summary = fleet.BundleSummary{Ready: 1, DesiredReady: 1}
+	list := bundleDeployments("abc")
+
+	// nothing is reported while the gitjob is running
+	require.NoError(t, r.reportCommitStatus(context.TODO(), gitrepo, list))
+	assert.Empty(t, fc.statuses)
+
+	gitrepo.Status.GitJobStatus = "Current"
+	require.NoError(t, r.reportCommitStatus(context.TODO(), gitrepo, list))
+	assert.Equal(t, forge.Credentials{Username: "fleet", Token: "secret"}, creds, "credentials of the client secret are used")
+	assert.Equal(t, forge.CommitStatus{
+		State:       forge.CommitStateSuccess,
+		Context:     "fleet/apps",
+		Description: "1/1 bundle deployments ready",
+	}, fc.statuses["abc"])
+	assert.Equal(t, "abc:success", gitrepo.Status.CommitStatus)
+
+	// unchanged states are not reported again
+	fc.statuses = nil
+	require.NoError(t, r.reportCommitStatus(context.TODO(), gitrepo, list))
+	assert.Empty(t, fc.statuses)
+}
@@ -0,0 +1,14 @@
+package reconciler
+
+import (
+	"github.com/rancher/fleet/internal/forge"
+)
+
+// NewForgeFunc returns a client for the API of a git hosting provider.
+type NewForgeFunc func(provider, apiURL, repoURL string, creds forge.Credentials) (forge.Client, error)
+
+// NewForge is the default NewForgeFunc, it uses the shared HTTP client of the
+// forge package, which has a timeout.
+func NewForge(provider, apiURL, repoURL string, creds forge.Credentials) (forge.Client, error) {
+	return forge.New(provider, apiURL, repoURL, creds, forge.HTTPClient)
+}
@@ -20,6 +20,7 @@ import (
 	v1alpha1 "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
 	"github.com/rancher/fleet/pkg/cert"
 	fleetevent "github.com/rancher/fleet/pkg/event"
+	"github.com/rancher/fleet/pkg/git"
 	"github.com/rancher/fleet/pkg/sharding"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -41,6 +42,7 @@ const (
 	ociRegistryAuthVolumeName = "oci-auth"
 	gitClonerVolumeName       = "git-cloner"
 	emptyDirVolumeName        = "git-cloner-empty-dir"
+	verificationVolumeName    = "git-verification"
 
 	fleetHomeDir = "/fleet-home"
 
@@ -231,6 +233,17 @@ func (r *GitJobReconciler) newGitJob(ctx context.Context, obj *v1alpha1.GitRepo)
 		})
 	}
 
+	if obj.Spec.Verification != nil {
+		job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name: verificationVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: obj.Spec.Verification.SecretName,
+				},
+			},
+		})
+	}
+
 	if obj.Spec.ClientSecretName != "" {
 		job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes,
 			corev1.Volume{
@@ -567,6 +580,32 @@ func (r *GitJobReconciler) newGitCloner(
 		args = append(args, "--ca-bundle-file", "/gitjob/cabundle/"+bundleCAFile)
 	}
 
+	if obj.Spec.Verification != nil {
+		var verificationSecret corev1.Secret
+		if err := r.Get(ctx, types.NamespacedName{
+			Namespace: obj.Namespace,
+			Name:      obj.Spec.Verification.SecretName,
+		}, &verificationSecret); err != nil {
+			return corev1.Container{}, fmt.Errorf("failed to get verification secret: %w", err)
+		}
+
+		// fail closed, the cloner does not verify anything without keys
+		if _, err := git.VerifierFromSecret(&verificationSecret); err != nil {
+			return corev1.Container{}, fmt.Errorf("%w: verification secret %s: %w", git.ErrVerificationFailed, verificationSecret.Name, err)
+		}
+
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      verificationVolumeName,
+			MountPath: "/gitjob/verification",
+		})
+		if len(verificationSecret.Data[git.VerificationGPGKey]) > 0 {
+			args = append(args, "--verify-gpg-keys-file", "/gitjob/verification/"+git.VerificationGPGKey)
+		}
+		if len(verificationSecret.Data[git.VerificationAllowedSignersKey]) > 0 {
+			args = append(args, "--verify-allowed-signers-file", "/gitjob/verification/"+git.VerificationAllowedSignersKey)
+		}
+	}
+
 	env := []corev1.EnvVar{
 		{
 			Name:  fleetapply.JSONOutputEnvVar,
@@ -3,6 +3,7 @@ package reconciler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand/v2"
 	"sort"
@@ -19,6 +20,7 @@ import (
 	v1alpha1 "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
 	"github.com/rancher/fleet/pkg/durations"
 	fleetevent "github.com/rancher/fleet/pkg/event"
+	"github.com/rancher/fleet/pkg/git"
 	"github.com/rancher/fleet/pkg/sharding"
 
 	"github.com/rancher/wrangler/v3/pkg/condition"
@@ -218,7 +220,10 @@ func (r *GitJobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 
 	oldCommit := gitrepo.Status.Commit
 	repoPolled, err := r.repoPolled(ctx, gitrepo)
-	if err != nil {
+	if errors.Is(err, git.ErrVerificationFailed) {
+		r.Recorder.Event(gitrepo, fleetevent.Warning, "VerificationFailed", err.Error())
+		logger.Info("Failed to verify latest commit", "error", err)
+	} else if err != nil {
 		r.Recorder.Event(gitrepo, fleetevent.Warning, "FailedToCheckCommit", err.Error())
 		logger.Info("Failed to check for latest commit", "error", err)
 	} else if repoPolled && oldCommit != gitrepo.Status.Commit {
@@ -283,6 +288,9 @@ func (r *GitJobReconciler) manageGitJob(ctx context.Context, logger logr.Logger,
 				return r.GitFetcher.LatestCommit(ctx, gitrepo, r.Client)
 			})
 			condition.Cond(gitPollingCondition).SetError(&gitrepo.Status, "", err)
+			if err != nil {
+				setVerificationCondition(gitrepo, err)
+			}
 			if err == nil && commit != "" {
 				gitrepo.Status.Commit = commit
 			}
@@ -302,6 +310,7 @@ func (r *GitJobReconciler) manageGitJob(ctx context.Context, logger logr.Logger,
 				return r.result(gitrepo), fmt.Errorf("error validating external secrets: %w", err)
 			}
 			if err := r.createJobAndResources(ctx, gitrepo, logger); err != nil {
+				setVerificationCondition(gitrepo, err)
 				gitjobsCreatedFailure.Inc(gitrepo)
 				return r.result(gitrepo), err
 			}
@@ -516,6 +525,7 @@ func (r *GitJobReconciler) repoPolled(ctx context.Context, gitrepo *v1alpha1.Git
 		})
 		condition.Cond(gitPollingCondition).SetError(&gitrepo.Status, "", err)
 		if err != nil {
+			setVerificationCondition(gitrepo, err)
 			return true, err
 		}
 		gitrepo.Status.Commit = commit
@@ -615,6 +625,7 @@ func setStatusFromGitjob(ctx context.Context, c client.Client, gitRepo *v1alpha1
 	}
 
 	terminationMessage := ""
+	verificationFailed := false
 	if result.Status == status.FailedStatus {
 		selector := labels.SelectorFromSet(labels.Set{"job-name": job.Name})
 		podList := &corev1.PodList{}
@@ -641,6 +652,8 @@ func setStatusFromGitjob(ctx context.Context, c client.Client, gitRepo *v1alpha1
 					podStatus.State.Terminated != nil &&
 					podStatus.State.Terminated.ExitCode != 0 {
 					terminationMessage += podStatus.State.Terminated.Message
+					verificationFailed = verificationFailed ||
+						podStatus.State.Terminated.ExitCode == git.VerificationFailedExitCode
 				}
 			}
 		}
@@ -665,9 +678,13 @@ func setStatusFromGitjob(ctx context.Context, c client.Client, gitRepo *v1alpha1
 	switch result.Status {
 	case status.FailedStatus:
 		kstatus.SetError(gitRepo, filterFleetCLIJobOutput(terminationMessage))
+		if verificationFailed {
+			setVerificationCondition(gitRepo, fmt.Errorf("%w: %s", git.ErrVerificationFailed, filterFleetCLIJobOutput(terminationMessage)))
+		}
 	case status.CurrentStatus:
 		if strings.Contains(result.Message, "Job Completed") {
 			gitRepo.Status.Commit = job.Annotations["commit"]
+			setVerificationCondition(gitRepo, nil)
 		}
 		kstatus.SetActive(gitRepo)
 	case status.InProgressStatus:
@@ -682,6 +699,23 @@ func setStatusFromGitjob(ctx context.Context, c client.Client, gitRepo *v1alpha1
 	return nil
 }
 
+// setVerificationCondition sets the VerificationFailed condition, if the
+// GitRepo requires signed commits. Errors other than failed verifications
+// leave the condition unchanged.
+func setVerificationCondition(gitrepo *v1alpha1.GitRepo, err error) {
+	if gitrepo.Spec.Verification == nil {
+		return
+	}
+	switch {
+	case err == nil:
+		condition.Cond(v1alpha1.GitRepoVerificationFailedCondition).SetStatusBool(&gitrepo.Status, false)
+		condition.Cond(v1alpha1.GitRepoVerificationFailedCondition).Message(&gitrepo.Status, "")
+	case errors.Is(err, git.ErrVerificationFailed):
+		condition.Cond(v1alpha1.GitRepoVerificationFailedCondition).SetStatusBool(&gitrepo.Status, true)
+		condition.Cond(v1alpha1.GitRepoVerificationFailedCondition).Message(&gitrepo.Status, err.Error())
+	}
+}
+
 // updateErrorStatus sets the condition in the status and tries to update the resource
 func updateErrorStatus(ctx context.Context, c client.Client, req types.NamespacedName, status v1alpha1.GitRepoStatus, orgErr error) error {
 	reconciler.SetCondition(v1alpha1.GitRepoAcceptedCondition, &status, orgErr)
@@ -24,6 +24,7 @@ import (
 	"go.uber.org/mock/gomock"
 
 	fleetevent "github.com/rancher/fleet/pkg/event"
+	"github.com/rancher/fleet/pkg/git"
 	gitmocks "github.com/rancher/fleet/pkg/git/mocks"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
@@ -2866,3 +2867,147 @@ func getFleetControllerDeployment(tolerations []corev1.Toleration) *appsv1.Deplo
 		},
 	}
 }
+
+func TestSetVerificationCondition(t *testing.T) {
+	getCondition := func(gitrepo *fleetv1.GitRepo) (genericcondition.GenericCondition, bool) {
+		for _, c := range gitrepo.Status.Conditions {
+			if c.Type == fleetv1.GitRepoVerificationFailedCondition {
+				return c, true
+			}
+		}
+		return genericcondition.GenericCondition{}, false
+	}
+
+	gitrepo := &fleetv1.GitRepo{}
+	setVerificationCondition(gitrepo, fmt.Errorf("%w: commit is not signed", git.ErrVerificationFailed))
+	if _, found := getCondition(gitrepo); found {
+		t.Errorf("expecting no condition without verification")
+	}
+
+	gitrepo.Spec.Verification = &fleetv1.GitVerification{SecretName: "keys"}
+	setVerificationCondition(gitrepo, fmt.Errorf("%w: commit is not signed", git.ErrVerificationFailed))
+	cond, _ := getCondition(gitrepo)
+	if cond.Status != "True" || cond.Message != "signature verification failed: commit is not signed" {
+		t.Errorf("unexpected condition %+v", cond)
+	}
+
+	// other errors do not change the condition
+	setVerificationCondition(gitrepo, errors.New("connection refused"))
+	cond, _ = getCondition(gitrepo)
+	if cond.Status != "True" {
+		t.Errorf("unexpected condition %+v", cond)
+	}
+
+	setVerificationCondition(gitrepo, nil)
+	cond, _ = getCondition(gitrepo)
+	if cond.Status != "False" || cond.Message != "" {
+		t.Errorf("unexpected condition %+v", cond)
+	}
+}
+
+func TestGitClonerVerification(t *testing.T) {
+	gitrepo := &fleetv1.GitRepo{
+		ObjectMeta: metav1.ObjectMeta{Name: "gitrepo", Namespace: "default"},
+		Spec: fleetv1.GitRepoSpec{
+			Repo:         "https://github.com/rancher/fleet-examples",
+			Verification: &fleetv1.GitVerification{SecretName: "keys"},
+		},
+	}
+
+	tests := map[string]struct {
+		data         map[string][]byte
+		expectedArgs []string
+		expectedErr  error
+	}{
+		"secret without keys": {
+			data:        map[string][]byte{git.VerificationGPGKey: {}},
+			expectedErr: git.ErrVerificationFailed,
+		},
+		"secret with allowed signers": {
+			data: map[string][]byte{
+				git.VerificationGPGKey:            {},
+				git.VerificationAllowedSignersKey: []byte("fleet@example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIFlp7TLA9lpiHY8qmeP2ePNhpsiaZUlEtjzuImA2K5Z3\n"),
+			},
+			expectedArgs: []string{"--verify-allowed-signers-file", "/gitjob/verification/" + git.VerificationAllowedSignersKey},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := GitJobReconciler{
+				Client: fake.NewFakeClient(&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "keys", Namespace: "default"},
+					Data:       test.data,
+				}),
+				Image:      "test",
+				KnownHosts: mockKnownHostsGetter{},
+			}
+
+			cont, err := r.newGitCloner(context.TODO(), gitrepo, "")
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("expecting error %v, got %v", test.expectedErr, err)
+			}
+			if slices.Contains(cont.Args, "--verify-gpg-keys-file") {
+				t.Errorf("expecting no GPG keys file for an empty key, got %v", cont.Args)
+			}
+			if test.expectedArgs != nil && !strings.Contains(strings.Join(cont.Args, " "), strings.Join(test.expectedArgs, " ")) {
+				t.Errorf("expecting args %v, got %v", test.expectedArgs, cont.Args)
+			}
+		})
+	}
+}
+
+func TestSetStatusFromGitjob_VerificationFailed(t *testing.T) {
+	tests := map[string]struct {
+		exitCode int32
+		expected bool
+	}{
+		"verification failed": {
+			exitCode: git.VerificationFailedExitCode,
+			expected: true,
+		},
+		"other error mentioning verification": {
+			exitCode: 1,
+			expected: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			job := &batchv1.Job{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "batch/v1", Kind: "Job"},
+				ObjectMeta: metav1.ObjectMeta{Name: "gitjob", Namespace: "default"},
+				Status: batchv1.JobStatus{
+					Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}},
+				},
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "gitjob-pod",
+					Namespace: "default",
+					Labels:    map[string]string{"job-name": "gitjob"},
+				},
+				Status: corev1.PodStatus{
+					InitContainerStatuses: []corev1.ContainerStatus{{
+						Name: "gitcloner-initializer",
+						State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: test.exitCode,
+							Message:  "failed to verify refs/heads/master: signature verification failed: commit is not signed",
+						}},
+					}},
+				},
+			}
+			gitrepo := &fleetv1.GitRepo{
+				Spec: fleetv1.GitRepoSpec{Verification: &fleetv1.GitVerification{SecretName: "keys"}},
+			}
+
+			if err := setStatusFromGitjob(context.TODO(), fake.NewFakeClient(pod), gitrepo, job); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			cond, _ := getCondition(gitrepo, fleetv1.GitRepoVerificationFailedCondition)
+			if (cond.Status == "True") != test.expected {
+				t.Errorf("expecting verification failed %t, got condition %+v", test.expected, cond)
+			}
+		})
+	}
+}
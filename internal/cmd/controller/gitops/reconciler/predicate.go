@@ -46,3 +46,19 @@ func webhookCommitChangedPredicate() predicate.Predicate {
 		},
 	}
 }
+
+func webhookPullRequestChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldGitRepo, ok := e.ObjectOld.(*v1alpha1.GitRepo)
+			if !ok {
+				return true
+			}
+			newGitRepo, ok := e.ObjectNew.(*v1alpha1.GitRepo)
+			if !ok {
+				return true
+			}
+			return oldGitRepo.Status.WebhookPullRequest != newGitRepo.Status.WebhookPullRequest
+		},
+	}
+}
@@ -0,0 +1,257 @@
+package reconciler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/rancher/fleet/internal/forge"
+	"github.com/rancher/fleet/internal/names"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	fleetevent "github.com/rancher/fleet/pkg/event"
+	"github.com/rancher/fleet/pkg/sharding"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	defaultPullRequestPollingInterval = time.Minute
+	defaultPreviewTargetNamespace     = "{{ .Name }}-pr-{{ .Number }}"
+)
+
+// PullRequestReconciler creates a preview GitRepo for each open pull request
+// of GitRepos, which have spec.pullRequests set. Preview GitRepos are owned
+// by their GitRepo and deleted once the pull request is closed.
+type PullRequestReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Workers  int
+	ShardID  string
+	Recorder record.EventRecorder
+	// NewForge creates the API client used to list pull requests.
+	NewForge NewForgeFunc
+}
+
+func (r *PullRequestReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&fleet.GitRepo{},
+			builder.WithPredicates(
+				predicate.Or(
+					predicate.GenerationChangedPredicate{},
+					webhookPullRequestChangedPredicate(),
+				),
+			),
+		).
+		// recreate previews which were modified or deleted by users
+		Owns(&fleet.GitRepo{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WithEventFilter(sharding.FilterByShardID(r.ShardID)).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.Workers}).
+		Named("GitRepoPullRequests").
+		Complete(r)
+}
+
+// Reconcile lists the open pull requests of the GitRepo's repository and
+// creates, updates or deletes preview GitRepos accordingly.
+func (r *PullRequestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithName("gitops-pullrequests")
+	ctx = log.IntoContext(ctx, logger)
+
+	gitrepo := &fleet.GitRepo{}
+	if err := r.Get(ctx, req.NamespacedName, gitrepo); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// previews are garbage collected by their owner reference
+	if !gitrepo.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	previews := &fleet.GitRepoList{}
+	if err := r.List(ctx, previews, client.InNamespace(gitrepo.Namespace), client.MatchingLabels{
+		fleet.PreviewOfLabel: gitrepo.Name,
+	}); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	opts := gitrepo.Spec.PullRequests
+	if opts == nil {
+		return ctrl.Result{}, r.deletePreviews(ctx, previews.Items, nil)
+	}
+	if len(opts.Labels) == 0 && len(opts.Authors) == 0 {
+		r.Recorder.Event(gitrepo, fleetevent.Warning, "PreviewsNotEnabled", "pull request previews need labels or authors")
+		return ctrl.Result{}, r.deletePreviews(ctx, previews.Items, nil)
+	}
+
+	prs, err := r.pullRequests(ctx, gitrepo)
+	if err != nil {
+		r.Recorder.Event(gitrepo, fleetevent.Warning, "FailedToListPullRequests", err.Error())
+		return ctrl.Result{}, err
+	}
+
+	open := map[string]bool{}
+	for _, pr := range prs {
+		preview, err := previewGitRepo(gitrepo, pr)
+		if err != nil {
+			r.Recorder.Event(gitrepo, fleetevent.Warning, "FailedToCreatePreview", err.Error())
+			logger.Info("Skipping preview for pull request", "pullRequest", pr.Number, "error", err.Error())
+			continue
+		}
+		open[preview.Name] = true
+
+		if err := r.createOrUpdatePreview(ctx, gitrepo, preview); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := r.deletePreviews(ctx, previews.Items, open); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	interval := defaultPullRequestPollingInterval
+	if opts.PollingInterval != nil && opts.PollingInterval.Duration > 0 {
+		interval = opts.PollingInterval.Duration
+	}
+
+	return ctrl.Result{RequeueAfter: interval}, nil
+}
+
+// pullRequests returns the open pull requests targeting the GitRepo's branch,
+// which are enabled for previews by their labels or their author. Pull
+// requests from forks are skipped, unless they are allowed.
+func (r *PullRequestReconciler) pullRequests(ctx context.Context, gitrepo *fleet.GitRepo) ([]forge.PullRequest, error) {
+	opts := gitrepo.Spec.PullRequests
+
+	creds, err := forge.LoadCredentials(ctx, r.Client, gitrepo.Namespace, opts.SecretName, gitrepo.Spec.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	fc, err := r.NewForge(opts.Provider, opts.APIURL, gitrepo.Spec.Repo, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := fc.PullRequests(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+
+	var result []forge.PullRequest
+	for _, pr := range all {
+		if gitrepo.Spec.Branch != "" && pr.BaseBranch != gitrepo.Spec.Branch {
+			continue
+		}
+		if pr.FromFork() && !opts.AllowForks {
+			continue
+		}
+		if !previewEnabled(opts, pr) {
+			continue
+		}
+		result = append(result, pr)
+	}
+
+	return result, nil
+}
+
+// previewEnabled returns true if the pull request opted in to previews, by
+// having all the configured labels, or by being opened by one of the
+// configured authors.
+func previewEnabled(opts *fleet.PullRequestPreviews, pr forge.PullRequest) bool {
+	if len(opts.Labels) > 0 && pr.HasLabels(opts.Labels) {
+		return true
+	}
+	return pr.Author != "" && slices.Contains(opts.Authors, pr.Author)
+}
+
+func (r *PullRequestReconciler) createOrUpdatePreview(ctx context.Context, gitrepo, preview *fleet.GitRepo) error {
+	obj := &fleet.GitRepo{ObjectMeta: metav1.ObjectMeta{Namespace: preview.Namespace, Name: preview.Name}}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, obj, func() error {
+		obj.Labels = preview.Labels
+		obj.Spec = preview.Spec
+		return controllerutil.SetControllerReference(gitrepo, obj, r.Scheme)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create or update preview gitrepo %s: %w", preview.Name, err)
+	}
+	if op == controllerutil.OperationResultCreated {
+		r.Recorder.Event(gitrepo, fleetevent.Normal, "CreatedPreview", preview.Name)
+	}
+
+	return nil
+}
+
+// deletePreviews deletes all previews, which are not in keep.
+func (r *PullRequestReconciler) deletePreviews(ctx context.Context, previews []fleet.GitRepo, keep map[string]bool) error {
+	for _, preview := range previews {
+		if keep[preview.Name] {
+			continue
+		}
+		if err := r.Delete(ctx, &preview); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete preview gitrepo %s: %w", preview.Name, err)
+		}
+		log.FromContext(ctx).Info("Deleted preview gitrepo", "preview", preview.Name)
+	}
+
+	return nil
+}
+
+// previewGitRepo returns the preview GitRepo for the pull request. It deploys
+// the pull request's head commit to the templated target namespace
+// (pure function).
+func previewGitRepo(gitrepo *fleet.GitRepo, pr forge.PullRequest) (*fleet.GitRepo, error) {
+	tmpl := defaultPreviewTargetNamespace
+	if gitrepo.Spec.PullRequests.TargetNamespace != "" {
+		tmpl = gitrepo.Spec.PullRequests.TargetNamespace
+	}
+	t, err := template.New("targetNamespace").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target namespace template: %w", err)
+	}
+	var ns bytes.Buffer
+	if err := t.Execute(&ns, map[string]interface{}{
+		"Name":   gitrepo.Name,
+		"Number": pr.Number,
+		"Branch": pr.HeadBranch,
+	}); err != nil {
+		return nil, fmt.Errorf("invalid target namespace template: %w", err)
+	}
+	if errs := validation.IsDNS1123Label(ns.String()); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid target namespace %q for pull request %d: %v", ns.String(), pr.Number, errs)
+	}
+
+	labels := map[string]string{}
+	for k, v := range gitrepo.Labels {
+		labels[k] = v
+	}
+	labels[fleet.PreviewOfLabel] = gitrepo.Name
+	labels[fleet.PullRequestLabel] = strconv.Itoa(pr.Number)
+
+	spec := *gitrepo.Spec.DeepCopy()
+	spec.Branch = ""
+	spec.Revision = pr.HeadSHA
+	spec.TargetNamespace = ns.String()
+	spec.PullRequests = nil
+
+	return &fleet.GitRepo{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: gitrepo.Namespace,
+			Name:      names.SafeConcatName(gitrepo.Name, "pr", strconv.Itoa(pr.Number)),
+			Labels:    labels,
+		},
+		Spec: spec,
+	}, nil
+}
@@ -0,0 +1,194 @@
+package reconciler
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/rancher/fleet/internal/forge"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+)
+
+// fakeForge returns prs and records the reported commit statuses by commit.
+type fakeForge struct {
+	prs      []forge.PullRequest
+	statuses map[string]forge.CommitStatus
+}
+
+func (f *fakeForge) PullRequests(context.Context) ([]forge.PullRequest, error) {
+	return f.prs, nil
+}
+
+func (f *fakeForge) SetCommitStatus(_ context.Context, sha string, status forge.CommitStatus) error {
+	if f.statuses == nil {
+		f.statuses = map[string]forge.CommitStatus{}
+	}
+	f.statuses[sha] = status
+	return nil
+}
+
+func (f *fakeForge) CreatePullRequest(context.Context, forge.PullRequestOptions) (forge.PullRequest, error) {
+	return forge.PullRequest{}, forge.ErrNotSupported
+}
+
+func (f *fakeForge) UpdatePullRequest(context.Context, int, forge.PullRequestOptions) (forge.PullRequest, error) {
+	return forge.PullRequest{}, forge.ErrNotSupported
+}
+
+func pullRequestGitRepo(opts *fleet.PullRequestPreviews) *fleet.GitRepo {
+	return &fleet.GitRepo{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "fleet-local",
+			Name:      "apps",
+			Labels:    map[string]string{"team": "a"},
+		},
+		Spec: fleet.GitRepoSpec{
+			Repo:         "https://github.com/rancher/fleet-examples",
+			Branch:       "main",
+			Paths:        []string{"simple"},
+			PullRequests: opts,
+		},
+	}
+}
+
+func TestPreviewGitRepo(t *testing.T) {
+	pr := forge.PullRequest{Number: 12, HeadBranch: "Feature_X", HeadSHA: "abc123", BaseBranch: "main"}
+
+	preview, err := previewGitRepo(pullRequestGitRepo(&fleet.PullRequestPreviews{Provider: forge.GitHub}), pr)
+	require.NoError(t, err)
+	assert.Equal(t, "apps-pr-12", preview.Name)
+	assert.Equal(t, "fleet-local", preview.Namespace)
+	assert.Equal(t, map[string]string{
+		"team":                 "a",
+		fleet.PreviewOfLabel:   "apps",
+		fleet.PullRequestLabel: "12",
+	}, preview.Labels)
+	assert.Equal(t, "", preview.Spec.Branch)
+	assert.Equal(t, "abc123", preview.Spec.Revision)
+	assert.Equal(t, "apps-pr-12", preview.Spec.TargetNamespace)
+	assert.Equal(t, []string{"simple"}, preview.Spec.Paths)
+	assert.Nil(t, preview.Spec.PullRequests)
+
+	preview, err = previewGitRepo(pullRequestGitRepo(&fleet.PullRequestPreviews{
+		TargetNamespace: `preview-{{ .Branch | lower }}`,
+	}), pr)
+	require.Error(t, err, "template functions are not available")
+	assert.Nil(t, preview)
+
+	_, err = previewGitRepo(pullRequestGitRepo(&fleet.PullRequestPreviews{
+		TargetNamespace: `preview-{{ .Branch }}`,
+	}), pr)
+	assert.ErrorContains(t, err, `invalid target namespace "preview-Feature_X"`)
+}
+
+func TestPullRequestReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, fleet.AddToScheme(scheme))
+
+	gitrepo := pullRequestGitRepo(&fleet.PullRequestPreviews{Provider: forge.GitHub, Labels: []string{"preview"}, Authors: []string{"maintainer"}})
+	stale := &fleet.GitRepo{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "fleet-local",
+		Name:      "apps-pr-1",
+		Labels:    map[string]string{fleet.PreviewOfLabel: "apps", fleet.PullRequestLabel: "1"},
+	}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(gitrepo, stale).Build()
+
+	pr := func(number int, base, author, headRepo string, labels ...string) forge.PullRequest {
+		return forge.PullRequest{
+			Number:     number,
+			HeadSHA:    "sha" + strconv.Itoa(number),
+			BaseBranch: base,
+			Labels:     labels,
+			HeadRepo:   headRepo,
+			BaseRepo:   "example/apps",
+			Author:     author,
+		}
+	}
+	fc := &fakeForge{prs: []forge.PullRequest{
+		pr(2, "main", "dev", "example/apps", "preview"),
+		pr(3, "main", "dev", "example/apps"),
+		pr(4, "release", "dev", "example/apps", "preview"),
+		pr(5, "main", "maintainer", "example/apps"),
+		// pull requests from forks are not previewed, even with labels
+		pr(6, "main", "maintainer", "someone/apps", "preview"),
+		pr(7, "main", "dev", "", "preview"),
+	}}
+	r := &PullRequestReconciler{
+		Client:   c,
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(10),
+		NewForge: func(provider, apiURL, repoURL string, creds forge.Credentials) (forge.Client, error) {
+			return fc, nil
+		},
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "fleet-local", Name: "apps"}}
+	result, err := r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+	assert.Equal(t, defaultPullRequestPollingInterval, result.RequeueAfter)
+
+	previews := &fleet.GitRepoList{}
+	require.NoError(t, c.List(context.TODO(), previews, client.MatchingLabels{fleet.PreviewOfLabel: "apps"}))
+	require.Len(t, previews.Items, 2)
+	preview := previews.Items[0]
+	assert.Equal(t, "apps-pr-2", preview.Name)
+	assert.Equal(t, "sha2", preview.Spec.Revision)
+	require.Len(t, preview.OwnerReferences, 1)
+	assert.Equal(t, "apps", preview.OwnerReferences[0].Name)
+	assert.Equal(t, "apps-pr-5", previews.Items[1].Name)
+
+	// forks can be allowed explicitly
+	require.NoError(t, c.Get(context.TODO(), req.NamespacedName, gitrepo))
+	gitrepo.Spec.PullRequests.AllowForks = true
+	require.NoError(t, c.Update(context.TODO(), gitrepo))
+
+	_, err = r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+	require.NoError(t, c.List(context.TODO(), previews, client.MatchingLabels{fleet.PreviewOfLabel: "apps"}))
+	var names []string
+	for _, p := range previews.Items {
+		names = append(names, p.Name)
+	}
+	assert.ElementsMatch(t, []string{"apps-pr-2", "apps-pr-5", "apps-pr-6", "apps-pr-7"}, names)
+
+	// previews need labels or authors to opt in
+	require.NoError(t, c.Get(context.TODO(), req.NamespacedName, gitrepo))
+	gitrepo.Spec.PullRequests.Labels = nil
+	gitrepo.Spec.PullRequests.Authors = nil
+	require.NoError(t, c.Update(context.TODO(), gitrepo))
+
+	_, err = r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+	require.NoError(t, c.List(context.TODO(), previews, client.MatchingLabels{fleet.PreviewOfLabel: "apps"}))
+	assert.Empty(t, previews.Items)
+
+	// disabling previews deletes all of them
+	require.NoError(t, c.Get(context.TODO(), req.NamespacedName, gitrepo))
+	gitrepo.Spec.PullRequests = &fleet.PullRequestPreviews{Provider: forge.GitHub, Labels: []string{"preview"}}
+	require.NoError(t, c.Update(context.TODO(), gitrepo))
+	_, err = r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+	require.NoError(t, c.List(context.TODO(), previews, client.MatchingLabels{fleet.PreviewOfLabel: "apps"}))
+	require.Len(t, previews.Items, 1)
+
+	require.NoError(t, c.Get(context.TODO(), req.NamespacedName, gitrepo))
+	gitrepo.Spec.PullRequests = nil
+	require.NoError(t, c.Update(context.TODO(), gitrepo))
+
+	result, err = r.Reconcile(context.TODO(), req)
+	require.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter)
+	require.NoError(t, c.List(context.TODO(), previews, client.MatchingLabels{fleet.PreviewOfLabel: "apps"}))
+	assert.Empty(t, previews.Items)
+}
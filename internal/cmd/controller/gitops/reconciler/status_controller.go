@@ -11,12 +11,14 @@ import (
 	"github.com/rancher/fleet/internal/resourcestatus"
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
 	"github.com/rancher/fleet/pkg/durations"
+	fleetevent "github.com/rancher/fleet/pkg/event"
 	"github.com/rancher/fleet/pkg/sharding"
 	"github.com/rancher/wrangler/v3/pkg/genericcondition"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -32,9 +34,12 @@ const enqueueDelay = 3 * time.Second
 
 type StatusReconciler struct {
 	client.Client
-	Scheme  *runtime.Scheme
-	Workers int
-	ShardID string
+	Scheme   *runtime.Scheme
+	Workers  int
+	ShardID  string
+	Recorder record.EventRecorder
+	// NewForge creates the API client used to report commit statuses.
+	NewForge NewForgeFunc
 }
 
 func (r *StatusReconciler) SetupWithManager(mgr ctrl.Manager) error {
@@ -143,12 +148,21 @@ func (r *StatusReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, err
 	}
 
+	result := ctrl.Result{}
+	if err := r.reportCommitStatus(ctx, gitrepo, bdList); err != nil {
+		// the status is reported again on the next reconcile, as it is
+		// not stored in status.commitStatus
+		logger.Error(err, "Failed to report commit status", "commit", gitrepo.Status.Commit)
+		r.Recorder.Event(gitrepo, fleetevent.Warning, "FailedToReportCommitStatus", err.Error())
+		result.RequeueAfter = durations.CommitStatusRetry
+	}
+
 	if err := r.updateStatus(ctx, orig, gitrepo); err != nil {
 		logger.Error(err, "Reconcile failed update to git repo status", "status", gitrepo.Status)
 		return ctrl.Result{RequeueAfter: durations.GitRepoStatusDelay}, nil
 	}
 
-	return ctrl.Result{}, nil
+	return result, nil
 }
 
 func (r *StatusReconciler) updateStatus(ctx context.Context, orig *fleet.GitRepo, obj *fleet.GitRepo) error {
@@ -33,6 +33,7 @@ import (
 	"github.com/rancher/fleet/internal/bundlereader"
 	fleetutil "github.com/rancher/fleet/internal/cmd/controller/errorutil"
 	"github.com/rancher/fleet/internal/cmd/controller/finalize"
+	"github.com/rancher/fleet/internal/cmd/controller/target"
 	"github.com/rancher/fleet/internal/metrics"
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
 	"github.com/rancher/fleet/pkg/durations"
@@ -186,7 +187,7 @@ func (r *HelmOpReconciler) createUpdateBundle(ctx context.Context, helmop *fleet
 	updated := bundle.DeepCopy()
 	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, bundle, func() error {
 		bundle.Spec = updated.Spec
-		bundle.Annotations = updated.Annotations
+		bundle.Annotations = target.KeepApprovals(bundle, updated.Annotations)
 		bundle.Labels = updated.Labels
 		return nil
 	})
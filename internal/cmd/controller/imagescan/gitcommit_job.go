@@ -16,6 +16,7 @@ import (
 	"time"
 
 	gogit "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport"
@@ -26,6 +27,7 @@ import (
 	"golang.org/x/sync/semaphore"
 
 	"github.com/rancher/fleet/internal/cmd/controller/imagescan/update"
+	"github.com/rancher/fleet/internal/forge"
 	fleetgithub "github.com/rancher/fleet/internal/github"
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
 	"github.com/rancher/fleet/pkg/durations"
@@ -207,14 +209,25 @@ func (j *GitCommitJob) cloneAndReplace(ctx context.Context) {
 		}
 	}
 
-	commit, err := commitAllAndPush(context.Background(), repo, auth, *gitrepo.Spec.ImageScanCommit)
+	commitSpec := *gitrepo.Spec.ImageScanCommit
+	commit, pushed, err := commitAllAndPush(context.Background(), repo, auth, commitSpec)
 	if err != nil {
 		err = j.updateErrorStatus(ctx, gitrepo, err)
 		logger.V(1).Info("Cannot commit and push to repo", "error", err)
 		return
 	}
-	if commit != "" {
-		logger.Info("Created commit in repo", "repo", gitrepo.Spec.Repo, "commit", commit)
+	if pushed {
+		logger.Info("Created commit in repo", "repo", gitrepo.Spec.Repo, "commit", commit, "branch", commitSpec.PushBranch)
+	}
+
+	var pullRequestURL string
+	if commit != "" && commitSpec.PushBranch != "" && commitSpec.PullRequest != nil {
+		pullRequestURL, err = j.pullRequest(ctx, repo, gitrepo, commitSpec)
+		if err != nil {
+			err = j.updateErrorStatus(ctx, gitrepo, err)
+			logger.V(1).Info("Cannot open pull request", "error", err)
+			return
+		}
 	}
 	interval := gitrepo.Spec.ImageSyncInterval
 	if interval == nil || interval.Seconds() == 0.0 {
@@ -224,6 +237,9 @@ func (j *GitCommitJob) cloneAndReplace(ctx context.Context) {
 
 	// update gitrepo status
 	condition.Cond(fleet.ImageScanSyncCondition).SetError(&gitrepo.Status, "", nil)
+	if pullRequestURL != "" {
+		condition.Cond(fleet.ImageScanSyncCondition).Message(&gitrepo.Status, "Pull request: "+pullRequestURL)
+	}
 	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		t := &fleet.GitRepo{}
 		err := j.client.Get(ctx, nsn, t)
@@ -339,19 +355,76 @@ func setupKnownHosts(gitrepo *fleet.GitRepo, data []byte) error {
 	return nil
 }
 
-func commitAllAndPush(ctx context.Context, repo *gogit.Repository, auth transport.AuthMethod, commit fleet.CommitSpec) (string, error) {
-	working, err := repo.Worktree()
+// pullRequest opens or updates the pull request from the push branch into the
+// cloned branch and returns its URL.
+func (j *GitCommitJob) pullRequest(ctx context.Context, repo *gogit.Repository, gitrepo *fleet.GitRepo, commit fleet.CommitSpec) (string, error) {
+	opts := commit.PullRequest
+	head, err := repo.Head()
 	if err != nil {
 		return "", err
 	}
 
-	status, err := working.Status()
+	secretName := opts.SecretName
+	if secretName == "" {
+		secretName = gitrepo.Spec.ClientSecretName
+	}
+	creds, err := forge.LoadCredentials(ctx, j.client, gitrepo.Namespace, secretName, gitrepo.Spec.Repo)
 	if err != nil {
 		return "", err
-	} else if status.IsClean() {
-		return "", nil
+	}
+	fc, err := forge.New(opts.Provider, opts.APIURL, gitrepo.Spec.Repo, creds, forge.HTTPClient)
+	if err != nil {
+		return "", err
+	}
+
+	title := opts.Title
+	if title == "" {
+		if title, err = commitMessage(commit); err != nil {
+			return "", err
+		}
 	}
 
+	pr, err := ensurePullRequest(ctx, fc, forge.PullRequestOptions{
+		Title:      title,
+		Body:       defaultMessageTemplate,
+		HeadBranch: commit.PushBranch,
+		BaseBranch: head.Name().Short(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return pr.URL, nil
+}
+
+// ensurePullRequest updates the open pull request from the head into the
+// base branch, if there is one, and creates it otherwise.
+func ensurePullRequest(ctx context.Context, fc forge.Client, opts forge.PullRequestOptions) (forge.PullRequest, error) {
+	prs, err := fc.PullRequests(ctx)
+	if err != nil {
+		return forge.PullRequest{}, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+	for _, pr := range prs {
+		if pr.HeadBranch != opts.HeadBranch || pr.BaseBranch != opts.BaseBranch {
+			continue
+		}
+		if pr.Title == opts.Title {
+			return pr, nil
+		}
+		updated, err := fc.UpdatePullRequest(ctx, pr.Number, opts)
+		if err != nil {
+			return forge.PullRequest{}, fmt.Errorf("failed to update pull request %d: %w", pr.Number, err)
+		}
+		return updated, nil
+	}
+
+	pr, err := fc.CreatePullRequest(ctx, opts)
+	if err != nil {
+		return forge.PullRequest{}, fmt.Errorf("failed to create pull request: %w", err)
+	}
+	return pr, nil
+}
+
+func commitMessage(commit fleet.CommitSpec) (string, error) {
 	msgTmpl := commit.MessageTemplate
 	if msgTmpl == "" {
 		msgTmpl = defaultMessageTemplate
@@ -364,9 +437,33 @@ func commitAllAndPush(ctx context.Context, repo *gogit.Repository, auth transpor
 	if err := tmpl.Execute(buf, "no data! yet"); err != nil {
 		return "", err
 	}
+	return buf.String(), nil
+}
+
+// commitAllAndPush commits all changes and pushes them to the cloned branch,
+// or to the push branch. It returns the commit, which is empty if there were
+// no changes. If the push branch already contains the same changes, the
+// commit is not pushed.
+func commitAllAndPush(ctx context.Context, repo *gogit.Repository, auth transport.AuthMethod, commit fleet.CommitSpec) (string, bool, error) {
+	working, err := repo.Worktree()
+	if err != nil {
+		return "", false, err
+	}
+
+	status, err := working.Status()
+	if err != nil {
+		return "", false, err
+	} else if status.IsClean() {
+		return "", false, nil
+	}
+
+	msg, err := commitMessage(commit)
+	if err != nil {
+		return "", false, err
+	}
 
 	var rev plumbing.Hash
-	if rev, err = working.Commit(buf.String(), &gogit.CommitOptions{
+	if rev, err = working.Commit(msg, &gogit.CommitOptions{
 		All: true,
 		Author: &object.Signature{
 			Name:  commit.AuthorName,
@@ -374,10 +471,62 @@ func commitAllAndPush(ctx context.Context, repo *gogit.Repository, auth transpor
 			When:  time.Now(),
 		},
 	}); err != nil {
-		return "", err
+		return "", false, err
+	}
+
+	if commit.PushBranch == "" {
+		return rev.String(), true, repo.PushContext(ctx, &gogit.PushOptions{
+			Auth: auth,
+		})
 	}
 
-	return rev.String(), repo.PushContext(ctx, &gogit.PushOptions{
-		Auth: auth,
+	upToDate, err := pushBranchUpToDate(ctx, repo, auth, commit.PushBranch, rev)
+	if err != nil || upToDate {
+		return rev.String(), false, err
+	}
+
+	// the push branch is reset to the cloned branch with the changes, so
+	// it follows the cloned branch
+	head, err := repo.Head()
+	if err != nil {
+		return "", false, err
+	}
+	refSpec := gitconfig.RefSpec(fmt.Sprintf("+%s:%s", head.Name(), plumbing.NewBranchReferenceName(commit.PushBranch)))
+	return rev.String(), true, repo.PushContext(ctx, &gogit.PushOptions{
+		Auth:     auth,
+		RefSpecs: []gitconfig.RefSpec{refSpec},
 	})
 }
+
+// pushBranchUpToDate returns true, if the push branch exists and has the same
+// content as the commit.
+func pushBranchUpToDate(ctx context.Context, repo *gogit.Repository, auth transport.AuthMethod, branch string, rev plumbing.Hash) (bool, error) {
+	remoteRef := plumbing.NewRemoteReferenceName("origin", branch)
+	err := repo.FetchContext(ctx, &gogit.FetchOptions{
+		RemoteName: "origin",
+		RefSpecs:   []gitconfig.RefSpec{gitconfig.RefSpec(fmt.Sprintf("+%s:%s", plumbing.NewBranchReferenceName(branch), remoteRef))},
+		Auth:       auth,
+		Depth:      1,
+		Tags:       gogit.NoTags,
+	})
+	if errors.Is(err, gogit.NoMatchingRefSpecError{}) {
+		return false, nil
+	}
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		return false, err
+	}
+
+	ref, err := repo.Reference(remoteRef, true)
+	if err != nil {
+		return false, err
+	}
+	remoteCommit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return false, err
+	}
+	localCommit, err := repo.CommitObject(rev)
+	if err != nil {
+		return false, err
+	}
+	return remoteCommit.TreeHash == localCommit.TreeHash, nil
+}
@@ -0,0 +1,239 @@
+package imagescan
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/rancher/fleet/internal/forge"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+)
+
+// githubStandIn serves the pull request API of GitHub for prs and records
+// the created and updated pull requests.
+type githubStandIn struct {
+	prs     []map[string]interface{}
+	created int
+	updated int
+}
+
+func (g *githubStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]string
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/rancher/fleet/pulls":
+		_ = json.NewEncoder(w).Encode(g.prs)
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/rancher/fleet/pulls":
+		g.created++
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(pullRequestJSON(8, body["title"], body["head"], body["base"]))
+	case r.Method == http.MethodPatch && r.URL.Path == "/repos/rancher/fleet/pulls/7":
+		g.updated++
+		_ = json.NewEncoder(w).Encode(pullRequestJSON(7, body["title"], "image-updates", "main"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func pullRequestJSON(number int, title, head, base string) map[string]interface{} {
+	return map[string]interface{}{
+		"number":   number,
+		"title":    title,
+		"html_url": "https://github.com/rancher/fleet/pull/" + strconv.Itoa(number),
+		"head":     map[string]string{"ref": head},
+		"base":     map[string]string{"ref": base},
+	}
+}
+
+func TestEnsurePullRequest(t *testing.T) {
+	opts := forge.PullRequestOptions{
+		Title:      "Update images",
+		HeadBranch: "image-updates",
+		BaseBranch: "main",
+	}
+
+	tests := map[string]struct {
+		prs         []map[string]interface{}
+		wantURL     string
+		wantCreated int
+		wantUpdated int
+	}{
+		"creates pull request": {
+			prs: []map[string]interface{}{
+				pullRequestJSON(7, "Other", "feature", "main"),
+				pullRequestJSON(6, "Update images", "image-updates", "release"),
+			},
+			wantURL:     "https://github.com/rancher/fleet/pull/8",
+			wantCreated: 1,
+		},
+		"updates existing pull request": {
+			prs:         []map[string]interface{}{pullRequestJSON(7, "Old title", "image-updates", "main")},
+			wantURL:     "https://github.com/rancher/fleet/pull/7",
+			wantUpdated: 1,
+		},
+		"keeps unchanged pull request": {
+			prs:     []map[string]interface{}{pullRequestJSON(7, "Update images", "image-updates", "main")},
+			wantURL: "https://github.com/rancher/fleet/pull/7",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			standIn := &githubStandIn{prs: tt.prs}
+			srv := httptest.NewServer(standIn)
+			defer srv.Close()
+
+			fc, err := forge.New(forge.GitHub, srv.URL, "https://github.com/rancher/fleet", forge.Credentials{Token: "secret"}, srv.Client())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pr, err := ensurePullRequest(context.TODO(), fc, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pr.URL != tt.wantURL {
+				t.Errorf("unexpected pull request URL %q, want %q", pr.URL, tt.wantURL)
+			}
+			if standIn.created != tt.wantCreated || standIn.updated != tt.wantUpdated {
+				t.Errorf("created %d and updated %d pull requests, want %d and %d",
+					standIn.created, standIn.updated, tt.wantCreated, tt.wantUpdated)
+			}
+		})
+	}
+}
+
+func TestCommitAllAndPushToPushBranch(t *testing.T) {
+	ctx := context.TODO()
+	remote := initRemote(t)
+	commit := fleet.CommitSpec{
+		AuthorName:  "fleet",
+		AuthorEmail: "fleet@example.com",
+		PushBranch:  "image-updates",
+	}
+
+	update := func(content string) (string, bool) {
+		t.Helper()
+		dir := t.TempDir()
+		repo, err := gogit.PlainClone(dir, false, &gogit.CloneOptions{
+			URL:           remote,
+			ReferenceName: plumbing.NewBranchReferenceName("main"),
+			SingleBranch:  true,
+			Depth:         1,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "deployment.yaml"), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		rev, pushed, err := commitAllAndPush(ctx, repo, nil, commit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rev, pushed
+	}
+
+	rev, pushed := update("image: app:1.1.0\n")
+	if rev == "" || !pushed {
+		t.Fatalf("expected commit to be pushed, got %q, %v", rev, pushed)
+	}
+	assertBranch(t, remote, "main", "image: app:1.0.0\n")
+	assertBranch(t, remote, "image-updates", "image: app:1.1.0\n")
+
+	if rev, pushed := update("image: app:1.1.0\n"); rev == "" || pushed {
+		t.Errorf("expected commit not to be pushed again, got %q, %v", rev, pushed)
+	}
+
+	if _, pushed := update("image: app:1.2.0\n"); !pushed {
+		t.Error("expected new changes to be pushed")
+	}
+	assertBranch(t, remote, "image-updates", "image: app:1.2.0\n")
+
+	if rev, pushed := update("image: app:1.0.0\n"); rev != "" || pushed {
+		t.Errorf("expected no commit without changes, got %q, %v", rev, pushed)
+	}
+}
+
+// initRemote returns the path of a bare repository with a main branch.
+func initRemote(t *testing.T) string {
+	t.Helper()
+	remote := t.TempDir()
+	if _, err := gogit.PlainInit(remote, true); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "deployment.yaml"), []byte("image: app:1.0.0\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Add("deployment.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	rev, err := w.Commit("initial", &gogit.CommitOptions{
+		Author: &object.Signature{Name: "fleet", Email: "fleet@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("main"), rev)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{remote}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Push(&gogit.PushOptions{
+		RefSpecs: []gitconfig.RefSpec{"refs/heads/main:refs/heads/main"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return remote
+}
+
+func assertBranch(t *testing.T, remote, branch, want string) {
+	t.Helper()
+	repo, err := gogit.PlainOpen(remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := commit.File("deployment.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := file.Contents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("unexpected content on branch %s: %q, want %q", branch, got, want)
+	}
+}
@@ -8,6 +8,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -141,22 +146,51 @@ func (j *TagScanJob) updateImageTags(ctx context.Context) {
 
 	image.Status.LastScanTime = metav1.NewTime(time.Now())
 
-	latestTag, err := latestTag(image.Spec.Policy, tags)
+	candidates, err := sortTags(image.Spec.Policy, image.Spec.FilterTags, tags)
 	if err != nil {
 		err = j.updateErrorStatus(ctx, image, err)
-		logger.Error(err, "Failed get the digest", "latestImage", image.Status.LatestImage)
+		logger.Error(err, "Failed to select the latest tag", "policy", image.Spec.Policy)
 		return
 	}
 
-	image.Status.LatestTag = latestTag
-	image.Status.LatestImage = image.Status.CanonicalImageName + ":" + latestTag
-	digest, err := getDigest(image.Status.LatestImage, options...)
-	if err != nil {
-		err = j.updateErrorStatus(ctx, image, err)
-		logger.Error(err, "Failed get the digest", "latestImage", image.Status.LatestImage)
-		return
+	latest := candidates[0]
+	var verifiedDigest string
+	if image.Spec.Verify != nil {
+		latest, verifiedDigest, err = verifiedTag(ref.Context(), image.Spec.Verify, candidates, &image.Status, append(options, remote.WithContext(ctx))...)
+		if err != nil {
+			err = j.updateErrorStatus(ctx, image, err)
+			logger.Error(err, "Failed to verify image signatures")
+			return
+		}
+	}
+
+	previousImage := image.Status.LatestImage
+	image.Status.LatestTag = latest.tag
+	image.Status.LatestExtractedValue = ""
+	if image.Spec.FilterTags != nil {
+		image.Status.LatestExtractedValue = latest.value
+	}
+	image.Status.LatestImage = image.Status.CanonicalImageName + ":" + latest.tag
+
+	if verifiedDigest != "" && image.Spec.DigestReflectionPolicy != fleet.ReflectNever {
+		// the digest is not resolved again, as the tag may have been moved
+		// to an image without a valid signature since it was verified
+		image.Status.LatestDigest = verifiedDigest
+	} else if shouldResolveDigest(image.Spec.DigestReflectionPolicy, previousImage, image.Status) {
+		digest, err := getDigest(image.Status.LatestImage, options...)
+		if err != nil {
+			err = j.updateErrorStatus(ctx, image, err)
+			logger.Error(err, "Failed get the digest", "latestImage", image.Status.LatestImage)
+			return
+		}
+		if previousImage == image.Status.LatestImage && image.Status.LatestDigest != "" && image.Status.LatestDigest != digest {
+			logger.Info("Tag was moved to a new digest", "latestImage", image.Status.LatestImage,
+				"previousDigest", image.Status.LatestDigest, "digest", digest)
+		}
+		image.Status.LatestDigest = digest
+	} else if image.Spec.DigestReflectionPolicy == fleet.ReflectNever {
+		image.Status.LatestDigest = ""
 	}
-	image.Status.LatestDigest = digest
 
 	condition.Cond(fleet.ImageScanScanCondition).SetError(&image.Status, "", nil)
 	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
@@ -196,7 +230,7 @@ func (j *TagScanJob) updateErrorStatus(ctx context.Context, image *fleet.ImageSc
 }
 
 func shouldScan(image *fleet.ImageScan) bool {
-	if image.Status.LatestTag == "" {
+	if image.Status.LatestTag == "" || scanRequested(image) {
 		return true
 	}
 
@@ -212,6 +246,19 @@ func shouldScan(image *fleet.ImageScan) bool {
 	return true
 }
 
+// shouldResolveDigest returns true if the digest of the latest image needs to
+// be resolved, according to the digest reflection policy.
+func shouldResolveDigest(policy fleet.DigestReflectionPolicy, previousImage string, status fleet.ImageScanStatus) bool {
+	switch policy {
+	case fleet.ReflectNever:
+		return false
+	case fleet.ReflectIfNotPresent:
+		return status.LatestDigest == "" || previousImage != status.LatestImage
+	default:
+		return true
+	}
+}
+
 func getDigest(image string, options ...remote.Option) (string, error) {
 	nameRef, err := name.ParseReference(image)
 	if err != nil {
@@ -252,58 +299,162 @@ func authFromSecret(secret *corev1.Secret, registry string) (authn.Authenticator
 	}
 }
 
-func latestTag(policy fleet.ImagePolicyChoice, versions []string) (string, error) {
-	if len(versions) == 0 {
-		return "", errors.New("no tag found")
+// tagValue is a tag and the value extracted from it by the tag filter, which
+// is ordered by the policy.
+type tagValue struct {
+	tag   string
+	value string
+}
+
+// filterTags returns the tags matching the filter, with the values extracted
+// from them. Without a filter, all tags are returned as their own values.
+func filterTags(filter *fleet.TagFilter, tags []string) ([]tagValue, error) {
+	if filter == nil || filter.Pattern == "" {
+		result := make([]tagValue, 0, len(tags))
+		for _, tag := range tags {
+			result = append(result, tagValue{tag: tag, value: tag})
+		}
+		return result, nil
 	}
-	switch {
-	case policy.SemVer != nil:
-		return semverLatest(policy.SemVer.Range, versions)
-	case policy.Alphabetical != nil:
-		var des bool
-		if policy.Alphabetical.Order == "" {
-			des = true
-		} else {
-			des = strings.ToUpper(policy.Alphabetical.Order) == AlphabeticalOrderDesc
+
+	re, err := regexp.Compile(filter.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag filter pattern %q: %w", filter.Pattern, err)
+	}
+
+	var result []tagValue
+	for _, tag := range tags {
+		match := re.FindStringSubmatchIndex(tag)
+		if match == nil {
+			continue
 		}
-		var latest string
-		for _, version := range versions {
-			if latest == "" {
-				latest = version
-				continue
-			}
+		value := tag
+		if filter.Extract != "" {
+			value = string(re.ExpandString(nil, filter.Extract, tag, match))
+		}
+		result = append(result, tagValue{tag: tag, value: value})
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no tag matches pattern %q", filter.Pattern)
+	}
+	return result, nil
+}
 
-			if version >= latest && des {
-				latest = version
-			}
+// sortTags returns the tags, which are selectable by the policy, with the
+// latest tag first. It returns an error if there is no such tag.
+func sortTags(policy fleet.ImagePolicyChoice, filter *fleet.TagFilter, tags []string) ([]tagValue, error) {
+	if len(tags) == 0 {
+		return nil, errors.New("no tag found")
+	}
+	versions, err := filterTags(filter, tags)
+	if err != nil {
+		return nil, err
+	}
 
-			if version <= latest && !des {
-				latest = version
+	switch {
+	case policy.SemVer != nil:
+		return semverSort(policy.SemVer.Range, versions)
+	case policy.Alphabetical != nil:
+		des := isDesc(policy.Alphabetical.Order)
+		sort.SliceStable(versions, func(i, j int) bool {
+			if des {
+				return versions[i].value > versions[j].value
 			}
-		}
-		return latest, nil
+			return versions[i].value < versions[j].value
+		})
+		return versions, nil
+	case policy.Numerical != nil:
+		return numericalSort(isDesc(policy.Numerical.Order), versions)
 	default:
-		return semverLatest("*", versions)
+		return semverSort("*", versions)
+	}
+}
+
+// isDesc returns true for descending order, which is the default.
+func isDesc(order string) bool {
+	if order == "" {
+		return true
 	}
+	return strings.ToUpper(order) == AlphabeticalOrderDesc
 }
 
-func semverLatest(r string, versions []string) (string, error) {
+// semverSort returns the versions within the range r, the highest version
+// first.
+func semverSort(r string, versions []tagValue) ([]tagValue, error) {
 	constraints, err := semver.NewConstraint(r)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	var latestVersion *semver.Version
+
+	type semverTag struct {
+		tagValue
+		version *semver.Version
+	}
+	var matching []semverTag
 	for _, version := range versions {
-		if ver, err := semver.NewVersion(version); err == nil {
-			if latestVersion == nil || ver.GreaterThan(latestVersion) {
-				if constraints.Check(ver) {
-					latestVersion = ver
-				}
-			}
+		if ver, err := semver.NewVersion(version.value); err == nil && constraints.Check(ver) {
+			matching = append(matching, semverTag{tagValue: version, version: ver})
+		}
+	}
+	if len(matching) == 0 {
+		return nil, fmt.Errorf("no available version matching %s", r)
+	}
+
+	sort.SliceStable(matching, func(i, j int) bool {
+		return matching[i].version.GreaterThan(matching[j].version)
+	})
+	result := make([]tagValue, 0, len(matching))
+	for _, m := range matching {
+		result = append(result, m.tagValue)
+	}
+	return result, nil
+}
+
+// numericalSort returns the tags with the highest value first for
+// descending order and with the lowest value first for ascending order.
+// Values, which are not numbers, are ignored.
+func numericalSort(des bool, versions []tagValue) ([]tagValue, error) {
+	type numericalTag struct {
+		tagValue
+		n *big.Float
+	}
+	var numbers []numericalTag
+	for _, version := range versions {
+		n, ok := parseNumber(version.value)
+		if !ok {
+			continue
 		}
+		numbers = append(numbers, numericalTag{tagValue: version, n: n})
+	}
+	if len(numbers) == 0 {
+		return nil, errors.New("no numerical tag found")
+	}
+
+	sort.SliceStable(numbers, func(i, j int) bool {
+		if des {
+			return numbers[i].n.Cmp(numbers[j].n) > 0
+		}
+		return numbers[i].n.Cmp(numbers[j].n) < 0
+	})
+	result := make([]tagValue, 0, len(numbers))
+	for _, n := range numbers {
+		result = append(result, n.tagValue)
+	}
+	return result, nil
+}
+
+// parseNumber parses integers before floats, so large integers, like
+// timestamps with nanoseconds, keep their precision.
+func parseNumber(s string) (*big.Float, bool) {
+	n := new(big.Float).SetPrec(128)
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n.SetInt64(i), true
+	}
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return n.SetUint64(u), true
 	}
-	if latestVersion == nil {
-		return "", fmt.Errorf("no available version matching %s", r)
+	if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsNaN(f) {
+		return n.SetFloat64(f), true
 	}
-	return latestVersion.Original(), nil
+	return nil, false
 }
@@ -1,12 +1,13 @@
 package imagescan
 
 import (
+	"slices"
 	"testing"
 
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
 )
 
-func TestLatestTag(t *testing.T) {
+func TestSortTags(t *testing.T) {
 	var alphabeticalVersions = []string{"a", "b", "c"}
 
 	tests := []struct {
@@ -45,13 +46,179 @@ func TestLatestTag(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := latestTag(tt.policy, alphabeticalVersions)
+			got, err := sortTags(tt.policy, nil, alphabeticalVersions)
 			if err != nil {
-				t.Fatalf("Error calling latestTag: %v", err)
+				t.Fatalf("Error calling sortTags: %v", err)
 			}
 
-			if got != tt.want {
-				t.Errorf("latestTag() = %v, want %v", got, tt.want)
+			if got[0].tag != tt.want {
+				t.Errorf("sortTags()[0] = %v, want %v", got[0].tag, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortTagsFiltered(t *testing.T) {
+	var versions = []string{
+		"main-3f2a1b-1700000300",
+		"main-9c8d7e-1700000100",
+		"main-a1b2c3-1700000200",
+		"feature-ffffff-1800000000",
+		"latest",
+		"1.2.0",
+		"1.10.0",
+	}
+	var timestamps = &fleet.TagFilter{
+		Pattern: `^main-[a-f0-9]+-(?P<ts>[0-9]+)$`,
+		Extract: "$ts",
+	}
+
+	tests := []struct {
+		name, want, wantValue string
+		policy                fleet.ImagePolicyChoice
+		filter                *fleet.TagFilter
+	}{
+		{
+			name: "numerical desc",
+			policy: fleet.ImagePolicyChoice{
+				Numerical: &fleet.NumericalPolicy{Order: "desc"},
+			},
+			filter:    timestamps,
+			want:      "main-3f2a1b-1700000300",
+			wantValue: "1700000300",
+		},
+		{
+			name: "numerical asc",
+			policy: fleet.ImagePolicyChoice{
+				Numerical: &fleet.NumericalPolicy{Order: "ASC"},
+			},
+			filter:    timestamps,
+			want:      "main-9c8d7e-1700000100",
+			wantValue: "1700000100",
+		},
+		{
+			name: "numerical default order",
+			policy: fleet.ImagePolicyChoice{
+				Numerical: &fleet.NumericalPolicy{},
+			},
+			filter:    timestamps,
+			want:      "main-3f2a1b-1700000300",
+			wantValue: "1700000300",
+		},
+		{
+			name: "alphabetical on extracted value",
+			policy: fleet.ImagePolicyChoice{
+				Alphabetical: &fleet.AlphabeticalPolicy{Order: "asc"},
+			},
+			filter:    timestamps,
+			want:      "main-9c8d7e-1700000100",
+			wantValue: "1700000100",
+		},
+		{
+			name: "pattern without extract",
+			policy: fleet.ImagePolicyChoice{
+				Alphabetical: &fleet.AlphabeticalPolicy{Order: "desc"},
+			},
+			filter:    &fleet.TagFilter{Pattern: `^main-`},
+			want:      "main-a1b2c3-1700000200",
+			wantValue: "main-a1b2c3-1700000200",
+		},
+		{
+			name: "semver ignores filtered tags",
+			policy: fleet.ImagePolicyChoice{
+				SemVer: &fleet.SemVerPolicy{Range: "*"},
+			},
+			filter:    &fleet.TagFilter{Pattern: `^1\.2\.`},
+			want:      "1.2.0",
+			wantValue: "1.2.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sortTags(tt.policy, tt.filter, versions)
+			if err != nil {
+				t.Fatalf("Error calling sortTags: %v", err)
+			}
+
+			if got[0].tag != tt.want {
+				t.Errorf("sortTags()[0] = %v, want %v", got[0].tag, tt.want)
+			}
+			if got[0].value != tt.wantValue {
+				t.Errorf("sortTags()[0] value = %v, want %v", got[0].value, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestSortTagsFilterErrors(t *testing.T) {
+	policy := fleet.ImagePolicyChoice{Numerical: &fleet.NumericalPolicy{}}
+	tags := []string{"main-1", "main-2"}
+
+	if _, err := sortTags(policy, &fleet.TagFilter{Pattern: "("}, tags); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+	if _, err := sortTags(policy, &fleet.TagFilter{Pattern: "^release-"}, tags); err == nil {
+		t.Error("expected error if no tag matches the pattern")
+	}
+	if _, err := sortTags(policy, &fleet.TagFilter{Pattern: "^main-"}, tags); err == nil {
+		t.Error("expected error if no extracted value is a number")
+	}
+}
+
+func TestSortTagsNumericalPrecision(t *testing.T) {
+	// nanosecond timestamps cannot be told apart as floats
+	tags := []string{"1700000000000000001", "1700000000000000002", "1700000000000000000.5", "18446744073709551615"}
+
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{order: "desc", want: []string{"18446744073709551615", "1700000000000000002", "1700000000000000001", "1700000000000000000.5"}},
+		{order: "asc", want: []string{"1700000000000000000.5", "1700000000000000001", "1700000000000000002", "18446744073709551615"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			got, err := sortTags(fleet.ImagePolicyChoice{Numerical: &fleet.NumericalPolicy{Order: tt.order}}, nil, tags)
+			if err != nil {
+				t.Fatalf("Error calling sortTags: %v", err)
+			}
+			var order []string
+			for _, tag := range got {
+				order = append(order, tag.tag)
+			}
+			if !slices.Equal(order, tt.want) {
+				t.Errorf("sortTags() = %v, want %v", order, tt.want)
+			}
+		})
+	}
+}
+
+func TestShouldResolveDigest(t *testing.T) {
+	resolved := fleet.ImageScanStatus{
+		LatestImage:  "registry/app:stable",
+		LatestDigest: "sha256:abc",
+	}
+
+	tests := []struct {
+		name          string
+		policy        fleet.DigestReflectionPolicy
+		previousImage string
+		status        fleet.ImageScanStatus
+		want          bool
+	}{
+		{name: "default resolves moved tags", previousImage: "registry/app:stable", status: resolved, want: true},
+		{name: "always resolves moved tags", policy: fleet.ReflectAlways, previousImage: "registry/app:stable", status: resolved, want: true},
+		{name: "if not present keeps digest of unchanged tag", policy: fleet.ReflectIfNotPresent, previousImage: "registry/app:stable", status: resolved, want: false},
+		{name: "if not present resolves new tag", policy: fleet.ReflectIfNotPresent, previousImage: "registry/app:old", status: resolved, want: true},
+		{name: "if not present resolves missing digest", policy: fleet.ReflectIfNotPresent, previousImage: "registry/app:stable", status: fleet.ImageScanStatus{LatestImage: "registry/app:stable"}, want: true},
+		{name: "never", policy: fleet.ReflectNever, previousImage: "registry/app:old", status: resolved, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldResolveDigest(tt.policy, tt.previousImage, tt.status); got != tt.want {
+				t.Errorf("shouldResolveDigest() = %v, want %v", got, tt.want)
 			}
 		})
 	}
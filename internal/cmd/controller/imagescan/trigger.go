@@ -0,0 +1,54 @@
+package imagescan
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/reugn/go-quartz/quartz"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+)
+
+// NewTagScanTrigger returns the trigger of the tag scan job, which fires with
+// the interval. If a registry webhook requested a scan, it fires immediately
+// first.
+func NewTagScanTrigger(image *fleet.ImageScan, interval time.Duration) quartz.Trigger {
+	if !scanRequested(image) {
+		return quartz.NewSimpleTrigger(interval)
+	}
+	return &requestedScanTrigger{interval: interval}
+}
+
+// scanRequested returns true, if a registry webhook requested a scan after
+// the last scan.
+func scanRequested(image *fleet.ImageScan) bool {
+	requested, err := time.Parse(time.RFC3339Nano, image.Annotations[fleet.ImageScanRequestedAnnotation])
+	if err != nil {
+		return false
+	}
+	return requested.After(image.Status.LastScanTime.Time)
+}
+
+// requestedScanTrigger fires immediately and then like a
+// quartz.SimpleTrigger.
+type requestedScanTrigger struct {
+	interval time.Duration
+	fired    bool
+}
+
+var _ quartz.Trigger = &requestedScanTrigger{}
+
+// NextFireTime returns prev for the first fire time and prev plus the
+// interval afterwards.
+func (t *requestedScanTrigger) NextFireTime(prev int64) (int64, error) {
+	if !t.fired {
+		t.fired = true
+		return prev, nil
+	}
+	return prev + t.interval.Nanoseconds(), nil
+}
+
+// Description returns the description of the trigger.
+func (t *requestedScanTrigger) Description() string {
+	return fmt.Sprintf("RequestedScanTrigger%s%s", quartz.Sep, t.interval)
+}
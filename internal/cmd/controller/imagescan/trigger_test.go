@@ -0,0 +1,70 @@
+package imagescan
+
+import (
+	"testing"
+	"time"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestShouldScanRequested(t *testing.T) {
+	now := time.Now()
+	image := func(requested string, lastScan time.Time) *fleet.ImageScan {
+		i := &fleet.ImageScan{
+			Spec:   fleet.ImageScanSpec{Interval: metav1.Duration{Duration: time.Hour}},
+			Status: fleet.ImageScanStatus{LatestTag: "1.0", LastScanTime: metav1.NewTime(lastScan)},
+		}
+		if requested != "" {
+			i.Annotations = map[string]string{fleet.ImageScanRequestedAnnotation: requested}
+		}
+		return i
+	}
+
+	tests := []struct {
+		name  string
+		image *fleet.ImageScan
+		want  bool
+	}{
+		{name: "not requested", image: image("", now.Add(-time.Minute)), want: false},
+		{name: "requested after last scan", image: image(now.Format(time.RFC3339Nano), now.Add(-time.Minute)), want: true},
+		{name: "requested before last scan", image: image(now.Add(-2*time.Minute).Format(time.RFC3339Nano), now.Add(-time.Minute)), want: false},
+		{name: "invalid request", image: image("now", now.Add(-time.Minute)), want: false},
+		{name: "interval passed", image: image("", now.Add(-2*time.Hour)), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldScan(tt.image); got != tt.want {
+				t.Errorf("shouldScan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagScanTrigger(t *testing.T) {
+	const now = int64(1000)
+	interval := time.Minute
+
+	image := &fleet.ImageScan{}
+	next, err := NewTagScanTrigger(image, interval).NextFireTime(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != now+interval.Nanoseconds() {
+		t.Errorf("expected scan after the interval, got %d", next)
+	}
+
+	image.Annotations = map[string]string{fleet.ImageScanRequestedAnnotation: time.Now().Format(time.RFC3339Nano)}
+	trigger := NewTagScanTrigger(image, interval)
+	for i, want := range []int64{now, now + interval.Nanoseconds()} {
+		next, err := trigger.NextFireTime(now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next != want {
+			t.Errorf("fire %d: expected %d, got %d", i, want, next)
+		}
+	}
+}
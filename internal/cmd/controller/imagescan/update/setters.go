@@ -151,8 +151,20 @@ func WithSetters(inpath, outpath string, scans []*v1alpha1.ImageScan) error {
 		defs[fieldmeta.SetterDefinitionPrefix+nameSetter] = setterSchema(nameSetter, name)
 		imageRefs[nameSetter] = ref
 
+		if scan.Status.LatestDigest == "" {
+			// digests are not resolved, see DigestReflectionPolicy
+			continue
+		}
+
+		// the digest setters pin the image to an immutable reference,
+		// the tag is kept for readability
 		digestSetter := imageSetter + ":digest"
 		defs[fieldmeta.SetterDefinitionPrefix+digestSetter] = setterSchema(digestSetter, fmt.Sprintf("%s@%s", scan.Status.LatestImage, scan.Status.LatestDigest))
+		imageRefs[digestSetter] = ref
+
+		tagDigestSetter := imageSetter + ":tag@digest"
+		defs[fieldmeta.SetterDefinitionPrefix+tagDigestSetter] = setterSchema(tagDigestSetter, fmt.Sprintf("%s@%s", tag, scan.Status.LatestDigest))
+		imageRefs[tagDigestSetter] = ref
 	}
 
 	settersSchema.Definitions = defs
@@ -0,0 +1,90 @@
+package update
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const manifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: registry.example.com/app:1.0.0 # {"$imagescan": "app"}
+      - name: pinned
+        image: registry.example.com/app:1.0.0 # {"$imagescan": "app:digest"}
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: values
+data:
+  tag: 1.0.0 # {"$imagescan": "app:tag@digest"}
+`
+
+func TestWithSettersDigests(t *testing.T) {
+	const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	tests := map[string]struct {
+		digest   string
+		expected []string
+	}{
+		"with digest": {
+			digest: digest,
+			expected: []string{
+				`image: registry.example.com/app:1.1.0 # {"$imagescan": "app"}`,
+				`image: registry.example.com/app:1.1.0@` + digest + ` # {"$imagescan": "app:digest"}`,
+				`tag: 1.1.0@` + digest + ` # {"$imagescan": "app:tag@digest"}`,
+			},
+		},
+		"without digest": {
+			expected: []string{
+				`image: registry.example.com/app:1.1.0 # {"$imagescan": "app"}`,
+				`image: registry.example.com/app:1.0.0 # {"$imagescan": "app:digest"}`,
+				`tag: 1.0.0 # {"$imagescan": "app:tag@digest"}`,
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			file := filepath.Join(dir, "manifest.yaml")
+			if err := os.WriteFile(file, []byte(manifest), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			scan := &v1alpha1.ImageScan{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-local", Name: "app"},
+				Spec:       v1alpha1.ImageScanSpec{TagName: "app"},
+				Status: v1alpha1.ImageScanStatus{
+					LatestImage:  "registry.example.com/app:1.1.0",
+					LatestDigest: tt.digest,
+				},
+			}
+			if err := WithSetters(dir, dir, []*v1alpha1.ImageScan{scan}); err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, line := range tt.expected {
+				if !strings.Contains(string(b), line) {
+					t.Errorf("expected %q in result:\n%s", line, b)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,465 @@
+package imagescan
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	"github.com/rancher/wrangler/v3/pkg/condition"
+
+	errutil "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// Annotations of the layers of cosign signature images.
+const (
+	signatureAnnotation   = "dev.cosignproject.cosign/signature"
+	certificateAnnotation = "dev.sigstore.cosign/certificate"
+	chainAnnotation       = "dev.sigstore.cosign/chain"
+	bundleAnnotation      = "dev.sigstore.cosign/bundle"
+)
+
+const (
+	// maxVerifyCandidates limits the tags, whose signatures are checked in
+	// a single scan.
+	maxVerifyCandidates = 10
+	// maxPayloadSize limits the size of signed payloads, which are read
+	// from the registry.
+	maxPayloadSize = 1 << 20
+)
+
+var (
+	// OIDs of the OIDC issuer extension in Fulcio certificates, the first
+	// one is deprecated.
+	oidIssuer   = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	oidIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+
+	errNoSignature = errors.New("no signature found")
+)
+
+// verifiedTag returns the first of the candidates, whose image has a valid
+// signature, and the verified digest of the image. The skipped candidates are
+// reported by the verify condition of the status.
+func verifiedTag(repo name.Repository, spec *fleet.ImageVerification, candidates []tagValue, status *fleet.ImageScanStatus, options ...remote.Option) (tagValue, string, error) {
+	cond := condition.Cond(fleet.ImageScanVerifyCondition)
+
+	v, err := newVerifier(spec)
+	if err != nil {
+		cond.SetError(status, "", err)
+		return tagValue{}, "", err
+	}
+
+	var skipped []string
+	for i, c := range candidates {
+		if i >= maxVerifyCandidates {
+			break
+		}
+
+		desc, err := remote.Head(repo.Tag(c.tag), options...)
+		if err == nil {
+			err = v.verify(repo, desc.Digest, options...)
+		}
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", c.tag, err))
+			continue
+		}
+
+		cond.SetError(status, "", nil)
+		if len(skipped) > 0 {
+			cond.Message(status, "Skipped tags without valid signature: "+strings.Join(skipped, "; "))
+		}
+		return c, desc.Digest.String(), nil
+	}
+
+	err = fmt.Errorf("no image with a valid signature: %s", strings.Join(skipped, "; "))
+	cond.SetError(status, "", err)
+	return tagValue{}, "", err
+}
+
+// verifier checks cosign signatures of images.
+type verifier struct {
+	publicKey crypto.PublicKey
+	keyless   *keylessVerifier
+}
+
+func newVerifier(spec *fleet.ImageVerification) (*verifier, error) {
+	v := &verifier{}
+	if spec.PublicKey != "" {
+		key, err := parsePublicKey(spec.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key: %w", err)
+		}
+		v.publicKey = key
+	}
+	if spec.Keyless != nil {
+		k, err := newKeylessVerifier(spec.Keyless)
+		if err != nil {
+			return nil, err
+		}
+		v.keyless = k
+	}
+	if v.publicKey == nil && v.keyless == nil {
+		return nil, errors.New("image verification requires a public key or keyless verification")
+	}
+	return v, nil
+}
+
+// verify returns nil, if the image with the digest has a valid signature.
+// Signatures are stored as layers of the "sha256-<digest>.sig" image.
+func (v *verifier) verify(repo name.Repository, digest v1.Hash, options ...remote.Option) error {
+	img, err := remote.Image(repo.Tag(digest.Algorithm+"-"+digest.Hex+".sig"), options...)
+	if err != nil {
+		var terr *transport.Error
+		if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+			return errNoSignature
+		}
+		return err
+	}
+	manifest, err := img.Manifest()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, desc := range manifest.Layers {
+		sig, ok := desc.Annotations[signatureAnnotation]
+		if !ok {
+			continue
+		}
+		payload, err := layerPayload(img, desc.Digest)
+		if err == nil {
+			err = v.verifySignature(payload, sig, desc.Annotations, digest)
+		}
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return errNoSignature
+	}
+	return fmt.Errorf("invalid signature: %w", errutil.NewAggregate(errs))
+}
+
+func layerPayload(img v1.Image, digest v1.Hash) ([]byte, error) {
+	layer, err := img.LayerByDigest(digest)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := layer.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(io.LimitReader(rc, maxPayloadSize))
+}
+
+func (v *verifier) verifySignature(payload []byte, signature string, annotations map[string]string, digest v1.Hash) error {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("cannot decode signature: %w", err)
+	}
+	if err := checkPayload(payload, digest); err != nil {
+		return err
+	}
+
+	if _, ok := annotations[certificateAnnotation]; ok {
+		if v.keyless == nil {
+			return errors.New("keyless signatures are not accepted")
+		}
+		return v.keyless.verify(payload, sig, annotations)
+	}
+	if v.publicKey == nil {
+		return errors.New("signatures without certificate are not accepted")
+	}
+	return verifyWithKey(v.publicKey, payload, sig)
+}
+
+// checkPayload returns an error, if the signed payload is not for the image
+// with the digest.
+func checkPayload(payload []byte, digest v1.Hash) error {
+	var p struct {
+		Critical struct {
+			Image struct {
+				DockerManifestDigest string `json:"docker-manifest-digest"`
+			} `json:"image"`
+		} `json:"critical"`
+	}
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("cannot parse signed payload: %w", err)
+	}
+	if p.Critical.Image.DockerManifestDigest != digest.String() {
+		return fmt.Errorf("signature is for image %s", p.Critical.Image.DockerManifestDigest)
+	}
+	return nil
+}
+
+func verifyWithKey(key crypto.PublicKey, payload, sig []byte) error {
+	h := sha256.Sum256(payload)
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, h[:], sig) {
+			return errors.New("signature does not match")
+		}
+		return nil
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, h[:], sig)
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, payload, sig) {
+			return errors.New("signature does not match")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported key type %T", key)
+}
+
+func parsePublicKey(data string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+func parseCertificates(data string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no PEM certificate found")
+	}
+	return certs, nil
+}
+
+// keylessVerifier checks signatures made with short-lived certificates. The
+// signed entry timestamp of the transparency log is used as the signing
+// time, at which the certificate has to be valid.
+type keylessVerifier struct {
+	roots         *x509.CertPool
+	intermediates *x509.CertPool
+	rekorKey      crypto.PublicKey
+	identities    []signatureIdentity
+}
+
+type signatureIdentity struct {
+	issuer  *regexp.Regexp
+	subject *regexp.Regexp
+}
+
+func newKeylessVerifier(spec *fleet.KeylessVerification) (*keylessVerifier, error) {
+	if len(spec.Identities) == 0 {
+		return nil, errors.New("keyless verification requires identities")
+	}
+
+	k := &keylessVerifier{
+		roots:         x509.NewCertPool(),
+		intermediates: x509.NewCertPool(),
+	}
+	certs, err := parseCertificates(spec.Roots)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keyless roots: %w", err)
+	}
+	for _, cert := range certs {
+		if bytes.Equal(cert.RawSubject, cert.RawIssuer) {
+			k.roots.AddCert(cert)
+		} else {
+			k.intermediates.AddCert(cert)
+		}
+	}
+
+	if k.rekorKey, err = parsePublicKey(spec.RekorPublicKey); err != nil {
+		return nil, fmt.Errorf("invalid rekor public key: %w", err)
+	}
+
+	for _, id := range spec.Identities {
+		issuer, err := anchoredRegexp(id.Issuer)
+		if err != nil {
+			return nil, fmt.Errorf("invalid issuer %q: %w", id.Issuer, err)
+		}
+		subject, err := anchoredRegexp(id.Subject)
+		if err != nil {
+			return nil, fmt.Errorf("invalid subject %q: %w", id.Subject, err)
+		}
+		k.identities = append(k.identities, signatureIdentity{issuer: issuer, subject: subject})
+	}
+	return k, nil
+}
+
+func anchoredRegexp(expr string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + expr + ")$")
+}
+
+func (k *keylessVerifier) verify(payload, sig []byte, annotations map[string]string) error {
+	certs, err := parseCertificates(annotations[certificateAnnotation])
+	if err != nil {
+		return fmt.Errorf("invalid certificate: %w", err)
+	}
+	cert := certs[0]
+
+	// the chain of the signature can only add intermediates, roots are
+	// trusted from the configuration only
+	intermediates := k.intermediates.Clone()
+	if chain, ok := annotations[chainAnnotation]; ok {
+		chainCerts, err := parseCertificates(chain)
+		if err != nil {
+			return fmt.Errorf("invalid certificate chain: %w", err)
+		}
+		for _, c := range chainCerts {
+			intermediates.AddCert(c)
+		}
+	}
+
+	signedAt, err := k.verifyBundle(annotations[bundleAnnotation], payload, sig)
+	if err != nil {
+		return err
+	}
+
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:         k.roots,
+		Intermediates: intermediates,
+		CurrentTime:   signedAt,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}); err != nil {
+		return fmt.Errorf("untrusted certificate: %w", err)
+	}
+
+	if err := k.matchIdentity(cert); err != nil {
+		return err
+	}
+
+	return verifyWithKey(cert.PublicKey, payload, sig)
+}
+
+// rekorPayload is the transparency log entry, which is signed by the signed
+// entry timestamp. Its fields are in the order of the canonical JSON.
+type rekorPayload struct {
+	Body           string `json:"body"`
+	IntegratedTime int64  `json:"integratedTime"`
+	LogID          string `json:"logID"`
+	LogIndex       int64  `json:"logIndex"`
+}
+
+// verifyBundle verifies the signed entry timestamp of the transparency log
+// entry for the signature and returns the time the entry was logged.
+func (k *keylessVerifier) verifyBundle(data string, payload, sig []byte) (time.Time, error) {
+	if data == "" {
+		return time.Time{}, errors.New("signature has no transparency log bundle")
+	}
+	var bundle struct {
+		SignedEntryTimestamp []byte
+		Payload              rekorPayload
+	}
+	if err := json.Unmarshal([]byte(data), &bundle); err != nil {
+		return time.Time{}, fmt.Errorf("cannot parse transparency log bundle: %w", err)
+	}
+
+	canonical, err := json.Marshal(bundle.Payload)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := verifyWithKey(k.rekorKey, canonical, bundle.SignedEntryTimestamp); err != nil {
+		return time.Time{}, fmt.Errorf("invalid signed entry timestamp: %w", err)
+	}
+
+	// the logged entry has to be for this signature
+	body, err := base64.StdEncoding.DecodeString(bundle.Payload.Body)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot decode transparency log entry: %w", err)
+	}
+	var entry struct {
+		Kind string `json:"kind"`
+		Spec struct {
+			Data struct {
+				Hash struct {
+					Algorithm string `json:"algorithm"`
+					Value     string `json:"value"`
+				} `json:"hash"`
+			} `json:"data"`
+			Signature struct {
+				Content []byte `json:"content"`
+			} `json:"signature"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(body, &entry); err != nil {
+		return time.Time{}, fmt.Errorf("cannot parse transparency log entry: %w", err)
+	}
+	if entry.Kind != "hashedrekord" {
+		return time.Time{}, fmt.Errorf("unsupported transparency log entry kind %q", entry.Kind)
+	}
+	h := sha256.Sum256(payload)
+	if entry.Spec.Data.Hash.Algorithm != "sha256" || entry.Spec.Data.Hash.Value != hex.EncodeToString(h[:]) ||
+		!bytes.Equal(entry.Spec.Signature.Content, sig) {
+		return time.Time{}, errors.New("transparency log entry is for another signature")
+	}
+
+	return time.Unix(bundle.Payload.IntegratedTime, 0), nil
+}
+
+func (k *keylessVerifier) matchIdentity(cert *x509.Certificate) error {
+	issuer := certificateIssuer(cert)
+	subjects := append([]string{}, cert.EmailAddresses...)
+	for _, u := range cert.URIs {
+		subjects = append(subjects, u.String())
+	}
+
+	for _, id := range k.identities {
+		if !id.issuer.MatchString(issuer) {
+			continue
+		}
+		for _, s := range subjects {
+			if id.subject.MatchString(s) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("certificate identity %v issued by %q is not allowed", subjects, issuer)
+}
+
+// certificateIssuer returns the OIDC issuer of a Fulcio certificate.
+func certificateIssuer(cert *x509.Certificate) string {
+	var issuer string
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(oidIssuerV2):
+			var s string
+			if _, err := asn1.Unmarshal(ext.Value, &s); err == nil {
+				return s
+			}
+		case ext.Id.Equal(oidIssuer):
+			issuer = string(ext.Value)
+		}
+	}
+	return issuer
+}
@@ -0,0 +1,407 @@
+package imagescan
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	"github.com/rancher/wrangler/v3/pkg/condition"
+)
+
+// testRegistry is a local OCI registry with an image repository.
+type testRegistry struct {
+	t    *testing.T
+	repo name.Repository
+}
+
+func newTestRegistry(t *testing.T) *testRegistry {
+	srv := httptest.NewServer(registry.New())
+	t.Cleanup(srv.Close)
+
+	repo, err := name.NewRepository(strings.TrimPrefix(srv.URL, "http://") + "/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testRegistry{t: t, repo: repo}
+}
+
+// push pushes a random image with the tag and returns its digest.
+func (r *testRegistry) push(tag string) v1.Hash {
+	img, err := random.Image(256, 1)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	if err := remote.Write(r.repo.Tag(tag), img); err != nil {
+		r.t.Fatal(err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	return digest
+}
+
+// sign pushes a cosign signature image for the digest with a layer for
+// each of the annotated payloads.
+func (r *testRegistry) sign(digest v1.Hash, payload []byte, annotations ...map[string]string) {
+	img := empty.Image
+	for _, a := range annotations {
+		var err error
+		img, err = mutate.Append(img, mutate.Addendum{
+			Layer:       static.NewLayer(payload, "application/vnd.dev.cosign.simplesigning.v1+json"),
+			Annotations: a,
+		})
+		if err != nil {
+			r.t.Fatal(err)
+		}
+	}
+	if err := remote.Write(r.repo.Tag(digest.Algorithm+"-"+digest.Hex+".sig"), img); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+func simpleSigning(digest v1.Hash) []byte {
+	return []byte(`{"critical":{"identity":{"docker-reference":"registry/app"},"image":{"docker-manifest-digest":"` +
+		digest.String() + `"},"type":"cosign container image signature"},"optional":null}`)
+}
+
+func newKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func signPayload(t *testing.T, key *ecdsa.PrivateKey, payload []byte) []byte {
+	t.Helper()
+	h := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, h[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
+
+func candidates(tags ...string) []tagValue {
+	result := make([]tagValue, 0, len(tags))
+	for _, tag := range tags {
+		result = append(result, tagValue{tag: tag, value: tag})
+	}
+	return result
+}
+
+func TestVerifiedTagWithPublicKey(t *testing.T) {
+	r := newTestRegistry(t)
+	key, publicKey := newKey(t)
+	otherKey, _ := newKey(t)
+
+	signed := r.push("1.0.0")
+	payload := simpleSigning(signed)
+	r.sign(signed, payload, map[string]string{
+		signatureAnnotation: base64.StdEncoding.EncodeToString(signPayload(t, key, payload)),
+	})
+
+	r.push("1.1.0")
+
+	wrongKey := r.push("1.2.0")
+	payload = simpleSigning(wrongKey)
+	r.sign(wrongKey, payload, map[string]string{
+		signatureAnnotation: base64.StdEncoding.EncodeToString(signPayload(t, otherKey, payload)),
+	})
+
+	// the signature of another image is copied
+	copied := r.push("1.3.0")
+	payload = simpleSigning(signed)
+	r.sign(copied, payload, map[string]string{
+		signatureAnnotation: base64.StdEncoding.EncodeToString(signPayload(t, key, payload)),
+	})
+
+	status := &fleet.ImageScanStatus{}
+	spec := &fleet.ImageVerification{PublicKey: publicKey}
+	got, digest, err := verifiedTag(r.repo, spec, candidates("1.3.0", "1.2.0", "1.1.0", "1.0.0"), status)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.tag != "1.0.0" {
+		t.Errorf("verifiedTag() = %s, want 1.0.0", got.tag)
+	}
+	if digest != signed.String() {
+		t.Errorf("verifiedTag() digest = %s, want %s", digest, signed)
+	}
+
+	cond := condition.Cond(fleet.ImageScanVerifyCondition)
+	if !cond.IsTrue(status) {
+		t.Errorf("expected verify condition to be true")
+	}
+	msg := cond.GetMessage(status)
+	for _, want := range []string{"1.3.0: invalid signature", "1.2.0: invalid signature", "1.1.0: no signature found"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("expected %q in condition message %q", want, msg)
+		}
+	}
+
+	if _, _, err := verifiedTag(r.repo, spec, candidates("1.1.0"), status); err == nil {
+		t.Error("expected an error without signed candidates")
+	}
+	if !cond.IsFalse(status) {
+		t.Errorf("expected verify condition to be false")
+	}
+}
+
+// fulcio issues short-lived certificates and logs signatures like the
+// sigstore public good instance.
+type fulcio struct {
+	t        *testing.T
+	root     *x509.Certificate
+	rootKey  *ecdsa.PrivateKey
+	rootPEM  string
+	rekorKey *ecdsa.PrivateKey
+	rekorPEM string
+	// logDelay is the time between issuing the certificate and logging the
+	// signature
+	logDelay time.Duration
+}
+
+func newFulcio(t *testing.T) *fulcio {
+	rootKey, _ := newKey(t)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "sigstore"},
+		NotBefore:             time.Now().Add(-24 * time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &rootKey.PublicKey, rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rekorKey, rekorPEM := newKey(t)
+
+	return &fulcio{
+		t:        t,
+		root:     root,
+		rootKey:  rootKey,
+		rootPEM:  string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		rekorKey: rekorKey,
+		rekorPEM: rekorPEM,
+	}
+}
+
+// signature returns the annotations of a keyless signature by the email
+// identity, with a certificate issued at signedAt.
+func (f *fulcio) signature(payload []byte, email, issuer string, signedAt time.Time) map[string]string {
+	key, _ := newKey(f.t)
+	issuerExt, err := asn1.Marshal(issuer)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:    big.NewInt(2),
+		NotBefore:       signedAt.Add(-time.Minute),
+		NotAfter:        signedAt.Add(10 * time.Minute),
+		EmailAddresses:  []string{email},
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		ExtraExtensions: []pkix.Extension{{Id: oidIssuerV2, Value: issuerExt}},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, f.root, &key.PublicKey, f.rootKey)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	sig := signPayload(f.t, key, payload)
+	h := sha256.Sum256(payload)
+	body, _ := json.Marshal(map[string]interface{}{
+		"apiVersion": "0.0.1",
+		"kind":       "hashedrekord",
+		"spec": map[string]interface{}{
+			"data":      map[string]interface{}{"hash": map[string]string{"algorithm": "sha256", "value": hex.EncodeToString(h[:])}},
+			"signature": map[string]interface{}{"content": sig, "publicKey": map[string]interface{}{"content": certPEM}},
+		},
+	})
+	entry := rekorPayload{
+		Body:           base64.StdEncoding.EncodeToString(body),
+		IntegratedTime: signedAt.Add(f.logDelay).Unix(),
+		LogID:          "c0d23d6ad406973f9559f3ba2d1ca01f84147d8ffc5b8445c224f98b9591801d",
+		LogIndex:       42,
+	}
+	canonical, _ := json.Marshal(entry)
+	bundle, _ := json.Marshal(map[string]interface{}{
+		"SignedEntryTimestamp": signPayload(f.t, f.rekorKey, canonical),
+		"Payload":              entry,
+	})
+
+	return map[string]string{
+		signatureAnnotation:   base64.StdEncoding.EncodeToString(sig),
+		certificateAnnotation: string(certPEM),
+		chainAnnotation:       f.rootPEM,
+		bundleAnnotation:      string(bundle),
+	}
+}
+
+func TestVerifiedTagKeyless(t *testing.T) {
+	f := newFulcio(t)
+	signedAt := time.Now().Add(-time.Hour)
+	const issuer = "https://token.actions.githubusercontent.com"
+
+	spec := &fleet.ImageVerification{
+		Keyless: &fleet.KeylessVerification{
+			Identities: []fleet.SignatureIdentity{{
+				Issuer:  issuer,
+				Subject: "ci@example\\.com",
+			}},
+			Roots:          f.rootPEM,
+			RekorPublicKey: f.rekorPEM,
+		},
+	}
+
+	tests := map[string]struct {
+		annotations func(payload []byte) map[string]string
+		wantErr     string
+	}{
+		"valid signature": {
+			annotations: func(payload []byte) map[string]string {
+				return f.signature(payload, "ci@example.com", issuer, signedAt)
+			},
+		},
+		"other identity": {
+			annotations: func(payload []byte) map[string]string {
+				return f.signature(payload, "dev@example.com", issuer, signedAt)
+			},
+			wantErr: "is not allowed",
+		},
+		"other issuer": {
+			annotations: func(payload []byte) map[string]string {
+				return f.signature(payload, "ci@example.com", "https://accounts.example.com", signedAt)
+			},
+			wantErr: "is not allowed",
+		},
+		"bundle of another signature": {
+			annotations: func(payload []byte) map[string]string {
+				a := f.signature(payload, "ci@example.com", issuer, signedAt)
+				other := f.signature(payload, "ci@example.com", issuer, signedAt)
+				a[bundleAnnotation] = other[bundleAnnotation]
+				return a
+			},
+			wantErr: "transparency log entry is for another signature",
+		},
+		"certificate expired when logged": {
+			annotations: func(payload []byte) map[string]string {
+				f.logDelay = time.Hour
+				defer func() { f.logDelay = 0 }()
+				return f.signature(payload, "ci@example.com", issuer, signedAt)
+			},
+			wantErr: "untrusted certificate",
+		},
+		"forged timestamp": {
+			annotations: func(payload []byte) map[string]string {
+				a := f.signature(payload, "ci@example.com", issuer, signedAt)
+				a[bundleAnnotation] = strings.Replace(a[bundleAnnotation], `"logIndex":42`, `"logIndex":43`, 1)
+				return a
+			},
+			wantErr: "invalid signed entry timestamp",
+		},
+		"without bundle": {
+			annotations: func(payload []byte) map[string]string {
+				a := f.signature(payload, "ci@example.com", issuer, signedAt)
+				delete(a, bundleAnnotation)
+				return a
+			},
+			wantErr: "no transparency log bundle",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := newTestRegistry(t)
+			digest := r.push("1.0.0")
+			payload := simpleSigning(digest)
+			r.sign(digest, payload, tt.annotations(payload))
+
+			status := &fleet.ImageScanStatus{}
+			got, _, err := verifiedTag(r.repo, spec, candidates("1.0.0"), status)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.tag != "1.0.0" {
+					t.Errorf("verifiedTag() = %s, want 1.0.0", got.tag)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestVerifiedTagKeylessRejectedWithPublicKey(t *testing.T) {
+	f := newFulcio(t)
+	_, publicKey := newKey(t)
+	r := newTestRegistry(t)
+	digest := r.push("1.0.0")
+	payload := simpleSigning(digest)
+	r.sign(digest, payload, f.signature(payload, "ci@example.com", "https://issuer.example.com", time.Now()))
+
+	_, _, err := verifiedTag(r.repo, &fleet.ImageVerification{PublicKey: publicKey}, candidates("1.0.0"), &fleet.ImageScanStatus{})
+	if err == nil || !strings.Contains(err.Error(), "keyless signatures are not accepted") {
+		t.Errorf("expected keyless signature to be rejected, got %v", err)
+	}
+}
+
+func TestNewVerifierErrors(t *testing.T) {
+	tests := map[string]*fleet.ImageVerification{
+		"empty":              {},
+		"invalid public key": {PublicKey: "not a key"},
+		"keyless without identities": {Keyless: &fleet.KeylessVerification{
+			Roots: "roots",
+		}},
+		"keyless without roots": {Keyless: &fleet.KeylessVerification{
+			Identities: []fleet.SignatureIdentity{{Issuer: ".*", Subject: ".*"}},
+		}},
+	}
+	for name, spec := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := newVerifier(spec); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
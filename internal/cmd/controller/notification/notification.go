@@ -0,0 +1,147 @@
+// Package notification renders and delivers notifications about state
+// transitions of Fleet resources to HTTP webhooks.
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+)
+
+const defaultContentType = "application/json"
+
+// DefaultStates trigger notifications, if a Notification does not list any
+// states.
+var DefaultStates = []fleet.BundleState{fleet.ErrApplied, fleet.Modified, fleet.NotReady}
+
+// DefaultTimeout limits the delivery of a notification, if the webhook does
+// not set a timeout.
+const DefaultTimeout = 10 * time.Second
+
+// Event is a state transition of a Fleet resource, it is passed to the body
+// template.
+type Event struct {
+	Kind          string              `json:"kind"`
+	Namespace     string              `json:"namespace"`
+	Name          string              `json:"name"`
+	State         fleet.BundleState   `json:"state"`
+	PreviousState fleet.BundleState   `json:"previousState,omitempty"`
+	Message       string              `json:"message,omitempty"`
+	Summary       fleet.BundleSummary `json:"summary"`
+}
+
+// Text is a human readable description of the event.
+func (e Event) Text() string {
+	text := fmt.Sprintf("%s %s/%s is %s", e.Kind, e.Namespace, e.Name, e.State)
+	if e.PreviousState != "" {
+		text += fmt.Sprintf(" (was %s)", e.PreviousState)
+	}
+	if e.Message != "" {
+		text += ": " + e.Message
+	}
+	return text
+}
+
+// State returns the highest ranked state of the bundle deployments in the
+// summary, or Ready if there are none which are not ready.
+func State(summary fleet.BundleSummary) fleet.BundleState {
+	state := fleet.Ready
+	for s, count := range map[fleet.BundleState]int{
+		fleet.NotReady:    summary.NotReady,
+		fleet.WaitApplied: summary.WaitApplied,
+		fleet.ErrApplied:  summary.ErrApplied,
+		fleet.OutOfSync:   summary.OutOfSync,
+		fleet.Modified:    summary.Modified,
+		fleet.Pending:     summary.Pending,
+	} {
+		if count > 0 && fleet.StateRank[s] > fleet.StateRank[state] {
+			state = s
+		}
+	}
+	return state
+}
+
+// Render returns the request body for the event. Without a template, the
+// event is encoded as JSON, including a "text" field.
+func Render(tmpl string, e Event) ([]byte, error) {
+	if tmpl == "" {
+		return json.Marshal(struct {
+			Event
+			Text string `json:"text"`
+		}{e, e.Text()})
+	}
+
+	t, err := template.New("notification").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	var b bytes.Buffer
+	if err := t.Execute(&b, e); err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// Webhook delivers notifications to an HTTP endpoint.
+type Webhook struct {
+	Client      *http.Client
+	URL         string
+	Token       string
+	Headers     map[string]string
+	ContentType string
+	// Timeout limits a single delivery, defaults to DefaultTimeout.
+	Timeout time.Duration
+}
+
+// Send posts the body to the webhook once. Failed deliveries are not retried
+// here, the caller requeues them, so a slow or failing webhook does not block
+// its worker.
+func (w *Webhook) Send(ctx context.Context, body []byte) error {
+	timeout := w.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	contentType := w.ContentType
+	if contentType == "" {
+		contentType = defaultContentType
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range w.Headers {
+		req.Header.Set(k, v)
+	}
+	if w.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+w.Token)
+	}
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+}
@@ -0,0 +1,127 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+)
+
+func TestState(t *testing.T) {
+	tests := []struct {
+		name    string
+		summary fleet.BundleSummary
+		want    fleet.BundleState
+	}{
+		{name: "empty", want: fleet.Ready},
+		{name: "ready", summary: fleet.BundleSummary{Ready: 2, DesiredReady: 2}, want: fleet.Ready},
+		{name: "not ready", summary: fleet.BundleSummary{Ready: 1, NotReady: 1, DesiredReady: 2}, want: fleet.NotReady},
+		{name: "highest rank wins", summary: fleet.BundleSummary{NotReady: 1, Modified: 1, ErrApplied: 1, DesiredReady: 3}, want: fleet.ErrApplied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := State(tt.summary); got != tt.want {
+				t.Errorf("State() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	e := Event{
+		Kind:          "GitRepo",
+		Namespace:     "fleet-default",
+		Name:          "apps",
+		State:         fleet.ErrApplied,
+		PreviousState: fleet.Ready,
+		Message:       "helm install failed",
+	}
+
+	got, err := Render("", e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"kind":"GitRepo","namespace":"fleet-default","name":"apps","state":"ErrApplied","previousState":"Ready","message":"helm install failed","summary":{"ready":0,"desiredReady":0},"text":"GitRepo fleet-default/apps is ErrApplied (was Ready): helm install failed"}`
+	if string(got) != want {
+		t.Errorf("Render() = %s, want %s", got, want)
+	}
+
+	got, err = Render(`{{ .Kind }} {{ .Name }}: {{ .State }}`, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "GitRepo apps: ErrApplied" {
+		t.Errorf("Render() = %s", got)
+	}
+
+	if _, err := Render(`{{ .Missing }}`, e); err == nil {
+		t.Error("expected an error for a missing field")
+	}
+}
+
+func TestSend(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "success", status: http.StatusNoContent},
+		{name: "server error", status: http.StatusBadGateway, wantErr: true},
+		{name: "client error", status: http.StatusNotFound, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+					t.Errorf("unexpected Authorization header %q", got)
+				}
+				if got := r.Header.Get("X-Team"); got != "oncall" {
+					t.Errorf("unexpected X-Team header %q", got)
+				}
+				if got := r.Header.Get("Content-Type"); got != "application/json" {
+					t.Errorf("unexpected Content-Type header %q", got)
+				}
+				w.WriteHeader(tt.status)
+				calls++
+			}))
+			defer srv.Close()
+
+			w := &Webhook{
+				Client:  srv.Client(),
+				URL:     srv.URL,
+				Token:   "secret",
+				Headers: map[string]string{"X-Team": "oncall"},
+			}
+			err := w.Send(context.TODO(), []byte(`{}`))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			// failed deliveries are requeued by the caller
+			if calls != 1 {
+				t.Errorf("expected 1 call, got %d", calls)
+			}
+		})
+	}
+}
+
+func TestSendTimeout(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	w := &Webhook{Client: srv.Client(), URL: srv.URL, Timeout: 10 * time.Millisecond}
+	if err := w.Send(context.TODO(), []byte(`{}`)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Send() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
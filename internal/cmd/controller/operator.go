@@ -94,9 +94,14 @@ func start(
 	store := manifest.NewStore(mgr.GetClient())
 	builder := target.New(mgr.GetClient(), mgr.GetAPIReader())
 
+	var shardIDSuffix string
+	if shardID != "" {
+		shardIDSuffix = fmt.Sprintf("-%s", shardID)
+	}
 	if err = (&reconciler.ClusterReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor(fmt.Sprintf("fleet-cluster-ctrl%s", shardIDSuffix)),
 
 		Query:   builder,
 		ShardID: shardID,
@@ -107,10 +112,6 @@ func start(
 		return err
 	}
 
-	var shardIDSuffix string
-	if shardID != "" {
-		shardIDSuffix = fmt.Sprintf("-%s", shardID)
-	}
 	if err = (&reconciler.BundleReconciler{
 		Client:   mgr.GetClient(),
 		Scheme:   mgr.GetScheme(),
@@ -184,6 +185,18 @@ func start(
 		}
 	}
 
+	if err = (&reconciler.NotificationReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor(fmt.Sprintf("fleet-notification-ctrl%s", shardIDSuffix)),
+		ShardID:  shardID,
+
+		Workers: workersOpts.Notification,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Notification")
+		return err
+	}
+
 	//+kubebuilder:scaffold:builder
 
 	if err := reconciler.Load(ctx, mgr.GetAPIReader(), systemNamespace); err != nil {
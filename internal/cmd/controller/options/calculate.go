@@ -61,6 +61,9 @@ func Merge(base, custom fleet.BundleDeploymentOptions) fleet.BundleDeploymentOpt
 		if custom.Helm.ValuesFrom != nil {
 			result.Helm.ValuesFrom = append(result.Helm.ValuesFrom, custom.Helm.ValuesFrom...)
 		}
+		if custom.Helm.EncryptedValues != nil {
+			result.Helm.EncryptedValues = append(result.Helm.EncryptedValues, custom.Helm.EncryptedValues...)
+		}
 		if custom.Helm.Repo != "" {
 			result.Helm.Repo = custom.Helm.Repo
 		}
@@ -93,6 +96,7 @@ func Merge(base, custom fleet.BundleDeploymentOptions) fleet.BundleDeploymentOpt
 			result.Diff = &fleet.DiffOptions{}
 		}
 		result.Diff.ComparePatches = append(result.Diff.ComparePatches, custom.Diff.ComparePatches...)
+		result.Diff.IgnoreManagers = append(result.Diff.IgnoreManagers, custom.Diff.IgnoreManagers...)
 	}
 	if custom.YAML != nil {
 		if result.YAML == nil {
@@ -104,9 +108,21 @@ func Merge(base, custom fleet.BundleDeploymentOptions) fleet.BundleDeploymentOpt
 		result.ForceSyncGeneration = custom.ForceSyncGeneration
 	}
 	result.KeepResources = result.KeepResources || custom.KeepResources
+	if custom.DryRun != nil {
+		result.DryRun = custom.DryRun
+	}
+	if custom.ServerSideApply != nil {
+		result.ServerSideApply = custom.ServerSideApply
+	}
 	if custom.CorrectDrift != nil {
 		result.CorrectDrift = custom.CorrectDrift
 	}
+	if custom.Decryption != nil {
+		result.Decryption = custom.Decryption
+	}
+	// Health checks are evaluated in order and the last matching check is
+	// used, so customizations take precedence.
+	result.HealthChecks = append(result.HealthChecks, custom.HealthChecks...)
 
 	return result
 }
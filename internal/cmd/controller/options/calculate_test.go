@@ -0,0 +1,37 @@
+package options
+
+import (
+	"reflect"
+	"testing"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"k8s.io/utils/ptr"
+)
+
+func TestMergeSwitches(t *testing.T) {
+	tests := map[string]struct {
+		base   *bool
+		custom *bool
+		want   *bool
+	}{
+		"unset":                      {},
+		"kept without customization": {base: ptr.To(true), want: ptr.To(true)},
+		"enabled by customization":   {custom: ptr.To(true), want: ptr.To(true)},
+		"disabled by customization":  {base: ptr.To(true), custom: ptr.To(false), want: ptr.To(false)},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := Merge(
+				fleet.BundleDeploymentOptions{DryRun: tt.base, ServerSideApply: tt.base},
+				fleet.BundleDeploymentOptions{DryRun: tt.custom, ServerSideApply: tt.custom},
+			)
+			if !reflect.DeepEqual(got.DryRun, tt.want) {
+				t.Errorf("Merge() DryRun = %v, want %v", ptr.Deref(got.DryRun, false), ptr.Deref(tt.want, false))
+			}
+			if !reflect.DeepEqual(got.ServerSideApply, tt.want) {
+				t.Errorf("Merge() ServerSideApply = %v, want %v", ptr.Deref(got.ServerSideApply, false), ptr.Deref(tt.want, false))
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"reflect"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
@@ -19,6 +20,7 @@ import (
 	"github.com/rancher/fleet/internal/cmd/controller/finalize"
 	"github.com/rancher/fleet/internal/cmd/controller/summary"
 	"github.com/rancher/fleet/internal/cmd/controller/target"
+	"github.com/rancher/fleet/internal/dependson"
 	"github.com/rancher/fleet/internal/experimental"
 	"github.com/rancher/fleet/internal/helmvalues"
 	"github.com/rancher/fleet/internal/manifest"
@@ -28,6 +30,7 @@ import (
 	"github.com/rancher/fleet/pkg/durations"
 	fleetevent "github.com/rancher/fleet/pkg/event"
 	"github.com/rancher/fleet/pkg/sharding"
+	"github.com/rancher/wrangler/v3/pkg/condition"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -65,6 +68,7 @@ type Store interface {
 
 type TargetBuilder interface {
 	Targets(ctx context.Context, bundle *fleet.Bundle, manifestID string) ([]*target.Target, error)
+	Overlaps(ctx context.Context, bundle *fleet.Bundle, targets []*target.Target) ([]target.Overlap, error)
 }
 
 // BundleReconciler reconciles a Bundle object
@@ -79,10 +83,23 @@ type BundleReconciler struct {
 	ShardID string
 
 	Workers int
+
+	// analyzer runs the analyses of partitions in the background.
+	analyzer target.Analyzer
+
+	// overlaps are the overlaps of each bundle, which events were
+	// recorded for.
+	overlapsMu sync.Mutex
+	overlaps   map[types.NamespacedName]sets.Set[string]
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *BundleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// analyses run in the background with the context of the manager
+	if err := mgr.Add(&r.analyzer); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&fleet.Bundle{},
 			builder.WithPredicates(
@@ -191,6 +208,24 @@ func (r *BundleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return r.computeResult(ctx, logger, bundleOrig, bundle, "failed to remove display name label", err)
 	}
 
+	// Bundles in a dependency cycle would wait for each other forever, so
+	// no bundle deployments are created or updated for them. The cycle might
+	// be resolved by changing any bundle of the cycle, so check again later.
+	cycle, err := dependson.FindCycle(ctx, r.Client, bundle)
+	if err != nil {
+		return r.computeResult(ctx, logger, bundleOrig, bundle, "failed to resolve bundle dependencies", err)
+	}
+	if updateDependencyCycleCondition(&bundle.Status, cycle) {
+		err := dependson.CycleError(cycle)
+		logger.Info("Bundle is part of a dependency cycle, skipping bundle deployments", "cycle", cycle)
+		SetCondition(string(fleet.Ready), &bundle.Status, err)
+		if err := r.updateStatus(ctx, bundleOrig, bundle); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		return ctrl.Result{RequeueAfter: durations.DependencyCycleRetry}, nil
+	}
+
 	logger.V(1).Info(
 		"Reconciling bundle, checking targets, calculating changes, building objects",
 		"generation",
@@ -271,6 +306,10 @@ func (r *BundleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 			)
 	}
 
+	// warn about resources, which other bundles already deployed to the
+	// targeted clusters
+	r.warnOverlaps(ctx, logger, bundle, matchedTargets)
+
 	if (!contentsInOCI && !contentsInHelmChart) && len(matchedTargets) > 0 {
 		// when not using the OCI registry or helm chart we need to create a contents resource
 		// so the BundleDeployments are able to access the contents to be deployed.
@@ -296,11 +335,25 @@ func (r *BundleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 	}
 
 	// this will add the defaults for a new bundledeployment. It propagates stagedOptions to options.
-	if err := target.UpdatePartitions(&bundle.Status, matchedTargets); err != nil {
+	// The promotion gates hold the rollout at partitions which are still baking or fail their analysis.
+	promotion := &target.Promotion{
+		Now: time.Now,
+		Analyze: func(partition string, analysis *fleet.PartitionAnalysis) error {
+			return r.analyzer.Analyze(analysisKey(bundle, partition), analysis)
+		},
+	}
+	if err := target.UpdatePartitions(&bundle.Status, matchedTargets, promotion); err != nil {
 		err = fmt.Errorf("failed to update partitions: %w", err)
 
 		return ctrl.Result{}, r.updateErrorStatus(ctx, bundleOrig, bundle, err)
 	}
+	// forget the analyses of removed partitions
+	partitions := make([]string, 0, len(bundle.Status.PartitionStatus))
+	for _, p := range bundle.Status.PartitionStatus {
+		partitions = append(partitions, analysisKey(bundle, p.Name))
+	}
+	r.analyzer.Forget(analysisKey(bundle, ""), partitions...)
+	updateRolledBackCondition(&bundle.Status)
 
 	if contentsInOCI {
 		url, err := r.getOCIReference(ctx, bundle)
@@ -351,6 +404,15 @@ func (r *BundleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 
 			return ctrl.Result{}, r.updateErrorStatus(ctx, bundleOrig, bundle, err)
 		}
+		rollbackOptions, err := helmvalues.ExtractRollbackOptions(bd)
+		if err != nil {
+			err := fmt.Errorf("failed to extract Helm rollback options for secret creation: %w", err)
+
+			return ctrl.Result{}, r.updateErrorStatus(ctx, bundleOrig, bundle, err)
+		}
+		if len(rollbackOptions) > 0 {
+			h = helmvalues.HashOptions(options, stagedOptions, rollbackOptions)
+		}
 		// We need a checksum to trigger on value change, rely on later code in
 		// the reconciler to update the status
 		bd.Spec.ValuesHash = h
@@ -378,7 +440,7 @@ func (r *BundleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		bundleDeploymentUIDs.Insert(bd.UID)
 
 		if bd.Spec.ValuesHash != "" {
-			if err := r.createOptionsSecret(ctx, bd, options, stagedOptions); err != nil {
+			if err := r.createOptionsSecret(ctx, bd, options, stagedOptions, rollbackOptions); err != nil {
 				return r.computeResult(ctx, logger, bundleOrig, bundle, "failed to create options secret", err)
 			}
 		} else {
@@ -413,7 +475,8 @@ func (r *BundleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, errutil.NewAggregate(merr)
 	}
 
-	return ctrl.Result{}, errutil.NewAggregate(merr)
+	// re-evaluate partitions held by a bake duration or a failed analysis
+	return ctrl.Result{RequeueAfter: promotion.RequeueAfter}, errutil.NewAggregate(merr)
 }
 
 // handleDelete runs cleanup for resources associated to a Bundle, finally removing the finalizer to unblock the deletion of the object from kubernetes.
@@ -440,6 +503,8 @@ func (r *BundleReconciler) handleDelete(ctx context.Context, logger logr.Logger,
 	}
 
 	metrics.BundleCollector.Delete(req.Name, req.Namespace)
+	r.forgetOverlaps(bundle)
+	r.analyzer.Forget(analysisKey(bundle, ""))
 	controllerutil.RemoveFinalizer(bundle, finalize.BundleFinalizer)
 	if err := r.Update(ctx, bundle); err != nil {
 		return ctrl.Result{}, err
@@ -453,6 +518,58 @@ func (r *BundleReconciler) handleDelete(ctx context.Context, logger logr.Logger,
 	return ctrl.Result{}, err
 }
 
+// warnOverlaps records an event for each new overlap of the bundle's
+// resources with the resources of other bundles. Overlaps are found in the
+// resources reported by the agents and in the Conflict condition of bundle
+// deployments, which the agents refused to deploy.
+func (r *BundleReconciler) warnOverlaps(ctx context.Context, logger logr.Logger, bundle *fleet.Bundle, targets []*target.Target) {
+	overlaps, err := r.Builder.Overlaps(ctx, bundle, targets)
+	if err != nil {
+		logger.V(1).Info("Failed to check bundle resources for overlaps", "error", err)
+	}
+	current := sets.New[string]()
+	for _, overlap := range overlaps {
+		current.Insert(overlap.String())
+	}
+	conflict := condition.Cond(fleet.BundleDeploymentConditionConflict)
+	for _, t := range targets {
+		if t.Deployment != nil && conflict.IsTrue(t.Deployment) {
+			current.Insert(fmt.Sprintf("resources on cluster %s/%s are owned by another bundle: %s",
+				t.Cluster.Namespace, t.Cluster.Name, conflict.GetMessage(t.Deployment)))
+		}
+	}
+
+	key := types.NamespacedName{Namespace: bundle.Namespace, Name: bundle.Name}
+	r.overlapsMu.Lock()
+	if r.overlaps == nil {
+		r.overlaps = map[types.NamespacedName]sets.Set[string]{}
+	}
+	warned := r.overlaps[key]
+	r.overlaps[key] = current
+	r.overlapsMu.Unlock()
+
+	for _, overlap := range sets.List(current) {
+		if warned.Has(overlap) {
+			continue
+		}
+		logger.Info("Bundle resource is deployed by another bundle", "overlap", overlap)
+		r.Recorder.Event(bundle, fleetevent.Warning, "ResourceOverlap", overlap)
+	}
+}
+
+// forgetOverlaps removes the overlaps recorded for a deleted bundle.
+func (r *BundleReconciler) forgetOverlaps(bundle *fleet.Bundle) {
+	r.overlapsMu.Lock()
+	defer r.overlapsMu.Unlock()
+	delete(r.overlaps, types.NamespacedName{Namespace: bundle.Namespace, Name: bundle.Name})
+}
+
+// analysisKey identifies the analysis of a bundle's partition. Without a
+// partition, it is the prefix of the keys of all the bundle's analyses.
+func analysisKey(bundle *fleet.Bundle, partition string) string {
+	return bundle.Namespace + "/" + bundle.Name + "/" + partition
+}
+
 // ensureFinalizer adds a finalizer to a recently created bundle.
 func (r *BundleReconciler) ensureFinalizer(ctx context.Context, bundle *fleet.Bundle) error {
 	if controllerutil.ContainsFinalizer(bundle, finalize.BundleFinalizer) {
@@ -491,6 +608,12 @@ func (r *BundleReconciler) createBundleDeployment(
 ) (*fleet.BundleDeployment, error) {
 	logger := l.WithValues("deploymentID", bd.Spec.DeploymentID)
 
+	// A rolled back bundle deployment keeps using the content of its last
+	// known-good deployment.
+	if bd.Spec.DeploymentID != "" && bd.Spec.DeploymentID == bd.Spec.RollbackDeploymentID {
+		manifestID, _ = kv.Split(bd.Spec.DeploymentID, ":")
+	}
+
 	// When content resources are stored in etcd, we need to add finalizers.
 	if !contentsInOCI && !contentsInHelmChart {
 		content := &fleet.Content{}
@@ -513,13 +636,24 @@ func (r *BundleReconciler) createBundleDeployment(
 		// latest version of the bundle points to a different deployment ID.
 		// An empty value for bd.Spec.DeploymentID means that we are deploying the first version of this
 		// bundle, hence there are no Contents left over to purge.
+		// Content which is kept for an automatic rollback is only purged once
+		// it is replaced by a newer rollback target.
 		if (!bd.Spec.OCIContents || !contentsInHelmChart) &&
 			bd.Spec.DeploymentID != "" &&
-			bd.Spec.DeploymentID != updated.Spec.DeploymentID {
+			bd.Spec.DeploymentID != updated.Spec.DeploymentID &&
+			bd.Spec.DeploymentID != updated.Spec.RollbackDeploymentID {
 			if err := finalize.PurgeContent(ctx, r.Client, bd.Name, bd.Spec.DeploymentID); err != nil {
 				logger.Error(err, "Reconcile failed to purge old content resource")
 			}
 		}
+		if (!bd.Spec.OCIContents || !contentsInHelmChart) &&
+			bd.Spec.RollbackDeploymentID != "" &&
+			bd.Spec.RollbackDeploymentID != updated.Spec.RollbackDeploymentID &&
+			bd.Spec.RollbackDeploymentID != updated.Spec.DeploymentID {
+			if err := finalize.PurgeContent(ctx, r.Client, bd.Name, bd.Spec.RollbackDeploymentID); err != nil {
+				logger.Error(err, "Reconcile failed to purge old rollback content resource")
+			}
+		}
 
 		// check if there's any OCI secret that can be purged
 		if err := maybePurgeOCIReferenceSecret(ctx, r.Client, bd, updated); err != nil {
@@ -540,7 +674,7 @@ func (r *BundleReconciler) createBundleDeployment(
 	return bd, nil
 }
 
-func (r *BundleReconciler) createOptionsSecret(ctx context.Context, bd *fleet.BundleDeployment, options []byte, stagedOptions []byte) error {
+func (r *BundleReconciler) createOptionsSecret(ctx context.Context, bd *fleet.BundleDeployment, options, stagedOptions, rollbackOptions []byte) error {
 	secret := &corev1.Secret{
 		Type: fleet.SecretTypeBundleDeploymentOptions,
 		ObjectMeta: metav1.ObjectMeta{
@@ -565,6 +699,9 @@ func (r *BundleReconciler) createOptionsSecret(ctx context.Context, bd *fleet.Bu
 			helmvalues.ValuesKey:       options,
 			helmvalues.StagedValuesKey: stagedOptions,
 		}
+		if len(rollbackOptions) > 0 {
+			secret.Data[helmvalues.RollbackValuesKey] = rollbackOptions
+		}
 		return nil
 	}); err != nil {
 		return fmt.Errorf("%w: %w", fleetutil.ErrRetryable, err)
@@ -974,6 +1111,11 @@ func maybePurgeOCIReferenceSecret(ctx context.Context, c client.Client, old, new
 		return nil
 	}
 
+	// the secret is still needed for an automatic rollback
+	if old.Spec.DeploymentID == new.Spec.RollbackDeploymentID {
+		return nil
+	}
+
 	if !new.Spec.OCIContents || (old.Spec.DeploymentID != new.Spec.DeploymentID) {
 		id, _ := kv.Split(old.Spec.DeploymentID, ":")
 		var secret corev1.Secret
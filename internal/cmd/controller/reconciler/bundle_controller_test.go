@@ -256,6 +256,8 @@ func TestReconcile_TargetsBuildingError(t *testing.T) {
 	recorderMock := mocks.NewMockEventRecorder(mockCtrl)
 
 	targetBuilderMock := mocks.NewMockTargetBuilder(mockCtrl)
+
+	targetBuilderMock.EXPECT().Overlaps(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 	targetBuilderMock.EXPECT().Targets(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("something went wrong"))
 
 	r := reconciler.BundleReconciler{
@@ -336,6 +338,7 @@ func TestReconcile_StatusResetFromTargetsError(t *testing.T) {
 		},
 	}
 	targetBuilderMock := mocks.NewMockTargetBuilder(mockCtrl)
+	targetBuilderMock.EXPECT().Overlaps(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 	targetBuilderMock.EXPECT().Targets(gomock.Any(), gomock.Any(), gomock.Any()).Return(matchedTargets, nil)
 
 	storeMock := mocks.NewMockStore(mockCtrl)
@@ -418,6 +421,7 @@ func TestReconcile_ManifestStorageError(t *testing.T) {
 
 			matchedTargets := []*target.Target{{DeploymentID: "foo"}} // just needs to be non-empty
 			targetBuilderMock := mocks.NewMockTargetBuilder(mockCtrl)
+			targetBuilderMock.EXPECT().Overlaps(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 			targetBuilderMock.EXPECT().Targets(gomock.Any(), gomock.Any(), gomock.Any()).Return(matchedTargets, nil)
 
 			storeMock := mocks.NewMockStore(mockCtrl)
@@ -533,6 +537,7 @@ func TestReconcile_OptionsSecretCreationError(t *testing.T) {
 		},
 	}
 	targetBuilderMock := mocks.NewMockTargetBuilder(mockCtrl)
+	targetBuilderMock.EXPECT().Overlaps(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 	targetBuilderMock.EXPECT().Targets(gomock.Any(), gomock.Any(), gomock.Any()).Return(matchedTargets, nil)
 
 	storeMock := mocks.NewMockStore(mockCtrl)
@@ -621,6 +626,7 @@ func TestReconcile_OptionsSecretDeletionError(t *testing.T) {
 		},
 	}
 	targetBuilderMock := mocks.NewMockTargetBuilder(mockCtrl)
+	targetBuilderMock.EXPECT().Overlaps(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 	targetBuilderMock.EXPECT().Targets(gomock.Any(), gomock.Any(), gomock.Any()).Return(matchedTargets, nil)
 
 	storeMock := mocks.NewMockStore(mockCtrl)
@@ -735,6 +741,7 @@ func TestReconcile_OCIReferenceSecretResolutionError(t *testing.T) {
 				},
 			}
 			targetBuilderMock := mocks.NewMockTargetBuilder(mockCtrl)
+			targetBuilderMock.EXPECT().Overlaps(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 			targetBuilderMock.EXPECT().Targets(gomock.Any(), gomock.Any(), gomock.Any()).Return(matchedTargets, nil)
 
 			r := reconciler.BundleReconciler{
@@ -916,6 +923,7 @@ func TestReconcile_DownstreamObjectsHandlingError(t *testing.T) {
 				},
 			}
 			targetBuilderMock := mocks.NewMockTargetBuilder(mockCtrl)
+			targetBuilderMock.EXPECT().Overlaps(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 			targetBuilderMock.EXPECT().Targets(gomock.Any(), gomock.Any(), gomock.Any()).Return(matchedTargets, nil)
 
 			storeMock := mocks.NewMockStore(mockCtrl)
@@ -1029,6 +1037,7 @@ func TestReconcile_AccessSecretsHandlingError(t *testing.T) {
 		},
 	}
 	targetBuilderMock := mocks.NewMockTargetBuilder(mockCtrl)
+	targetBuilderMock.EXPECT().Overlaps(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 	targetBuilderMock.EXPECT().Targets(gomock.Any(), gomock.Any(), gomock.Any()).Return(matchedTargets, nil)
 
 	r := reconciler.BundleReconciler{
@@ -0,0 +1,92 @@
+package reconciler
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	"github.com/rancher/fleet/internal/cmd/controller/target"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"github.com/rancher/wrangler/v3/pkg/condition"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+type overlapBuilder struct {
+	overlaps []target.Overlap
+}
+
+func (b *overlapBuilder) Targets(context.Context, *fleet.Bundle, string) ([]*target.Target, error) {
+	return nil, nil
+}
+
+func (b *overlapBuilder) Overlaps(context.Context, *fleet.Bundle, []*target.Target) ([]target.Overlap, error) {
+	return b.overlaps, nil
+}
+
+var _ = Describe("Bundle overlap warnings", func() {
+	var (
+		builder  *overlapBuilder
+		recorder *record.FakeRecorder
+		r        *BundleReconciler
+		bundle   *fleet.Bundle
+		targets  []*target.Target
+	)
+
+	BeforeEach(func() {
+		builder = &overlapBuilder{}
+		recorder = record.NewFakeRecorder(10)
+		r = &BundleReconciler{Builder: builder, Recorder: recorder}
+		bundle = &fleet.Bundle{ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-default", Name: "new"}}
+		targets = []*target.Target{{
+			Cluster:    &fleet.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-default", Name: "downstream"}},
+			Deployment: &fleet.BundleDeployment{},
+		}}
+	})
+
+	warn := func() {
+		r.warnOverlaps(context.TODO(), logr.Discard(), bundle, targets)
+	}
+
+	It("warns once about conflicts reported by the agent", func() {
+		warn()
+		Expect(recorder.Events).To(BeEmpty())
+
+		// the agent refuses to deploy the new bundle
+		conflict := condition.Cond(fleet.BundleDeploymentConditionConflict)
+		conflict.SetStatusBool(targets[0].Deployment, true)
+		conflict.Message(targets[0].Deployment, "owned by bundle fleet-default/old")
+		warn()
+		Expect(recorder.Events).To(Receive(ContainSubstring("cluster fleet-default/downstream are owned by another bundle: owned by bundle fleet-default/old")))
+
+		warn()
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("warns again about overlaps, which were resolved in between", func() {
+		overlap := target.Overlap{Cluster: "fleet-default/downstream", Bundle: "fleet-default/old", Resource: "ConfigMap default/app"}
+		builder.overlaps = []target.Overlap{overlap}
+		warn()
+		Expect(recorder.Events).To(Receive(ContainSubstring(overlap.String())))
+		warn()
+		Expect(recorder.Events).To(BeEmpty())
+
+		builder.overlaps = nil
+		warn()
+		builder.overlaps = []target.Overlap{overlap}
+		warn()
+		Expect(recorder.Events).To(Receive(ContainSubstring(overlap.String())))
+	})
+
+	It("forgets deleted bundles", func() {
+		builder.overlaps = []target.Overlap{{Cluster: "fleet-default/downstream", Bundle: "fleet-default/old", Resource: "ConfigMap default/app"}}
+		warn()
+		Expect(r.overlaps).To(HaveLen(1))
+		r.forgetOverlaps(bundle)
+		Expect(r.overlaps).To(BeEmpty())
+	})
+})
@@ -2,20 +2,26 @@ package reconciler
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/rancher/fleet/internal/cmd/controller/summary"
 	"github.com/rancher/fleet/internal/cmd/controller/target"
+	"github.com/rancher/fleet/internal/dependson"
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"github.com/rancher/wrangler/v3/pkg/condition"
 )
 
 const (
 	maxNew = 50
 )
 
+// resetStatus recomputes the bundle status from allTargets. The partition
+// status is left untouched, as target.UpdatePartitions needs the previous
+// values to track bake durations.
 func resetStatus(status *fleet.BundleStatus, allTargets []*target.Target) (err error) {
 	status.MaxNew = maxNew
 	status.Summary = fleet.BundleSummary{}
-	status.PartitionStatus = nil
 	status.Unavailable = 0
 	status.NewlyCreated = 0
 	status.Summary = target.Summary(allTargets)
@@ -30,3 +36,41 @@ func updateDisplay(status *fleet.BundleStatus) {
 		status.Summary.DesiredReady)
 	status.Display.State = string(summary.GetSummaryState(status.Summary))
 }
+
+// updateRolledBackCondition reflects the last automatic rollback of the bundle
+// in the RolledBack condition. The condition is only added to bundles which
+// were rolled back at least once.
+func updateRolledBackCondition(status *fleet.BundleStatus) {
+	c := condition.Cond(fleet.BundleConditionRolledBack)
+	if status.Rollback == nil {
+		if c.GetStatus(status) != "" {
+			c.SetStatusBool(status, false)
+			c.Message(status, "")
+		}
+		return
+	}
+
+	c.SetStatusBool(status, true)
+	c.Message(status, fmt.Sprintf("rolled back partition %q, failing clusters: %s",
+		status.Rollback.Partition,
+		strings.Join(status.Rollback.Clusters, ", ")))
+}
+
+// updateDependencyCycleCondition reflects the dependency cycle, as found by
+// dependson.FindCycle, in the DependencyCycle condition. It returns true if
+// the bundle is part of a cycle. The condition is only added to bundles which
+// were part of a cycle at least once.
+func updateDependencyCycleCondition(status *fleet.BundleStatus, cycle []string) bool {
+	c := condition.Cond(fleet.BundleConditionDependencyCycle)
+	if len(cycle) == 0 {
+		if c.GetStatus(status) != "" {
+			c.SetStatusBool(status, false)
+			c.Message(status, "")
+		}
+		return false
+	}
+
+	c.SetStatusBool(status, true)
+	c.Message(status, dependson.CycleError(cycle).Error())
+	return true
+}
@@ -76,6 +76,11 @@ func (r *BundleDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Req
 			if err := finalize.PurgeContent(ctx, r.Client, bd.Name, bd.Spec.DeploymentID); err != nil {
 				return ctrl.Result{}, err
 			}
+			if bd.Spec.RollbackDeploymentID != "" {
+				if err := finalize.PurgeContent(ctx, r.Client, bd.Name, bd.Spec.RollbackDeploymentID); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
 			err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
 				err := r.Get(ctx, req.NamespacedName, bd)
 				if err != nil {
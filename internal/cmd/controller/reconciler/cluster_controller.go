@@ -12,10 +12,12 @@ import (
 
 	"github.com/rancher/fleet/internal/cmd/controller/finalize"
 	"github.com/rancher/fleet/internal/cmd/controller/summary"
+	"github.com/rancher/fleet/internal/config"
 	"github.com/rancher/fleet/internal/metrics"
 	"github.com/rancher/fleet/internal/resourcestatus"
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
 	"github.com/rancher/fleet/pkg/durations"
+	fleetevent "github.com/rancher/fleet/pkg/event"
 	"github.com/rancher/fleet/pkg/sharding"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -29,6 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	errutil "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -53,6 +56,8 @@ type ClusterReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 
+	Recorder record.EventRecorder
+
 	Query   BundleQuery
 	ShardID string
 
@@ -263,6 +268,11 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		cluster.Status.Display.State = "WaitCheckIn"
 	}
 
+	wasOffline, offlineIn := updateOfflineCondition(&cluster.Status, time.Now(), offlineThreshold(config.Get()))
+	if isOffline(cluster) {
+		cluster.Status.Display.State = "Offline"
+	}
+
 	r.setCondition(&cluster.Status, nil)
 
 	err = r.updateStatus(ctx, req.NamespacedName, cluster.Status)
@@ -270,6 +280,15 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		logger.V(1).Info("Reconcile failed final update to cluster status", "status", cluster.Status, "error", err)
 	} else {
 		metrics.ClusterCollector.Collect(ctx, cluster)
+
+		switch offline := isOffline(cluster); {
+		case offline && !wasOffline:
+			logger.Info("Cluster is offline", "lastSeen", cluster.Status.Agent.LastSeen)
+			r.Recorder.Event(cluster, fleetevent.Warning, "Offline", "Agent did not check in since "+cluster.Status.Agent.LastSeen.UTC().Format(time.RFC3339))
+		case !offline && wasOffline:
+			logger.Info("Cluster is back online", "lastSeen", cluster.Status.Agent.LastSeen)
+			r.Recorder.Event(cluster, fleetevent.Normal, "Online", "Agent checked in again")
+		}
 	}
 
 	if allReady && cluster.Status.ResourceCounts.Ready != cluster.Status.ResourceCounts.DesiredReady {
@@ -286,7 +305,9 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		}, nil
 	}
 
-	return ctrl.Result{}, err
+	// check again once the cluster would turn offline, unless the agent
+	// checks in before
+	return ctrl.Result{RequeueAfter: offlineIn}, err
 }
 
 // setCondition sets the condition and updates the timestamp, if the condition changed
@@ -7,6 +7,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/rancher/fleet/internal/cmd/controller/finalize"
+	"github.com/rancher/fleet/internal/config"
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -42,6 +43,7 @@ var _ = Describe("ClusterReconciler", func() {
 		sch = scheme.Scheme
 		Expect(fleet.AddToScheme(sch)).To(Succeed())
 		Expect(corev1.AddToScheme(sch)).To(Succeed())
+		config.Set(&config.Config{})
 
 		cluster = &fleet.Cluster{
 			ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,63 @@
+package reconciler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rancher/fleet/internal/config"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	"github.com/rancher/fleet/pkg/durations"
+
+	"github.com/rancher/wrangler/v3/pkg/condition"
+)
+
+// defaultClusterOfflineCheckinMultiplier is the number of missed agent
+// check-ins, after which a cluster is marked offline.
+const defaultClusterOfflineCheckinMultiplier = 3
+
+// offlineThreshold returns how long an agent may not check in, before its
+// cluster is marked offline.
+func offlineThreshold(cfg *config.Config) time.Duration {
+	interval := cfg.AgentCheckinInterval.Duration
+	if interval <= 0 {
+		interval = durations.DefaultClusterCheckInterval
+	}
+	multiplier := cfg.ClusterOfflineCheckinMultiplier
+	if multiplier <= 0 {
+		multiplier = defaultClusterOfflineCheckinMultiplier
+	}
+	return time.Duration(multiplier) * interval
+}
+
+// updateOfflineCondition sets the Offline condition, depending on the last
+// check-in of the agent. It returns whether the cluster was offline before
+// and the duration after which the cluster turns offline, if it is online.
+// Clusters, whose agent never checked in, are waiting for their check-in and
+// are not offline.
+func updateOfflineCondition(status *fleet.ClusterStatus, now time.Time, threshold time.Duration) (wasOffline bool, offlineIn time.Duration) {
+	c := condition.Cond(fleet.ClusterConditionOffline)
+	wasOffline = c.IsTrue(status)
+
+	lastSeen := status.Agent.LastSeen
+	if lastSeen.IsZero() {
+		return wasOffline, 0
+	}
+
+	offlineIn = lastSeen.Add(threshold).Sub(now)
+	if offlineIn > 0 {
+		if c.GetStatus(status) != "" {
+			c.SetStatusBool(status, false)
+			c.Message(status, "")
+		}
+		return wasOffline, offlineIn
+	}
+
+	c.SetStatusBool(status, true)
+	c.Message(status, fmt.Sprintf("agent did not check in since %s", lastSeen.UTC().Format(time.RFC3339)))
+	return wasOffline, 0
+}
+
+// isOffline returns true if the cluster is marked offline.
+func isOffline(cluster *fleet.Cluster) bool {
+	return condition.Cond(fleet.ClusterConditionOffline).IsTrue(&cluster.Status)
+}
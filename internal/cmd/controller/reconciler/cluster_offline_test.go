@@ -0,0 +1,69 @@
+package reconciler
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/rancher/fleet/internal/config"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"github.com/rancher/wrangler/v3/pkg/condition"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Cluster offline detection", func() {
+	var (
+		now    time.Time
+		status *fleet.ClusterStatus
+		cond   = condition.Cond(fleet.ClusterConditionOffline)
+	)
+
+	BeforeEach(func() {
+		now = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		status = &fleet.ClusterStatus{}
+	})
+
+	It("uses a multiple of the agent check-in interval", func() {
+		Expect(offlineThreshold(&config.Config{})).To(Equal(45 * time.Minute))
+		Expect(offlineThreshold(&config.Config{
+			AgentCheckinInterval:            metav1.Duration{Duration: time.Minute},
+			ClusterOfflineCheckinMultiplier: 5,
+		})).To(Equal(5 * time.Minute))
+	})
+
+	It("does not mark clusters offline, which never checked in", func() {
+		wasOffline, offlineIn := updateOfflineCondition(status, now, time.Minute)
+		Expect(wasOffline).To(BeFalse())
+		Expect(offlineIn).To(BeZero())
+		Expect(status.Conditions).To(BeEmpty())
+	})
+
+	It("requeues online clusters until they would turn offline", func() {
+		status.Agent.LastSeen = metav1.NewTime(now.Add(-20 * time.Second))
+
+		wasOffline, offlineIn := updateOfflineCondition(status, now, time.Minute)
+		Expect(wasOffline).To(BeFalse())
+		Expect(offlineIn).To(Equal(40 * time.Second))
+		Expect(status.Conditions).To(BeEmpty())
+	})
+
+	It("marks clusters offline and back online", func() {
+		status.Agent.LastSeen = metav1.NewTime(now.Add(-2 * time.Minute))
+
+		wasOffline, offlineIn := updateOfflineCondition(status, now, time.Minute)
+		Expect(wasOffline).To(BeFalse())
+		Expect(offlineIn).To(BeZero())
+		Expect(cond.IsTrue(status)).To(BeTrue())
+		Expect(cond.GetMessage(status)).To(Equal("agent did not check in since 2025-01-01T11:58:00Z"))
+
+		status.Agent.LastSeen = metav1.NewTime(now)
+		wasOffline, offlineIn = updateOfflineCondition(status, now, time.Minute)
+		Expect(wasOffline).To(BeTrue())
+		Expect(offlineIn).To(Equal(time.Minute))
+		Expect(cond.IsFalse(status)).To(BeTrue())
+		Expect(cond.GetMessage(status)).To(BeEmpty())
+	})
+})
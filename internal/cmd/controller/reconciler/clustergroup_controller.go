@@ -111,6 +111,8 @@ func (r *ClusterGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	group.Status.ClusterCount = 0
 	group.Status.NonReadyClusterCount = 0
 	group.Status.NonReadyClusters = nil
+	group.Status.OfflineClusterCount = 0
+	group.Status.OfflineClusters = nil
 
 	sort.Slice(clusters.Items, func(i, j int) bool {
 		return clusters.Items[i].Name < clusters.Items[j].Name
@@ -126,6 +128,12 @@ func (r *ClusterGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request
 				group.Status.NonReadyClusters = append(group.Status.NonReadyClusters, cluster.Name)
 			}
 		}
+		if isOffline(&cluster) {
+			group.Status.OfflineClusterCount++
+			if len(group.Status.OfflineClusters) < MaxReportedNonReadyClusters {
+				group.Status.OfflineClusters = append(group.Status.OfflineClusters, cluster.Name)
+			}
+		}
 	}
 
 	summary.SetReadyConditions(&group.Status, "Bundle", group.Status.Summary)
@@ -87,11 +87,12 @@ func (r *ImageScanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	tagScanKey := imagescan.TagScanKey(req.Namespace, req.Name)
 	_ = r.Scheduler.DeleteJob(tagScanKey)
 
+	// Scans requested by a registry webhook start immediately.
 	err = r.Scheduler.ScheduleJob(
 		quartz.NewJobDetail(
 			imagescan.NewTagScanJob(r.Client, req.Namespace, req.Name),
 			tagScanKey),
-		quartz.NewSimpleTrigger(interval.Duration),
+		imagescan.NewTagScanTrigger(image, interval.Duration),
 	)
 	if err != nil {
 		logger.Error(err, "Failed to schedule imagescan tagscan job")
@@ -105,12 +106,19 @@ func (r *ImageScanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	}
 
 	gitCommitKey := imagescan.GitCommitKey(gitrepo.Namespace, gitrepo.Name)
+	trigger := quartz.NewSimpleTrigger(interval.Duration)
+	// Keep an unchanged job, so frequent scan requests do not keep
+	// postponing the commit.
+	if job, err := r.Scheduler.GetScheduledJob(gitCommitKey); err == nil &&
+		job.Trigger().Description() == trigger.Description() {
+		return ctrl.Result{}, nil
+	}
 	_ = r.Scheduler.DeleteJob(gitCommitKey)
 	err = r.Scheduler.ScheduleJob(
 		quartz.NewJobDetail(
 			imagescan.NewGitCommitJob(r.Client, gitrepo.Namespace, gitrepo.Name),
 			gitCommitKey),
-		quartz.NewSimpleTrigger(interval.Duration),
+		trigger,
 	)
 	if err != nil {
 		logger.Error(err, "Failed to schedule gitrepo gitcommit job")
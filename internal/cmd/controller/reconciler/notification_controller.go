@@ -0,0 +1,368 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/rancher/fleet/internal/cmd/controller/notification"
+	"github.com/rancher/fleet/internal/cmd/controller/summary"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	fleetevent "github.com/rancher/fleet/pkg/event"
+	"github.com/rancher/fleet/pkg/sharding"
+	"github.com/rancher/wrangler/v3/pkg/condition"
+	"github.com/rancher/wrangler/v3/pkg/genericcondition"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	notificationURLKey   = "url"
+	notificationTokenKey = "token"
+)
+
+// NotificationReconciler sends notifications for state transitions of the
+// Fleet resources selected by Notifications.
+type NotificationReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	ShardID  string
+	Workers  int
+
+	// HTTPClient is used to deliver notifications, defaults to
+	// http.DefaultClient. Each delivery is limited by
+	// notification.DefaultTimeout.
+	HTTPClient *http.Client
+}
+
+//+kubebuilder:rbac:groups=fleet.cattle.io,resources=notifications,verbs=get;list;watch
+//+kubebuilder:rbac:groups=fleet.cattle.io,resources=notifications/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=fleet.cattle.io,resources=gitrepos;helmops;bundles;clusters,verbs=get;list;watch
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NotificationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&fleet.Notification{},
+			builder.WithPredicates(
+				predicate.GenerationChangedPredicate{},
+				sharding.FilterByShardID(r.ShardID),
+			),
+		)
+
+	for kind, obj := range map[string]client.Object{
+		fleet.NotificationKindGitRepo: &fleet.GitRepo{},
+		fleet.NotificationKindHelmOp:  &fleet.HelmOp{},
+		fleet.NotificationKindBundle:  &fleet.Bundle{},
+		fleet.NotificationKindCluster: &fleet.Cluster{},
+	} {
+		b = b.Watches(
+			obj,
+			handler.EnqueueRequestsFromMapFunc(r.mapToNotifications(kind)),
+			builder.WithPredicates(resourceStateChangedPredicate()),
+		)
+	}
+
+	return b.
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.Workers}).
+		Complete(r)
+}
+
+// mapToNotifications returns a map func, which enqueues all Notifications in
+// the namespace of an object, which select resources of the kind.
+func (r *NotificationReconciler) mapToNotifications(kind string) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []ctrl.Request {
+		list := &fleet.NotificationList{}
+		if err := r.List(ctx, list, client.InNamespace(obj.GetNamespace())); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to list notifications", "namespace", obj.GetNamespace())
+			return nil
+		}
+
+		var requests []ctrl.Request
+		for _, n := range list.Items {
+			if !slices.ContainsFunc(n.Spec.Resources, func(res fleet.NotificationResource) bool {
+				return res.Kind == kind
+			}) {
+				continue
+			}
+			requests = append(requests, ctrl.Request{NamespacedName: types.NamespacedName{
+				Namespace: n.Namespace,
+				Name:      n.Name,
+			}})
+		}
+		return requests
+	}
+}
+
+// resourceStateChangedPredicate filters updates of watched resources, which
+// do not change their state.
+func resourceStateChangedPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldSummary, _, ok := resourceStatus(e.ObjectOld)
+			if !ok {
+				return false
+			}
+			newSummary, _, ok := resourceStatus(e.ObjectNew)
+			if !ok {
+				return false
+			}
+			return notification.State(oldSummary) != notification.State(newSummary)
+		},
+	}
+}
+
+// resourceStatus returns the summary and conditions of a resource, which can
+// be selected by Notifications.
+func resourceStatus(obj runtime.Object) (fleet.BundleSummary, []genericcondition.GenericCondition, bool) {
+	switch o := obj.(type) {
+	case *fleet.GitRepo:
+		return o.Status.Summary, o.Status.Conditions, true
+	case *fleet.HelmOp:
+		return o.Status.Summary, o.Status.Conditions, true
+	case *fleet.Bundle:
+		return o.Status.Summary, o.Status.Conditions, true
+	case *fleet.Cluster:
+		return o.Status.Summary, o.Status.Conditions, true
+	}
+	return fleet.BundleSummary{}, nil, false
+}
+
+// Reconcile computes the states of the resources selected by a Notification
+// and delivers a notification for each resource, whose state changed into
+// one of the notified states.
+func (r *NotificationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithName("notification")
+	ctx = log.IntoContext(ctx, logger)
+
+	n := &fleet.Notification{}
+	if err := r.Get(ctx, req.NamespacedName, n); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !n.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+	orig := n.DeepCopy()
+
+	events, err := r.observe(ctx, n)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	states := map[string]fleet.BundleState{}
+	for key, e := range events {
+		states[key] = e.State
+	}
+
+	var errs []error
+	if !n.Spec.Suspend {
+		errs = r.notify(ctx, n, events, states)
+	}
+
+	n.Status.ResourceStates = states
+	n.Status.ObservedGeneration = n.Generation
+	condition.Cond(fleet.Ready).SetError(&n.Status, "", errors.Join(errs...))
+
+	if err := r.Status().Patch(ctx, n, client.MergeFrom(orig)); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// failed deliveries are requeued with the controller's rate limiter,
+	// their previous state is kept, so they are sent again
+	return ctrl.Result{}, errors.Join(errs...)
+}
+
+// notify delivers notifications for events, whose state changed into one of
+// the notified states. If the delivery fails, the previous state is restored
+// in states, so the notification is retried.
+func (r *NotificationReconciler) notify(ctx context.Context, n *fleet.Notification, events map[string]notification.Event, states map[string]fleet.BundleState) []error {
+	notified := n.Spec.States
+	if len(notified) == 0 {
+		notified = notification.DefaultStates
+	}
+
+	// deliver in a stable order
+	var keys []string
+	for key, e := range events {
+		if n.Status.ResourceStates[key] != e.State && slices.Contains(notified, e.State) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Strings(keys)
+
+	webhook, err := r.webhook(ctx, n)
+	if err != nil {
+		for _, key := range keys {
+			restoreState(n, states, key)
+		}
+		return []error{err}
+	}
+
+	var errs []error
+	for i, key := range keys {
+		e := events[key]
+		e.PreviousState = n.Status.ResourceStates[key]
+
+		body, err := notification.Render(n.Spec.Template, e)
+		if err != nil {
+			// the template is invalid for all events
+			for _, key := range keys[i:] {
+				restoreState(n, states, key)
+			}
+			return append(errs, err)
+		}
+		if err := webhook.Send(ctx, body); err != nil {
+			restoreState(n, states, key)
+			r.Recorder.Event(n, fleetevent.Warning, "FailedToSendNotification", fmt.Sprintf("%s: %v", key, err))
+			errs = append(errs, fmt.Errorf("failed to send notification for %s: %w", key, err))
+			continue
+		}
+
+		n.Status.LastSentTime = &metav1.Time{Time: time.Now().UTC()}
+		log.FromContext(ctx).V(1).Info("Sent notification", "resource", key, "state", e.State, "previousState", e.PreviousState)
+	}
+
+	return errs
+}
+
+// restoreState resets the state of the resource to the last notified one,
+// so the notification is sent again.
+func restoreState(n *fleet.Notification, states map[string]fleet.BundleState, key string) {
+	if prev, ok := n.Status.ResourceStates[key]; ok {
+		states[key] = prev
+	} else {
+		delete(states, key)
+	}
+}
+
+// webhook returns the webhook of the notification, with the URL and token
+// from its secret.
+func (r *NotificationReconciler) webhook(ctx context.Context, n *fleet.Notification) (*notification.Webhook, error) {
+	w := &notification.Webhook{
+		Client:      r.HTTPClient,
+		URL:         n.Spec.Webhook.URL,
+		Headers:     n.Spec.Webhook.Headers,
+		ContentType: n.Spec.Webhook.ContentType,
+	}
+
+	if n.Spec.Webhook.SecretName != "" {
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: n.Namespace, Name: n.Spec.Webhook.SecretName}, secret); err != nil {
+			return nil, fmt.Errorf("failed to get webhook secret: %w", err)
+		}
+		if url, ok := secret.Data[notificationURLKey]; ok {
+			w.URL = string(url)
+		}
+		w.Token = string(secret.Data[notificationTokenKey])
+	}
+
+	if w.URL == "" {
+		return nil, errors.New("webhook URL is empty")
+	}
+
+	return w, nil
+}
+
+// observe returns the current state of each resource selected by the
+// notification, keyed by "<kind>/<name>".
+func (r *NotificationReconciler) observe(ctx context.Context, n *fleet.Notification) (map[string]notification.Event, error) {
+	events := map[string]notification.Event{}
+	for _, res := range n.Spec.Resources {
+		objs, err := r.listResources(ctx, n.Namespace, res)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			sum, conds, _ := resourceStatus(obj)
+			events[res.Kind+"/"+obj.GetName()] = notification.Event{
+				Kind:      res.Kind,
+				Namespace: obj.GetNamespace(),
+				Name:      obj.GetName(),
+				State:     notification.State(sum),
+				Message:   summary.MessageFromCondition(string(fleet.Ready), conds),
+				Summary:   sum,
+			}
+		}
+	}
+	return events, nil
+}
+
+func (r *NotificationReconciler) listResources(ctx context.Context, namespace string, res fleet.NotificationResource) ([]client.Object, error) {
+	selector := labels.Everything()
+	if res.Selector != nil {
+		var err error
+		selector, err = metav1.LabelSelectorAsSelector(res.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector for %s: %w", res.Kind, err)
+		}
+	}
+	opts := []client.ListOption{client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}}
+
+	var objs []client.Object
+	switch res.Kind {
+	case fleet.NotificationKindGitRepo:
+		list := &fleet.GitRepoList{}
+		if err := r.List(ctx, list, opts...); err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objs = append(objs, &list.Items[i])
+		}
+	case fleet.NotificationKindHelmOp:
+		list := &fleet.HelmOpList{}
+		if err := r.List(ctx, list, opts...); err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objs = append(objs, &list.Items[i])
+		}
+	case fleet.NotificationKindBundle:
+		list := &fleet.BundleList{}
+		if err := r.List(ctx, list, opts...); err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objs = append(objs, &list.Items[i])
+		}
+	case fleet.NotificationKindCluster:
+		list := &fleet.ClusterList{}
+		if err := r.List(ctx, list, opts...); err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objs = append(objs, &list.Items[i])
+		}
+	default:
+		return nil, fmt.Errorf("unsupported kind %q", res.Kind)
+	}
+
+	if res.Name == "" {
+		return objs, nil
+	}
+	for _, obj := range objs {
+		if obj.GetName() == res.Name {
+			return []client.Object{obj}, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,163 @@
+package reconciler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher/fleet/internal/cmd/controller/notification"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("NotificationReconciler", func() {
+	var (
+		ctx        context.Context
+		reconciler *NotificationReconciler
+		k8sclient  client.Client
+		server     *httptest.Server
+		mu         sync.Mutex
+		received   []notification.Event
+		status     int
+		gitrepo    *fleet.GitRepo
+		req        reconcile.Request
+		oncall     *fleet.Notification
+	)
+
+	setState := func(summary fleet.BundleSummary) {
+		repo := &fleet.GitRepo{}
+		Expect(k8sclient.Get(ctx, client.ObjectKeyFromObject(gitrepo), repo)).To(Succeed())
+		repo.Status.Summary = summary
+		Expect(k8sclient.Status().Update(ctx, repo)).To(Succeed())
+	}
+
+	getNotification := func() *fleet.Notification {
+		n := &fleet.Notification{}
+		Expect(k8sclient.Get(ctx, req.NamespacedName, n)).To(Succeed())
+		return n
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		Expect(fleet.AddToScheme(scheme.Scheme)).To(Succeed())
+
+		received = nil
+		status = http.StatusOK
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			if status != http.StatusOK {
+				w.WriteHeader(status)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			e := notification.Event{}
+			Expect(json.Unmarshal(body, &e)).To(Succeed())
+			received = append(received, e)
+		}))
+
+		gitrepo = &fleet.GitRepo{
+			ObjectMeta: metav1.ObjectMeta{Name: "apps", Namespace: "default", Labels: map[string]string{"team": "a"}},
+		}
+		oncall = &fleet.Notification{
+			ObjectMeta: metav1.ObjectMeta{Name: "oncall", Namespace: "default"},
+			Spec: fleet.NotificationSpec{
+				Resources: []fleet.NotificationResource{{
+					Kind:     fleet.NotificationKindGitRepo,
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+				}},
+				Webhook: fleet.NotificationWebhook{URL: server.URL},
+			},
+		}
+		req = reconcile.Request{NamespacedName: types.NamespacedName{Name: "oncall", Namespace: "default"}}
+
+		k8sclient = fake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithObjects(gitrepo, oncall).
+			WithStatusSubresource(&fleet.GitRepo{}, &fleet.Notification{}).
+			Build()
+
+		reconciler = &NotificationReconciler{
+			Client:   k8sclient,
+			Scheme:   scheme.Scheme,
+			Recorder: record.NewFakeRecorder(10),
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("notifies each transition into a notified state once", func() {
+		_, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(received).To(BeEmpty())
+		Expect(getNotification().Status.ResourceStates).To(Equal(map[string]fleet.BundleState{"GitRepo/apps": fleet.Ready}))
+
+		setState(fleet.BundleSummary{DesiredReady: 2, Ready: 1, ErrApplied: 1})
+		_, err = reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(received).To(HaveLen(1))
+		Expect(received[0].Name).To(Equal("apps"))
+		Expect(received[0].State).To(Equal(fleet.ErrApplied))
+		Expect(received[0].PreviousState).To(Equal(fleet.Ready))
+		Expect(getNotification().Status.LastSentTime).NotTo(BeNil())
+
+		// the same state is not notified again
+		_, err = reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(received).To(HaveLen(1))
+
+		// recovering is recorded, but not notified by default
+		setState(fleet.BundleSummary{DesiredReady: 2, Ready: 2})
+		_, err = reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(received).To(HaveLen(1))
+		Expect(getNotification().Status.ResourceStates).To(Equal(map[string]fleet.BundleState{"GitRepo/apps": fleet.Ready}))
+	})
+
+	It("retries failed deliveries", func() {
+		Expect(k8sclient.Get(ctx, req.NamespacedName, oncall)).To(Succeed())
+		oncall.Status.ResourceStates = map[string]fleet.BundleState{"GitRepo/apps": fleet.Ready}
+		Expect(k8sclient.Status().Update(ctx, oncall)).To(Succeed())
+
+		setState(fleet.BundleSummary{DesiredReady: 1, Modified: 1})
+		status = http.StatusServiceUnavailable
+		_, err := reconciler.Reconcile(ctx, req)
+		Expect(err).To(HaveOccurred())
+
+		n := getNotification()
+		Expect(n.Status.ResourceStates).To(Equal(map[string]fleet.BundleState{"GitRepo/apps": fleet.Ready}))
+		Expect(n.Status.Conditions).To(ContainElement(HaveField("Type", string(fleet.Ready))))
+
+		status = http.StatusOK
+		_, err = reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(received).To(HaveLen(1))
+		Expect(received[0].State).To(Equal(fleet.Modified))
+		Expect(getNotification().Status.ResourceStates).To(Equal(map[string]fleet.BundleState{"GitRepo/apps": fleet.Modified}))
+	})
+
+	It("does not notify transitions while suspended", func() {
+		Expect(k8sclient.Get(ctx, req.NamespacedName, oncall)).To(Succeed())
+		oncall.Spec.Suspend = true
+		Expect(k8sclient.Update(ctx, oncall)).To(Succeed())
+
+		setState(fleet.BundleSummary{DesiredReady: 1, NotReady: 1})
+		_, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(received).To(BeEmpty())
+		Expect(getNotification().Status.ResourceStates).To(Equal(map[string]fleet.BundleState{"GitRepo/apps": fleet.NotReady}))
+	})
+})
@@ -39,6 +39,7 @@ type ControllerReconcilerWorkers struct {
 	Cluster          int
 	ClusterGroup     int
 	ImageScan        int
+	Notification     int
 	Schedule         int
 }
 
@@ -126,6 +127,14 @@ func (f *FleetController) Run(cmd *cobra.Command, args []string) error {
 		workersOpts.ImageScan = w
 	}
 
+	if d := os.Getenv("NOTIFICATION_RECONCILER_WORKERS"); d != "" {
+		w, err := strconv.Atoi(d)
+		if err != nil {
+			setupLog.Error(err, "failed to parse NOTIFICATION_RECONCILER_WORKERS", "value", d)
+		}
+		workersOpts.Notification = w
+	}
+
 	if d := os.Getenv("SCHEDULE_RECONCILER_WORKERS"); d != "" {
 		w, err := strconv.Atoi(d)
 		if err != nil {
@@ -95,6 +95,8 @@ func GetDeploymentState(bundleDeployment *fleet.BundleDeployment) fleet.BundleSt
 		return fleet.WaitApplied
 	case !bundleDeployment.Status.Ready:
 		return fleet.NotReady
+	case bundleDeployment.Status.SyncWavesPending:
+		return fleet.WaitApplied
 	case bundleDeployment.Spec.DeploymentID != bundleDeployment.Spec.StagedDeploymentID:
 		return fleet.OutOfSync
 	case !bundleDeployment.Status.NonModified:
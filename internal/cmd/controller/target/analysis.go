@@ -0,0 +1,264 @@
+package target
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+)
+
+const (
+	defaultAnalysisTimeout = 10 * time.Second
+	// maxAnalysisTimeout bounds the timeout configured for an analysis.
+	maxAnalysisTimeout = 30 * time.Second
+)
+
+// ErrAnalysisRunning is returned by Analyzer.Analyze while the analysis runs
+// in the background.
+var ErrAnalysisRunning = errors.New("analysis is running")
+
+// Analyzer runs analyses in the background, so they do not block the
+// reconciliation of bundles. It has to be added to the manager, analyses
+// only run after it was started and are cancelled when the manager stops.
+type Analyzer struct {
+	// Client sends the requests of the checks, http.DefaultClient is used
+	// if it is nil.
+	Client *http.Client
+
+	mu   sync.Mutex
+	ctx  context.Context
+	runs map[string]*analysisRun
+}
+
+type analysisRun struct {
+	analysis fleet.PartitionAnalysis
+	cancel   context.CancelFunc
+	done     bool
+	err      error
+}
+
+// Start implements manager.Runnable. The analyses are run with the context
+// of the manager, until it stops.
+func (a *Analyzer) Start(ctx context.Context) error {
+	a.mu.Lock()
+	a.ctx = ctx
+	a.mu.Unlock()
+
+	<-ctx.Done()
+	return nil
+}
+
+// Analyze returns the result of the analysis identified by key, once it
+// finished. Until then, it returns ErrAnalysisRunning. The result is only
+// returned once, the next call starts the analysis again. Results of
+// analyses, whose definition changed while running, are discarded.
+func (a *Analyzer) Analyze(key string, analysis *fleet.PartitionAnalysis) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if run, ok := a.runs[key]; ok {
+		if reflect.DeepEqual(run.analysis, *analysis) {
+			if !run.done {
+				return ErrAnalysisRunning
+			}
+			delete(a.runs, key)
+			return run.err
+		}
+		run.cancel()
+	}
+
+	// not started yet, the analysis is polled again
+	if a.ctx == nil {
+		return ErrAnalysisRunning
+	}
+
+	if a.runs == nil {
+		a.runs = map[string]*analysisRun{}
+	}
+	ctx, cancel := context.WithCancel(a.ctx)
+	run := &analysisRun{analysis: *analysis.DeepCopy(), cancel: cancel}
+	a.runs[key] = run
+
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	go func() {
+		defer cancel()
+		err := Analyze(ctx, client, &run.analysis)
+
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		run.done, run.err = true, err
+	}()
+
+	return ErrAnalysisRunning
+}
+
+// Forget cancels and removes the analyses, whose key starts with prefix,
+// except for the keys in keep. It is used to remove the analyses of deleted
+// bundles and partitions.
+func (a *Analyzer) Forget(prefix string, keep ...string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for key, run := range a.runs {
+		if strings.HasPrefix(key, prefix) && !slices.Contains(keep, key) {
+			run.cancel()
+			delete(a.runs, key)
+		}
+	}
+}
+
+// Analyze runs the check defined in analysis and returns an error if the check
+// did not succeed. The timeout of the analysis is capped at 30 seconds.
+func Analyze(ctx context.Context, client *http.Client, analysis *fleet.PartitionAnalysis) error {
+	timeout := defaultAnalysisTimeout
+	if analysis.Timeout != nil && analysis.Timeout.Duration > 0 {
+		timeout = min(analysis.Timeout.Duration, maxAnalysisTimeout)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	switch {
+	case analysis.Prometheus != nil:
+		return analyzePrometheus(ctx, client, analysis.Prometheus)
+	case analysis.HTTP != nil:
+		return analyzeHTTP(ctx, client, analysis.HTTP)
+	default:
+		return fmt.Errorf("no check configured in analysis")
+	}
+}
+
+// analyzeHTTP sends a request to the configured URL and checks the status
+// code of the response.
+func analyzeHTTP(ctx context.Context, client *http.Client, check *fleet.HTTPAnalysis) error {
+	method := check.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, check.URL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if len(check.ExpectedStatusCodes) == 0 {
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, check.URL)
+		}
+		return nil
+	}
+
+	if !slices.Contains(check.ExpectedStatusCodes, resp.StatusCode) {
+		return fmt.Errorf("unexpected status code %d from %s, expected one of %v", resp.StatusCode, check.URL, check.ExpectedStatusCodes)
+	}
+
+	return nil
+}
+
+// prometheusResponse is the subset of the Prometheus instant query API
+// response needed to evaluate a query result.
+type prometheusResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// analyzePrometheus runs an instant query against the Prometheus API. It
+// succeeds if the result contains at least one sample and all samples are
+// non-zero.
+func analyzePrometheus(ctx context.Context, client *http.Client, check *fleet.PrometheusAnalysis) error {
+	u, err := url.Parse(strings.TrimSuffix(check.Address, "/") + "/api/v1/query")
+	if err != nil {
+		return fmt.Errorf("invalid prometheus address %q: %w", check.Address, err)
+	}
+	u.RawQuery = url.Values{"query": []string{check.Query}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result prometheusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode prometheus response (status code %d): %w", resp.StatusCode, err)
+	}
+	if result.Status != "success" {
+		return fmt.Errorf("prometheus query failed: %s", result.Error)
+	}
+
+	var values []string
+	switch result.Data.ResultType {
+	case "vector":
+		var samples []struct {
+			Value []any `json:"value"`
+		}
+		if err := json.Unmarshal(result.Data.Result, &samples); err != nil {
+			return fmt.Errorf("failed to decode vector result: %w", err)
+		}
+		for _, s := range samples {
+			values = append(values, sampleValue(s.Value))
+		}
+	case "scalar":
+		var sample []any
+		if err := json.Unmarshal(result.Data.Result, &sample); err != nil {
+			return fmt.Errorf("failed to decode scalar result: %w", err)
+		}
+		values = append(values, sampleValue(sample))
+	default:
+		return fmt.Errorf("unsupported prometheus result type %q", result.Data.ResultType)
+	}
+
+	if len(values) == 0 {
+		return fmt.Errorf("query %q returned no samples", check.Query)
+	}
+	for _, v := range values {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("invalid sample value %q: %w", v, err)
+		}
+		if f == 0 {
+			return fmt.Errorf("query %q returned a zero sample", check.Query)
+		}
+	}
+
+	return nil
+}
+
+// sampleValue returns the value of a Prometheus sample, which is encoded as
+// a [<timestamp>, "<value>"] pair.
+func sampleValue(sample []any) string {
+	if len(sample) != 2 {
+		return ""
+	}
+	v, _ := sample[1].(string)
+	return v
+}
@@ -0,0 +1,204 @@
+package target
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+)
+
+func TestAnalyzeHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/healthy":
+			w.WriteHeader(http.StatusOK)
+		case "/accepted":
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		check   *fleet.HTTPAnalysis
+		wantErr bool
+	}{
+		{name: "2xx succeeds by default", check: &fleet.HTTPAnalysis{URL: srv.URL + "/healthy"}},
+		{name: "5xx fails by default", check: &fleet.HTTPAnalysis{URL: srv.URL + "/broken"}, wantErr: true},
+		{
+			name:  "expected status codes",
+			check: &fleet.HTTPAnalysis{URL: srv.URL + "/broken", ExpectedStatusCodes: []int{http.StatusServiceUnavailable}},
+		},
+		{
+			name:    "unexpected status code",
+			check:   &fleet.HTTPAnalysis{URL: srv.URL + "/accepted", ExpectedStatusCodes: []int{http.StatusOK}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Analyze(context.Background(), srv.Client(), &fleet.PartitionAnalysis{HTTP: tt.check})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Analyze() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAnalyzePrometheus(t *testing.T) {
+	responses := map[string]string{
+		"healthy":   `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"1"]}]}}`,
+		"empty":     `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+		"zero":      `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"0"]}]}}`,
+		"scalar":    `{"status":"success","data":{"resultType":"scalar","result":[1700000000,"0.5"]}}`,
+		"badquery":  `{"status":"error","errorType":"bad_data","error":"parse error"}`,
+		"matrix":    `{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+		"malformed": `not json`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(responses[r.URL.Query().Get("query")]))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		query   string
+		wantErr bool
+	}{
+		{query: "healthy"},
+		{query: "empty", wantErr: true},
+		{query: "zero", wantErr: true},
+		{query: "scalar"},
+		{query: "badquery", wantErr: true},
+		{query: "matrix", wantErr: true},
+		{query: "malformed", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			err := Analyze(context.Background(), srv.Client(), &fleet.PartitionAnalysis{
+				Prometheus: &fleet.PrometheusAnalysis{Address: srv.URL + "/", Query: tt.query},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Analyze() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAnalyzer(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	a := &Analyzer{Client: srv.Client()}
+	analysis := &fleet.PartitionAnalysis{HTTP: &fleet.HTTPAnalysis{URL: srv.URL}}
+
+	// nothing runs before the analyzer is started
+	if err := a.Analyze("bundle/partition", analysis); !errors.Is(err, ErrAnalysisRunning) {
+		t.Fatalf("Analyze() error = %v, want ErrAnalysisRunning", err)
+	}
+	if len(a.runs) != 0 {
+		t.Fatalf("analysis started before the analyzer")
+	}
+	startAnalyzer(t, a)
+
+	if err := a.Analyze("bundle/partition", analysis); !errors.Is(err, ErrAnalysisRunning) {
+		t.Fatalf("Analyze() error = %v, want ErrAnalysisRunning", err)
+	}
+	if err := a.Analyze("bundle/partition", analysis); !errors.Is(err, ErrAnalysisRunning) {
+		t.Fatalf("Analyze() error = %v, want ErrAnalysisRunning while the check is blocked", err)
+	}
+	close(release)
+
+	var err error
+	for range 100 {
+		if err = a.Analyze("bundle/partition", analysis); !errors.Is(err, ErrAnalysisRunning) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err == nil || errors.Is(err, ErrAnalysisRunning) {
+		t.Fatalf("Analyze() error = %v, want the result of the check", err)
+	}
+
+	// the result is returned once, the next call starts a new analysis
+	if err := a.Analyze("bundle/partition", analysis); !errors.Is(err, ErrAnalysisRunning) {
+		t.Errorf("Analyze() error = %v, want ErrAnalysisRunning", err)
+	}
+}
+
+func TestAnalyzerForget(t *testing.T) {
+	received := make(chan struct{}, 3)
+	cancelled := make(chan string, 3)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		<-r.Context().Done()
+		cancelled <- r.URL.Path
+	}))
+	defer srv.Close()
+
+	a := &Analyzer{Client: srv.Client()}
+	startAnalyzer(t, a)
+	// cancel the remaining analyses, before the server is closed
+	defer a.Forget("")
+	for _, key := range []string{"ns/bundle/removed", "ns/bundle/kept", "ns/other/removed"} {
+		analysis := &fleet.PartitionAnalysis{HTTP: &fleet.HTTPAnalysis{URL: srv.URL + "/" + key}}
+		if err := a.Analyze(key, analysis); !errors.Is(err, ErrAnalysisRunning) {
+			t.Fatalf("Analyze() error = %v, want ErrAnalysisRunning", err)
+		}
+	}
+	for range 3 {
+		<-received
+	}
+
+	a.Forget("ns/bundle/", "ns/bundle/kept")
+	select {
+	case path := <-cancelled:
+		if path != "/ns/bundle/removed" {
+			t.Errorf("cancelled analysis %s, want /ns/bundle/removed", path)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("forgotten analysis was not cancelled")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.runs["ns/bundle/removed"]; ok {
+		t.Error("forgotten analysis is still recorded")
+	}
+	if len(a.runs) != 2 {
+		t.Errorf("expected the analyses of other partitions and bundles to be kept, got %d", len(a.runs))
+	}
+}
+
+// startAnalyzer starts the analyzer like the manager does and waits until
+// it is ready. It is stopped when the test ends.
+func startAnalyzer(t *testing.T, a *Analyzer) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() { _ = a.Start(ctx) }()
+
+	for range 100 {
+		a.mu.Lock()
+		started := a.ctx != nil
+		a.mu.Unlock()
+		if started {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("analyzer did not start")
+}
@@ -86,6 +86,9 @@ func (m *Manager) Targets(ctx context.Context, bundle *fleet.Bundle, manifestID
 			}
 
 			opts := options.Merge(bundle.Spec.BundleDeploymentOptions, targetOpts)
+			if opts.Decryption == nil && cluster.Spec.Decryption != nil {
+				opts.Decryption = cluster.Spec.Decryption.DeepCopy()
+			}
 			err = preprocessHelmValues(logger, &opts, &cluster)
 			if err != nil {
 				return nil, fmt.Errorf("cluster %s in namespace %s: %w", cluster.Name, cluster.Namespace, err)
@@ -129,7 +132,7 @@ func (m *Manager) Targets(ctx context.Context, bundle *fleet.Bundle, manifestID
 				return nil, fmt.Errorf("failed to get options secret for bundledeployment %s/%s, this is likely temporary: %w", bd.Namespace, bd.Name, err)
 			}
 
-			h := helmvalues.HashOptions(secret.Data[helmvalues.ValuesKey], secret.Data[helmvalues.StagedValuesKey])
+			h := helmvalues.HashOptionsSecret(secret.Data)
 			if h != bd.Spec.ValuesHash {
 				return nil, fmt.Errorf("retrying, hash mismatch between secret and bundledeployment: actual %s != expected %s", h, bd.Spec.ValuesHash)
 			}
@@ -0,0 +1,91 @@
+package target
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Overlap is a resource of a bundle, which is already deployed by another
+// bundle to a targeted cluster.
+type Overlap struct {
+	// Cluster is the cluster as "namespace/name".
+	Cluster string
+	// Bundle is the other bundle as "namespace/name".
+	Bundle string
+	// Resource is the overlapping resource as "kind namespace/name".
+	Resource string
+}
+
+func (o Overlap) String() string {
+	return fmt.Sprintf("%s on cluster %s is deployed by bundle %s", o.Resource, o.Cluster, o.Bundle)
+}
+
+// Overlaps returns the resources, which other bundles deployed to the
+// targeted clusters and the bundle's deployments reported too, either as
+// deployed or as missing. The agent refuses to deploy such resources, as they
+// are owned by the other bundle. Bundle deployments are read from the cache,
+// only for clusters on which the bundle reported resources.
+func (m *Manager) Overlaps(ctx context.Context, bundle *fleet.Bundle, targets []*Target) ([]Overlap, error) {
+	var overlaps []Overlap
+	for _, target := range targets {
+		if target.Deployment == nil {
+			continue
+		}
+		own := reportedResources(target.Deployment)
+		if len(own) == 0 {
+			continue
+		}
+
+		bundleDeployments := &fleet.BundleDeploymentList{}
+		if err := m.client.List(ctx, bundleDeployments, client.InNamespace(target.Deployment.Namespace)); err != nil {
+			return nil, err
+		}
+		for _, bd := range bundleDeployments.Items {
+			other := bd.Labels[fleet.BundleNamespaceLabel] + "/" + bd.Labels[fleet.BundleLabel]
+			if other == bundle.Namespace+"/"+bundle.Name {
+				continue
+			}
+			for _, res := range bd.Status.Resources {
+				key := fleet.ResourceKey{APIVersion: res.APIVersion, Kind: res.Kind, Namespace: res.Namespace, Name: res.Name}
+				if !own[key] {
+					continue
+				}
+				resource := res.Kind + " " + res.Name
+				if res.Namespace != "" {
+					resource = res.Kind + " " + res.Namespace + "/" + res.Name
+				}
+				overlaps = append(overlaps, Overlap{
+					Cluster:  target.Cluster.Namespace + "/" + target.Cluster.Name,
+					Bundle:   other,
+					Resource: resource,
+				})
+			}
+		}
+	}
+
+	sort.Slice(overlaps, func(i, j int) bool {
+		return overlaps[i].String() < overlaps[j].String()
+	})
+	return overlaps, nil
+}
+
+// reportedResources returns the keys of the resources the agent reported for
+// the bundle deployment, including missing resources, which it failed to
+// create.
+func reportedResources(bd *fleet.BundleDeployment) map[fleet.ResourceKey]bool {
+	keys := map[fleet.ResourceKey]bool{}
+	for _, res := range bd.Status.Resources {
+		keys[fleet.ResourceKey{APIVersion: res.APIVersion, Kind: res.Kind, Namespace: res.Namespace, Name: res.Name}] = true
+	}
+	for _, res := range bd.Status.ModifiedStatus {
+		if res.Create {
+			keys[fleet.ResourceKey{APIVersion: res.APIVersion, Kind: res.Kind, Namespace: res.Namespace, Name: res.Name}] = true
+		}
+	}
+	return keys
+}
@@ -0,0 +1,82 @@
+package target
+
+import (
+	"context"
+	"testing"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestOverlaps(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := fleet.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	bd := func(name, bundle string, resources ...fleet.BundleDeploymentResource) *fleet.BundleDeployment {
+		return &fleet.BundleDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "cluster-ns",
+				Name:      name,
+				Labels: map[string]string{
+					fleet.BundleLabel:          bundle,
+					fleet.BundleNamespaceLabel: "fleet-default",
+				},
+			},
+			Status: fleet.BundleDeploymentStatus{Resources: resources},
+		}
+	}
+	// the bundle's own deployment is not an overlap
+	own := bd("bundle", "bundle",
+		fleet.BundleDeploymentResource{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "own"},
+		fleet.BundleDeploymentResource{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "shared"},
+	)
+	// the agent failed to create the cluster role
+	own.Status.ModifiedStatus = []fleet.ModifiedStatus{
+		{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole", Name: "shared", Create: true},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&fleet.BundleDeployment{}).WithObjects(
+		own,
+		bd("other", "other",
+			fleet.BundleDeploymentResource{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "shared"},
+			fleet.BundleDeploymentResource{APIVersion: "v1", Kind: "ConfigMap", Namespace: "other", Name: "own"},
+			fleet.BundleDeploymentResource{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole", Name: "shared"},
+			fleet.BundleDeploymentResource{APIVersion: "v1", Kind: "Secret", Namespace: "default", Name: "shared"},
+		),
+	).Build()
+
+	bundle := &fleet.Bundle{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-default", Name: "bundle"},
+	}
+	cluster := &fleet.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-default", Name: "local"},
+		Status:     fleet.ClusterStatus{Namespace: "cluster-ns"},
+	}
+	targets := []*Target{
+		{Cluster: cluster, Deployment: own},
+		// no resources are reported before the bundle is deployed
+		{Cluster: cluster},
+	}
+
+	overlaps, err := New(c, c).Overlaps(context.TODO(), bundle, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"ClusterRole shared on cluster fleet-default/local is deployed by bundle fleet-default/other",
+		"ConfigMap default/shared on cluster fleet-default/local is deployed by bundle fleet-default/other",
+	}
+	if len(overlaps) != len(expected) {
+		t.Fatalf("expected %d overlaps, got %v", len(expected), overlaps)
+	}
+	for i, overlap := range overlaps {
+		if overlap.String() != expected[i] {
+			t.Errorf("expected overlap %q, got %q", expected[i], overlap.String())
+		}
+	}
+}
@@ -9,24 +9,45 @@ import (
 type partition struct {
 	Status  fleet.PartitionStatus
 	Targets []*Target
+	// Definition is the partition from the rollout strategy, it is nil for
+	// automatically created partitions.
+	Definition *fleet.Partition
 }
 
 // UpdatePartitions recomputes status, including partitions, from data in allTargets.
 // It creates Deployments in allTargets if they are missing.
 // It updates Deployments in allTargets if they are out of sync (DeploymentID != StagedDeploymentID).
-func UpdatePartitions(status *fleet.BundleStatus, allTargets []*Target) (err error) {
+// If promotion is not nil, the rollout does not proceed past a partition until
+// the partition's bake duration and analysis gates are passed, and partitions
+// failing to deploy are rolled back if automatic rollbacks are enabled.
+func UpdatePartitions(status *fleet.BundleStatus, allTargets []*Target, promotion *Promotion) (err error) {
 	partitions, err := partitions(allTargets)
 	if err != nil {
 		return err
 	}
 
+	previous := status.PartitionStatus
+	status.PartitionStatus = nil
+
+	// A rollback halts staging until the bundle changes.
+	if rollbackHalted(status, allTargets) {
+		for _, partition := range partitions {
+			if partition.Status.Name == status.Rollback.Partition {
+				partition.Status.HeldReason = rolledBackReason
+			}
+			status.PartitionStatus = append(status.PartitionStatus, partition.Status)
+		}
+		return nil
+	}
+	status.Rollback = nil
+
 	status.UnavailablePartitions = 0
 	status.MaxUnavailablePartitions, err = maxUnavailablePartitions(partitions, allTargets)
 	if err != nil {
 		return err
 	}
 
-	for _, partition := range partitions {
+	for i, partition := range partitions {
 		partition := partition // fix gosec warning regarding "Implicit memory aliasing in for loop"
 
 		for _, target := range partition.Targets {
@@ -54,6 +75,10 @@ func UpdatePartitions(status *fleet.BundleStatus, allTargets []*Target) (err err
 			updateDeploymentFromStaged(currentTarget, status, &partition.Status)
 		}
 
+		if promotion.rollback(status, &partitions[i], previousPartitionStatus(previous, i, partition.Status.Name)) {
+			break
+		}
+
 		if updatePartitionStatus(&partition.Status, partition.Targets) {
 			status.UnavailablePartitions++
 		}
@@ -61,6 +86,10 @@ func UpdatePartitions(status *fleet.BundleStatus, allTargets []*Target) (err err
 		if status.UnavailablePartitions > status.MaxUnavailablePartitions {
 			break
 		}
+
+		if !promotion.promote(&partitions[i], previousPartitionStatus(previous, i, partition.Status.Name)) {
+			break
+		}
 	}
 
 	for _, partition := range partitions {
@@ -87,15 +116,16 @@ func updateDeploymentFromStaged(t *Target, bundleStatus *fleet.BundleStatus, par
 		// Is out of sync
 		t.Deployment.Spec.DeploymentID != t.Deployment.Spec.StagedDeploymentID &&
 		// Global max unavailable not reached
-		(bundleStatus.Unavailable < bundleStatus.MaxUnavailable || isUnavailable(t.Deployment)) &&
+		(bundleStatus.Unavailable < bundleStatus.MaxUnavailable || isUnavailable(t.Deployment) || ignoreOffline(t)) &&
 		// Partition max unavailable not reached
-		(partitionStatus.Unavailable < partitionStatus.MaxUnavailable || isUnavailable(t.Deployment)) {
+		(partitionStatus.Unavailable < partitionStatus.MaxUnavailable || isUnavailable(t.Deployment) || ignoreOffline(t)) {
 
-		if !isUnavailable(t.Deployment) {
+		if !isUnavailable(t.Deployment) && !ignoreOffline(t) {
 			// If this was previously available, now increment unavailable count. "Upgrading" is treated as unavailable.
 			bundleStatus.Unavailable++
 			partitionStatus.Unavailable++
 		}
+		recordKnownGood(t)
 		t.Deployment.Spec.DeploymentID = t.Deployment.Spec.StagedDeploymentID
 		t.Deployment.Spec.Options = t.Deployment.Spec.StagedOptions
 	}
@@ -0,0 +1,185 @@
+package target
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/rancher/fleet/internal/cmd/agent/deployer/kv"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	"github.com/rancher/fleet/pkg/durations"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Promotion gates the rollout of a bundle from one partition to the next,
+// based on the bake duration, the analysis and the approval configured for
+// each partition.
+//
+// A nil Promotion disables all gates, e.g. when printing targets from the CLI.
+type Promotion struct {
+	// Now returns the current time, it is used to compute bake durations.
+	Now func() time.Time
+	// Analyze runs the analysis of the named partition. It returns
+	// ErrAnalysisRunning, if the result is not available yet. Analyses are
+	// skipped if Analyze is nil.
+	Analyze func(partition string, analysis *fleet.PartitionAnalysis) error
+
+	// RequeueAfter is set by UpdatePartitions to the shortest duration
+	// after which a held partition should be re-evaluated. It is zero if
+	// no partition is held by a gate which needs re-evaluation.
+	RequeueAfter time.Duration
+}
+
+// promote decides whether the rollout may proceed past the partition p. It
+// records the progress of the partition and the reason for holding the
+// rollout in p.Status.
+// previous is the status of the same partition from the last reconcile, if
+// any.
+func (pr *Promotion) promote(p *partition, previous *fleet.PartitionStatus) bool {
+	if pr == nil || p.Definition == nil ||
+		(p.Definition.BakeDuration == nil && p.Definition.Analysis == nil && !p.Definition.RequireApproval) {
+		return true
+	}
+
+	notReady := 0
+	for _, target := range p.Targets {
+		if ignoreOffline(target) {
+			continue
+		}
+		if !upToDate(target) || isUnavailable(target.Deployment) {
+			notReady++
+		}
+	}
+	if notReady > 0 {
+		p.Status.HeldReason = fmt.Sprintf("waiting for %d/%d clusters to be up-to-date and ready", notReady, len(p.Targets))
+		return false
+	}
+
+	now := pr.now()
+	readySince := metav1.NewTime(now)
+	if previous != nil && previous.ReadySince != nil {
+		readySince = *previous.ReadySince
+
+		// The gates were already passed in a previous reconcile, no need to
+		// evaluate the analysis again. The approval is checked again, it
+		// can be revoked.
+		if previous.HeldReason == "" && pendingApproval(p) == "" {
+			p.Status.ReadySince = &readySince
+			return true
+		}
+	}
+	p.Status.ReadySince = &readySince
+
+	if bake := p.Definition.BakeDuration; bake != nil {
+		if remaining := readySince.Add(bake.Duration).Sub(now); remaining > 0 {
+			p.Status.HeldReason = fmt.Sprintf("baking, %s remaining", remaining.Round(time.Second))
+			pr.requeue(remaining)
+			return false
+		}
+	}
+
+	if p.Definition.Analysis != nil && pr.Analyze != nil {
+		if err := pr.Analyze(p.Status.Name, p.Definition.Analysis); errors.Is(err, ErrAnalysisRunning) {
+			p.Status.HeldReason = "waiting for the analysis to finish"
+			pr.requeue(durations.PartitionAnalysisPoll)
+			return false
+		} else if err != nil {
+			p.Status.HeldReason = fmt.Sprintf("analysis failed: %v", err)
+			pr.requeue(durations.PartitionAnalysisRetry)
+			return false
+		}
+	}
+
+	// Approvals are not requeued, changing the bundle's annotations
+	// triggers a reconcile.
+	if id := pendingApproval(p); id != "" {
+		p.Status.PendingApproval = id
+		p.Status.HeldReason = fmt.Sprintf("waiting for approval of %q", id)
+		return false
+	}
+
+	return true
+}
+
+// pendingApproval returns the approval id of the bundle version deployed to
+// the partition, if the partition requires an approval and the version is
+// not approved yet (pure function).
+func pendingApproval(p *partition) string {
+	if !p.Definition.RequireApproval {
+		return ""
+	}
+	id := approvalID(p.Targets)
+	if id == "" || approved(p.Targets[0].Bundle, p.Status.Name, id) {
+		return ""
+	}
+	return id
+}
+
+// approvalID returns the id which identifies the version of the bundle
+// deployed to targets, it is the manifest or contents ID shared by all
+// targets' deployment IDs (pure function).
+func approvalID(targets []*Target) string {
+	if len(targets) == 0 {
+		return ""
+	}
+	id, _ := kv.Split(targets[0].DeploymentID, ":")
+	return id
+}
+
+// approved returns true if the bundle's approval annotation approves the
+// promotion of the bundle version identified by id past the named partition
+// (pure function).
+func approved(bundle *fleet.Bundle, partition, id string) bool {
+	for _, approval := range strings.Split(bundle.Annotations[fleet.PromotionApprovalAnnotation], ",") {
+		name, approvedID := kv.Split(strings.TrimSpace(approval), "=")
+		if name == partition && approvedID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// KeepApprovals returns the annotations with the promotion approvals of the
+// live bundle added. Approvals are added to bundles by users, so they are
+// kept when fleet apply or the HelmOp controller replace the annotations of
+// a bundle.
+func KeepApprovals(live *fleet.Bundle, annotations map[string]string) map[string]string {
+	approvals, ok := live.Annotations[fleet.PromotionApprovalAnnotation]
+	if !ok {
+		return annotations
+	}
+	if _, ok := annotations[fleet.PromotionApprovalAnnotation]; ok {
+		return annotations
+	}
+	annotations = maps.Clone(annotations)
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[fleet.PromotionApprovalAnnotation] = approvals
+	return annotations
+}
+
+func (pr *Promotion) now() time.Time {
+	if pr.Now == nil {
+		return time.Now()
+	}
+	return pr.Now()
+}
+
+func (pr *Promotion) requeue(after time.Duration) {
+	if pr.RequeueAfter == 0 || after < pr.RequeueAfter {
+		pr.RequeueAfter = after
+	}
+}
+
+// previousPartitionStatus returns the status of the partition at index i from
+// the previous reconcile, if its name still matches.
+func previousPartitionStatus(previous []fleet.PartitionStatus, i int, name string) *fleet.PartitionStatus {
+	if i >= len(previous) || previous[i].Name != name {
+		return nil
+	}
+	return &previous[i]
+}
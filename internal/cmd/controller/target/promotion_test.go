@@ -0,0 +1,299 @@
+package target
+
+import (
+	"errors"
+	"maps"
+	"strings"
+	"testing"
+	"time"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// readyTargets marks all targets as up-to-date and ready.
+func readyTargets(targets []*Target) []*Target {
+	for _, t := range targets {
+		t.Deployment.Spec.StagedDeploymentID = t.DeploymentID
+		t.Deployment.Spec.DeploymentID = t.DeploymentID
+		t.Deployment.Status.AppliedDeploymentID = t.DeploymentID
+		t.Deployment.Status.Ready = true
+	}
+	return targets
+}
+
+// withApproval sets the promotion approval annotation on the targets' bundles.
+func withApproval(targets []*Target, approval string) []*Target {
+	for _, t := range targets {
+		t.Bundle.Annotations = map[string]string{fleet.PromotionApprovalAnnotation: approval}
+	}
+	return targets
+}
+
+func Test_promote(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	bake := &metav1.Duration{Duration: 10 * time.Minute}
+	analysis := &fleet.PartitionAnalysis{HTTP: &fleet.HTTPAnalysis{URL: "http://localhost"}}
+
+	tests := []struct {
+		name             string
+		promotion        *Promotion
+		definition       *fleet.Partition
+		targets          []*Target
+		previous         *fleet.PartitionStatus
+		want             bool
+		wantReason       string
+		wantReadySince   *time.Time
+		wantRequeueAfter time.Duration
+	}{
+		{
+			name:       "nil promotion disables gates",
+			definition: &fleet.Partition{BakeDuration: bake},
+			targets:    createTargets(1, 2),
+			want:       true,
+		},
+		{
+			name:      "partition without gates is promoted",
+			promotion: &Promotion{},
+			targets:   createTargets(1, 2),
+			want:      true,
+		},
+		{
+			name:       "partition with clusters that are not ready is held",
+			promotion:  &Promotion{},
+			definition: &fleet.Partition{BakeDuration: bake},
+			targets:    append(readyTargets(createTargets(1, 1)), createTargets(2, 2)...),
+			want:       false,
+			wantReason: "waiting for 1/2 clusters to be up-to-date and ready",
+		},
+		{
+			name:             "ready partition starts baking",
+			promotion:        &Promotion{Now: func() time.Time { return now }},
+			definition:       &fleet.Partition{BakeDuration: bake},
+			targets:          readyTargets(createTargets(1, 2)),
+			want:             false,
+			wantReason:       "baking, 10m0s remaining",
+			wantReadySince:   &now,
+			wantRequeueAfter: 10 * time.Minute,
+		},
+		{
+			name:             "baking partition keeps its ready time",
+			promotion:        &Promotion{Now: func() time.Time { return now.Add(4 * time.Minute) }},
+			definition:       &fleet.Partition{BakeDuration: bake},
+			targets:          readyTargets(createTargets(1, 2)),
+			previous:         &fleet.PartitionStatus{ReadySince: &metav1.Time{Time: now}, HeldReason: "baking"},
+			want:             false,
+			wantReason:       "baking, 6m0s remaining",
+			wantReadySince:   &now,
+			wantRequeueAfter: 6 * time.Minute,
+		},
+		{
+			name:           "baked partition is promoted",
+			promotion:      &Promotion{Now: func() time.Time { return now.Add(10 * time.Minute) }},
+			definition:     &fleet.Partition{BakeDuration: bake},
+			targets:        readyTargets(createTargets(1, 2)),
+			previous:       &fleet.PartitionStatus{ReadySince: &metav1.Time{Time: now}, HeldReason: "baking"},
+			want:           true,
+			wantReadySince: &now,
+		},
+		{
+			name: "failed analysis holds the partition",
+			promotion: &Promotion{
+				Now:     func() time.Time { return now.Add(10 * time.Minute) },
+				Analyze: func(string, *fleet.PartitionAnalysis) error { return errors.New("error rate too high") },
+			},
+			definition:       &fleet.Partition{BakeDuration: bake, Analysis: analysis},
+			targets:          readyTargets(createTargets(1, 2)),
+			previous:         &fleet.PartitionStatus{ReadySince: &metav1.Time{Time: now}, HeldReason: "baking"},
+			want:             false,
+			wantReason:       "analysis failed: error rate too high",
+			wantReadySince:   &now,
+			wantRequeueAfter: 30 * time.Second,
+		},
+		{
+			name: "running analysis holds the partition",
+			promotion: &Promotion{
+				Now:     func() time.Time { return now },
+				Analyze: func(string, *fleet.PartitionAnalysis) error { return ErrAnalysisRunning },
+			},
+			definition:       &fleet.Partition{Analysis: analysis},
+			targets:          readyTargets(createTargets(1, 2)),
+			want:             false,
+			wantReason:       "waiting for the analysis to finish",
+			wantReadySince:   &now,
+			wantRequeueAfter: 5 * time.Second,
+		},
+		{
+			name: "successful analysis promotes the partition",
+			promotion: &Promotion{
+				Now:     func() time.Time { return now },
+				Analyze: func(string, *fleet.PartitionAnalysis) error { return nil },
+			},
+			definition:     &fleet.Partition{Analysis: analysis},
+			targets:        readyTargets(createTargets(1, 2)),
+			want:           true,
+			wantReadySince: &now,
+		},
+		{
+			name: "analysis is not repeated once passed",
+			promotion: &Promotion{
+				Now:     func() time.Time { return now },
+				Analyze: func(string, *fleet.PartitionAnalysis) error { return errors.New("should not be called") },
+			},
+			definition:     &fleet.Partition{Analysis: analysis},
+			targets:        readyTargets(createTargets(1, 2)),
+			previous:       &fleet.PartitionStatus{ReadySince: &metav1.Time{Time: now}},
+			want:           true,
+			wantReadySince: &now,
+		},
+		{
+			name:           "partition requiring approval is held",
+			promotion:      &Promotion{Now: func() time.Time { return now }},
+			definition:     &fleet.Partition{RequireApproval: true},
+			targets:        readyTargets(createTargets(1, 2)),
+			want:           false,
+			wantReason:     `waiting for approval of "deployment-1"`,
+			wantReadySince: &now,
+		},
+		{
+			name:       "approval for another version does not promote",
+			promotion:  &Promotion{Now: func() time.Time { return now }},
+			definition: &fleet.Partition{RequireApproval: true},
+			targets: withApproval(readyTargets(createTargets(1, 2)),
+				"canary=deployment-0"),
+			want:           false,
+			wantReason:     `waiting for approval of "deployment-1"`,
+			wantReadySince: &now,
+		},
+		{
+			name:       "approved partition is promoted",
+			promotion:  &Promotion{Now: func() time.Time { return now }},
+			definition: &fleet.Partition{RequireApproval: true},
+			targets: withApproval(readyTargets(createTargets(1, 2)),
+				"other=deployment-1, canary=deployment-1"),
+			want:           true,
+			wantReadySince: &now,
+		},
+		{
+			name:       "promoted partition is promoted again",
+			promotion:  &Promotion{Now: func() time.Time { return now }},
+			definition: &fleet.Partition{RequireApproval: true},
+			targets: withApproval(readyTargets(createTargets(1, 2)),
+				"canary=deployment-1"),
+			previous:       &fleet.PartitionStatus{ReadySince: &metav1.Time{Time: now}},
+			want:           true,
+			wantReadySince: &now,
+		},
+		{
+			name:           "revoked approval holds a promoted partition",
+			promotion:      &Promotion{Now: func() time.Time { return now }},
+			definition:     &fleet.Partition{RequireApproval: true},
+			targets:        readyTargets(createTargets(1, 2)),
+			previous:       &fleet.PartitionStatus{ReadySince: &metav1.Time{Time: now}},
+			want:           false,
+			wantReason:     `waiting for approval of "deployment-1"`,
+			wantReadySince: &now,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &partition{Targets: tt.targets, Definition: tt.definition, Status: fleet.PartitionStatus{Name: "canary"}}
+			got := tt.promotion.promote(p, tt.previous)
+			if got != tt.want {
+				t.Errorf("promote() = %v, want %v", got, tt.want)
+			}
+			if !strings.HasPrefix(p.Status.HeldReason, tt.wantReason) || (tt.wantReason == "" && p.Status.HeldReason != "") {
+				t.Errorf("HeldReason = %q, want %q", p.Status.HeldReason, tt.wantReason)
+			}
+			switch {
+			case tt.wantReadySince == nil && p.Status.ReadySince != nil:
+				t.Errorf("ReadySince = %v, want nil", p.Status.ReadySince)
+			case tt.wantReadySince != nil && (p.Status.ReadySince == nil || !p.Status.ReadySince.Time.Equal(*tt.wantReadySince)):
+				t.Errorf("ReadySince = %v, want %v", p.Status.ReadySince, tt.wantReadySince)
+			}
+			if tt.promotion != nil && tt.promotion.RequeueAfter != tt.wantRequeueAfter {
+				t.Errorf("RequeueAfter = %v, want %v", tt.promotion.RequeueAfter, tt.wantRequeueAfter)
+			}
+		})
+	}
+}
+
+func TestUpdatePartitionsHeldByBakeDuration(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	canary := &fleet.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "canary", Labels: map[string]string{"env": "canary"}}}
+	prod := &fleet.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "prod", Labels: map[string]string{"env": "prod"}}}
+
+	targets := readyTargets(createTargets(1, 2))
+	withCluster(targets[0:1], canary)
+	withCluster(targets[1:2], prod)
+	bundle := &fleet.Bundle{Spec: fleet.BundleSpec{RolloutStrategy: &fleet.RolloutStrategy{
+		Partitions: []fleet.Partition{
+			{
+				Name:            "canary",
+				ClusterSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "canary"}},
+				BakeDuration:    &metav1.Duration{Duration: time.Hour},
+			},
+			{
+				Name:            "prod",
+				ClusterSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+			},
+		},
+	}}}
+	for _, t := range targets {
+		t.Bundle = bundle
+		// a new version of the bundle
+		t.DeploymentID = "new-" + t.DeploymentID
+	}
+	// the canary partition already runs the new version
+	readyTargets(targets[0:1])
+
+	promotion := &Promotion{Now: func() time.Time { return now }}
+	status := &fleet.BundleStatus{MaxNew: 50}
+	if err := UpdatePartitions(status, targets, promotion); err != nil {
+		t.Fatalf("UpdatePartitions() failed: %v", err)
+	}
+
+	if got := targets[1].Deployment.Spec.StagedDeploymentID; got == targets[1].DeploymentID {
+		t.Errorf("prod partition was staged while canary is baking")
+	}
+	if len(status.PartitionStatus) != 2 {
+		t.Fatalf("expected 2 partition statuses, got %d", len(status.PartitionStatus))
+	}
+	if got := status.PartitionStatus[0].HeldReason; got != "baking, 1h0m0s remaining" {
+		t.Errorf("unexpected held reason %q", got)
+	}
+
+	// after the bake duration, prod gets staged
+	promotion = &Promotion{Now: func() time.Time { return now.Add(time.Hour) }}
+	if err := UpdatePartitions(status, targets, promotion); err != nil {
+		t.Fatalf("UpdatePartitions() failed: %v", err)
+	}
+	if got := targets[1].Deployment.Spec.StagedDeploymentID; got != targets[1].DeploymentID {
+		t.Errorf("prod partition was not staged after baking, got %q", got)
+	}
+	if got := status.PartitionStatus[0].HeldReason; got != "" {
+		t.Errorf("unexpected held reason %q", got)
+	}
+}
+
+func TestKeepApprovals(t *testing.T) {
+	live := &fleet.Bundle{}
+	live.Annotations = map[string]string{fleet.PromotionApprovalAnnotation: "canary=deployment-1", "old": "value"}
+
+	updated := map[string]string{"new": "value"}
+	got := KeepApprovals(live, updated)
+	if want := map[string]string{"new": "value", fleet.PromotionApprovalAnnotation: "canary=deployment-1"}; !maps.Equal(got, want) {
+		t.Errorf("KeepApprovals() = %v, want %v", got, want)
+	}
+	if _, ok := updated[fleet.PromotionApprovalAnnotation]; ok {
+		t.Error("KeepApprovals() modified the annotations")
+	}
+
+	if got := KeepApprovals(live, nil); got[fleet.PromotionApprovalAnnotation] != "canary=deployment-1" {
+		t.Errorf("KeepApprovals() = %v, want the approval", got)
+	}
+
+	if got := KeepApprovals(&fleet.Bundle{}, updated); !maps.Equal(got, updated) {
+		t.Errorf("KeepApprovals() = %v, want %v", got, updated)
+	}
+}
@@ -0,0 +1,127 @@
+package target
+
+import (
+	"time"
+
+	"github.com/rancher/fleet/internal/cmd/controller/summary"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const rolledBackReason = "rolled back after clusters failed to deploy the bundle"
+
+var defAutoRollbackTimeout = 10 * time.Minute
+
+// autoRollback returns the automatic rollback configuration of the targets'
+// rollout strategy, or nil if automatic rollbacks are disabled (pure function).
+func autoRollback(targets []*Target) *fleet.AutoRollback {
+	rollout := getRollout(targets)
+	if rollout.AutoRollback == nil || !rollout.AutoRollback.Enabled {
+		return nil
+	}
+	return rollout.AutoRollback
+}
+
+// rollbackHalted returns true if staging is halted, because the current
+// generation of the bundle was already rolled back (pure function).
+func rollbackHalted(status *fleet.BundleStatus, targets []*Target) bool {
+	if status.Rollback == nil || len(targets) == 0 {
+		return false
+	}
+	return status.Rollback.Generation == targets[0].Bundle.Generation
+}
+
+// recordKnownGood stores the deployment, which is about to be replaced on t,
+// as the target of an automatic rollback if it is ready. Rollback targets are
+// cleared if automatic rollbacks are disabled.
+func recordKnownGood(t *Target) {
+	if autoRollback([]*Target{t}) == nil {
+		t.Deployment.Spec.RollbackDeploymentID = ""
+		t.Deployment.Spec.RollbackOptions = nil
+		return
+	}
+
+	if t.Deployment.Spec.DeploymentID == "" || isUnavailable(t.Deployment) {
+		return
+	}
+
+	t.Deployment.Spec.RollbackDeploymentID = t.Deployment.Spec.DeploymentID
+	t.Deployment.Spec.RollbackOptions = t.Deployment.Spec.Options.DeepCopy()
+}
+
+// failed returns true if the target's bundle deployment runs the target's
+// current deployment and fails to apply it or to become ready (pure function).
+func failed(t *Target) bool {
+	if t.Deployment == nil ||
+		t.Deployment.Spec.DeploymentID != t.DeploymentID ||
+		t.Deployment.Spec.DeploymentID == t.Deployment.Spec.RollbackDeploymentID {
+		return false
+	}
+
+	state := summary.GetDeploymentState(t.Deployment)
+	return state == fleet.ErrApplied || state == fleet.NotReady
+}
+
+// rollback reverts all bundle deployments in partition p to their last
+// known-good deployment, if some of them failed to deploy the current version
+// for longer than the configured timeout. It returns true if p was rolled back.
+// previous is the status of the same partition from the last reconcile, if
+// any.
+func (pr *Promotion) rollback(status *fleet.BundleStatus, p *partition, previous *fleet.PartitionStatus) bool {
+	if pr == nil {
+		return false
+	}
+	cfg := autoRollback(p.Targets)
+	if cfg == nil {
+		return false
+	}
+
+	var clusters []string
+	for _, t := range p.Targets {
+		if failed(t) {
+			clusters = append(clusters, t.Cluster.Namespace+"/"+t.Cluster.Name)
+		}
+	}
+	if len(clusters) == 0 {
+		return false
+	}
+
+	now := pr.now()
+	failingSince := metav1.NewTime(now)
+	if previous != nil && previous.FailingSince != nil {
+		failingSince = *previous.FailingSince
+	}
+	p.Status.FailingSince = &failingSince
+
+	timeout := defAutoRollbackTimeout
+	if cfg.Timeout != nil {
+		timeout = cfg.Timeout.Duration
+	}
+	if remaining := failingSince.Add(timeout).Sub(now); remaining > 0 {
+		pr.requeue(remaining)
+		return false
+	}
+
+	for _, t := range p.Targets {
+		bd := t.Deployment
+		if bd == nil || bd.Spec.RollbackDeploymentID == "" || bd.Spec.RollbackOptions == nil {
+			continue
+		}
+		bd.Spec.DeploymentID = bd.Spec.RollbackDeploymentID
+		bd.Spec.StagedDeploymentID = bd.Spec.RollbackDeploymentID
+		bd.Spec.Options = *bd.Spec.RollbackOptions.DeepCopy()
+		bd.Spec.StagedOptions = *bd.Spec.RollbackOptions.DeepCopy()
+	}
+
+	status.Rollback = &fleet.BundleRollbackStatus{
+		Generation: p.Targets[0].Bundle.Generation,
+		Partition:  p.Status.Name,
+		Clusters:   clusters,
+		Time:       metav1.NewTime(now),
+	}
+	p.Status.FailingSince = nil
+	p.Status.HeldReason = rolledBackReason
+
+	return true
+}
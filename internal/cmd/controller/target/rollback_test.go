@@ -0,0 +1,157 @@
+package target
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rancher/wrangler/v3/pkg/genericcondition"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// rollbackTargets returns two targets in separate partitions, which have been
+// ready on deployment "v1" and are now staged with deployment "v2".
+func rollbackTargets() []*Target {
+	bundle := &fleet.Bundle{
+		ObjectMeta: metav1.ObjectMeta{Name: "bundle", Generation: 2},
+		Spec: fleet.BundleSpec{RolloutStrategy: &fleet.RolloutStrategy{
+			AutoRollback: &fleet.AutoRollback{Enabled: true, Timeout: &metav1.Duration{Duration: 5 * time.Minute}},
+			Partitions: []fleet.Partition{
+				{Name: "canary", ClusterName: "canary"},
+				{Name: "prod", ClusterName: "prod"},
+			},
+		}},
+	}
+
+	var targets []*Target
+	for _, name := range []string{"canary", "prod"} {
+		targets = append(targets, &Target{
+			Cluster: &fleet.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-default", Name: name}},
+			Bundle:  bundle,
+			Options: fleet.BundleDeploymentOptions{DefaultNamespace: "v2"},
+			Deployment: &fleet.BundleDeployment{
+				Spec: fleet.BundleDeploymentSpec{
+					DeploymentID:       "v1",
+					StagedDeploymentID: "v1",
+					Options:            fleet.BundleDeploymentOptions{DefaultNamespace: "v1"},
+					StagedOptions:      fleet.BundleDeploymentOptions{DefaultNamespace: "v1"},
+				},
+				Status: fleet.BundleDeploymentStatus{AppliedDeploymentID: "v1", Ready: true},
+			},
+			DeploymentID: "v2",
+		})
+	}
+	return targets
+}
+
+// failDeployment makes the agent report that deploying the current
+// deployment ID failed.
+func failDeployment(t *Target) {
+	t.Deployment.Status.Ready = false
+	t.Deployment.Status.Conditions = []genericcondition.GenericCondition{
+		{Type: fleet.BundleDeploymentConditionDeployed, Status: "False", Message: "install failed"},
+	}
+}
+
+func TestUpdatePartitionsAutoRollback(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	targets := rollbackTargets()
+	canary, prod := targets[0], targets[1]
+	// only allow one unavailable cluster, so prod is not updated together with canary
+	status := &fleet.BundleStatus{MaxNew: 50, MaxUnavailable: 1}
+
+	update := func(at time.Time) *Promotion {
+		t.Helper()
+		promotion := &Promotion{Now: func() time.Time { return at }}
+		status.Unavailable = Unavailable(targets)
+		if err := UpdatePartitions(status, targets, promotion); err != nil {
+			t.Fatalf("UpdatePartitions() failed: %v", err)
+		}
+		return promotion
+	}
+
+	// canary is promoted to v2 and remembers v1 as known-good
+	update(now)
+	if canary.Deployment.Spec.DeploymentID != "v2" {
+		t.Fatalf("canary was not promoted, deployment ID is %q", canary.Deployment.Spec.DeploymentID)
+	}
+	if canary.Deployment.Spec.RollbackDeploymentID != "v1" ||
+		canary.Deployment.Spec.RollbackOptions == nil ||
+		canary.Deployment.Spec.RollbackOptions.DefaultNamespace != "v1" {
+		t.Fatalf("canary did not record v1 as known-good: %+v", canary.Deployment.Spec)
+	}
+
+	// canary fails to deploy v2, the timeout starts
+	canary.Deployment.Status.AppliedDeploymentID = "v2"
+	canary.Deployment.Status.Ready = false
+	promotion := update(now)
+	if status.Rollback != nil {
+		t.Fatalf("rolled back before the timeout expired")
+	}
+	if promotion.RequeueAfter != 5*time.Minute {
+		t.Errorf("expected requeue after timeout, got %v", promotion.RequeueAfter)
+	}
+	if status.PartitionStatus[0].FailingSince == nil {
+		t.Fatalf("expected canary partition to be failing")
+	}
+
+	// after the timeout the canary partition is rolled back
+	failDeployment(canary)
+	canary.Deployment.Status.AppliedDeploymentID = "v1"
+	update(now.Add(5 * time.Minute))
+	if status.Rollback == nil {
+		t.Fatalf("expected a rollback")
+	}
+	if got := status.Rollback.Clusters; len(got) != 1 || got[0] != "fleet-default/canary" {
+		t.Errorf("unexpected failing clusters %v", got)
+	}
+	if canary.Deployment.Spec.DeploymentID != "v1" || canary.Deployment.Spec.StagedDeploymentID != "v1" {
+		t.Errorf("canary was not rolled back: %+v", canary.Deployment.Spec)
+	}
+	if canary.Deployment.Spec.Options.DefaultNamespace != "v1" {
+		t.Errorf("canary options were not rolled back: %+v", canary.Deployment.Spec.Options)
+	}
+
+	// staging stays halted for this generation of the bundle
+	canary.Deployment.Status = fleet.BundleDeploymentStatus{AppliedDeploymentID: "v1", Ready: true}
+	update(now.Add(10 * time.Minute))
+	if canary.Deployment.Spec.DeploymentID != "v1" || prod.Deployment.Spec.DeploymentID != "v1" {
+		t.Errorf("staging was not halted: canary %q, prod %q", canary.Deployment.Spec.DeploymentID, prod.Deployment.Spec.DeploymentID)
+	}
+	if status.PartitionStatus[0].HeldReason != rolledBackReason {
+		t.Errorf("unexpected held reason %q", status.PartitionStatus[0].HeldReason)
+	}
+
+	// a new generation of the bundle resumes the rollout
+	canary.Bundle.Generation++
+	update(now.Add(15 * time.Minute))
+	if status.Rollback != nil {
+		t.Errorf("expected rollback status to be cleared")
+	}
+	if canary.Deployment.Spec.DeploymentID != "v2" {
+		t.Errorf("rollout did not resume, deployment ID is %q", canary.Deployment.Spec.DeploymentID)
+	}
+}
+
+func Test_recordKnownGood(t *testing.T) {
+	targets := rollbackTargets()
+
+	// not ready deployments are not recorded
+	targets[0].Deployment.Status.Ready = false
+	recordKnownGood(targets[0])
+	if targets[0].Deployment.Spec.RollbackDeploymentID != "" {
+		t.Errorf("recorded a deployment which is not ready")
+	}
+
+	// rollback targets are cleared once automatic rollbacks are disabled
+	recordKnownGood(targets[1])
+	if targets[1].Deployment.Spec.RollbackDeploymentID != "v1" {
+		t.Fatalf("did not record a ready deployment")
+	}
+	targets[1].Bundle.Spec.RolloutStrategy.AutoRollback.Enabled = false
+	recordKnownGood(targets[1])
+	if targets[1].Deployment.Spec.RollbackDeploymentID != "" || targets[1].Deployment.Spec.RollbackOptions != nil {
+		t.Errorf("rollback target was not cleared")
+	}
+}
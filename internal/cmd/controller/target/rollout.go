@@ -42,7 +42,7 @@ func manualPartition(rollout *fleet.RolloutStrategy, targets []*Target) ([]parti
 		partitions []partition
 	)
 
-	for _, partitionDef := range rollout.Partitions {
+	for i, partitionDef := range rollout.Partitions {
 		matcher, err := matcher.NewClusterMatcher(partitionDef.ClusterName, partitionDef.ClusterGroup, partitionDef.ClusterGroupSelector, partitionDef.ClusterSelector)
 		if err != nil {
 			return nil, err
@@ -67,6 +67,7 @@ func manualPartition(rollout *fleet.RolloutStrategy, targets []*Target) ([]parti
 		if err != nil {
 			return nil, err
 		}
+		partitions[len(partitions)-1].Definition = &rollout.Partitions[i]
 	}
 
 	return partitions, nil
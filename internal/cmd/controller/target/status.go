@@ -10,6 +10,8 @@ import (
 	"github.com/rancher/fleet/internal/cmd/controller/summary"
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
 
+	"github.com/rancher/wrangler/v3/pkg/condition"
+
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
@@ -33,7 +35,7 @@ func MaxUnavailable(targets []*Target) (int, error) {
 // Unavailable counts the number of targets that are not available (pure function)
 func Unavailable(targets []*Target) (count int) {
 	for _, target := range targets {
-		if target.Deployment == nil {
+		if target.Deployment == nil || ignoreOffline(target) {
 			continue
 		}
 		if isUnavailable(target.Deployment) {
@@ -51,6 +53,9 @@ func updatePartitionStatus(partitionStatus *fleet.PartitionStatus, targets []*Ta
 	// For a partition a target must be available and up-to-date.
 	partitionStatus.Unavailable = 0
 	for _, target := range targets {
+		if ignoreOffline(target) {
+			continue
+		}
 		if !upToDate(target) || isUnavailable(target.Deployment) {
 			partitionStatus.Unavailable++
 		}
@@ -82,6 +87,17 @@ func isUnavailable(target *fleet.BundleDeployment) bool {
 		!target.Status.Ready
 }
 
+// ignoreOffline returns true if the target's cluster is offline and the
+// rollout strategy does not count offline clusters as unavailable (pure
+// function). Such targets neither block nor count against the rollout.
+func ignoreOffline(target *Target) bool {
+	if target.Cluster == nil || !condition.Cond(fleet.ClusterConditionOffline).IsTrue(&target.Cluster.Status) {
+		return false
+	}
+	rollout := target.Bundle.Spec.RolloutStrategy
+	return rollout == nil || rollout.OfflineClusterPolicy != fleet.OfflineClusterPolicyUnavailable
+}
+
 // limit calculates the maximum number of unavailable items. It uses the first
 // non-nil value from the provided values. If no value is provided, it defaults
 // to a predefined limit. If a percentage is provided, it calculates the
@@ -3,6 +3,8 @@ package target
 import (
 	"testing"
 
+	"github.com/rancher/wrangler/v3/pkg/genericcondition"
+
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -529,3 +531,35 @@ func TestMaxUnavailable(t *testing.T) {
 		})
 	}
 }
+
+// offlineTarget marks the target's cluster offline.
+func offlineTarget(target *Target) *Target {
+	target.Cluster = &fleet.Cluster{Status: fleet.ClusterStatus{
+		Conditions: []genericcondition.GenericCondition{{Type: fleet.ClusterConditionOffline, Status: "True"}},
+	}}
+	if target.Bundle == nil {
+		target.Bundle = &fleet.Bundle{}
+	}
+	return target
+}
+
+func TestUnavailableOffline(t *testing.T) {
+	targets := []*Target{
+		availableTarget(),
+		unavailableTargetNonReady(),
+		offlineTarget(unavailableTargetNonReady()),
+	}
+	if got := Unavailable(targets); got != 1 {
+		t.Errorf("Unavailable() = %v, want 1, offline clusters are ignored by default", got)
+	}
+
+	partitionStatus := &fleet.PartitionStatus{MaxUnavailable: 1}
+	if updatePartitionStatus(partitionStatus, targets) || partitionStatus.Unavailable != 1 {
+		t.Errorf("expected the offline cluster not to count against the partition, got %d unavailable", partitionStatus.Unavailable)
+	}
+
+	targets[2].Bundle.Spec.RolloutStrategy = &fleet.RolloutStrategy{OfflineClusterPolicy: fleet.OfflineClusterPolicyUnavailable}
+	if got := Unavailable(targets); got != 2 {
+		t.Errorf("Unavailable() = %v, want 2, offline clusters are unavailable by policy", got)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/yaml"
 
 	"github.com/rancher/fleet/pkg/version"
@@ -41,6 +42,11 @@ const (
 	// APIServerCAKey is the key which contains the CA of the upstream
 	// server.
 	APIServerCAKey = "apiServerCA"
+	// ClusterRegistrationTokenKey is the key which contains the name of
+	// the cluster registration token. It is used in the cluster
+	// registration secret "import-NAME" and the fleet-agent-bootstrap
+	// secret.
+	ClusterRegistrationTokenKey = "clusterRegistrationToken"
 
 	// Default secret name for git credentials, used as a fallback if no secret is referenced by an app.
 	DefaultGitCredentialsSecretName = "gitcredential" //nolint:gosec // this is a resource name
@@ -77,6 +83,11 @@ type Config struct {
 	// AgentCheckinInterval determines how often agents update their clusters status, defaults to 15m
 	AgentCheckinInterval metav1.Duration `json:"agentCheckinInterval,omitempty"`
 
+	// ClusterOfflineCheckinMultiplier is the number of agent check-in
+	// intervals, after which a cluster, whose agent did not check in, is
+	// marked offline. Defaults to 3.
+	ClusterOfflineCheckinMultiplier int `json:"clusterOfflineCheckinMultiplier,omitempty"`
+
 	// ManageAgent if present and set to false, no bundles will be created to manage agents
 	ManageAgent *bool `json:"manageAgent,omitempty"`
 
@@ -112,6 +123,15 @@ type Config struct {
 	// IgnoreClusterRegistrationLabels if set to true, the labels on the cluster registration resource will not be copied to the cluster resource.
 	IgnoreClusterRegistrationLabels bool `json:"ignoreClusterRegistrationLabels,omitempty"`
 
+	// ClusterRegistrationApproval if set to true, cluster registrations
+	// stay pending until they are approved, regardless of the token used.
+	ClusterRegistrationApproval bool `json:"clusterRegistrationApproval,omitempty"`
+
+	// AgentRolloutStrategy controls how the agent bundles of clusters are
+	// updated, e.g. after an upgrade of fleet-controller changed the agent
+	// image. If not set, all agent bundles are updated at once.
+	AgentRolloutStrategy *AgentRolloutStrategy `json:"agentRolloutStrategy,omitempty"`
+
 	// AgentTLSMode supports two values: `system-store` and `strict`. If set to `system-store`, instructs the agent
 	// to trust CA bundles from the operating system's store. If set to `strict`, then the agent shall only connect
 	// to a server which uses the exact CA configured when creating/updating the agent.
@@ -126,6 +146,11 @@ type Config struct {
 	// GarbageCollectionInterval determines how often agents clean up obsolete Helm releases.
 	GarbageCollectionInterval metav1.Duration `json:"garbageCollectionInterval,omitempty"`
 
+	// IgnoreManagers are the names of field managers, whose fields agents
+	// ignore when checking deployed resources for modifications. They are
+	// added to the diff options of every bundle deployment.
+	IgnoreManagers []string `json:"ignoreManagers,omitempty"`
+
 	// AgentWorkers specifies the maximum number of workers for each agent reconciler.
 	AgentWorkers AgentWorkers `json:"agentWorkers,omitempty"`
 }
@@ -135,6 +160,35 @@ type AgentWorkers struct {
 	Drift            string `json:"drift,omitempty"`
 }
 
+// AgentRolloutStrategy updates the agents of clusters partition by partition.
+// The next partition is updated, once all agents of the previous partitions
+// checked in with their new revision.
+type AgentRolloutStrategy struct {
+	// MaxUnavailable is a number or percentage of clusters, whose agents
+	// are updated at the same time. Default: 10%
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+	// Partitions select clusters by their labels. A cluster belongs to the
+	// first matching partition. Clusters, which match no partition, are
+	// updated last.
+	Partitions []AgentRolloutPartition `json:"partitions,omitempty"`
+	// CheckinTimeout is how long an updated agent can take to check in.
+	// If any agent takes longer, the rollout is paused, until it checks in
+	// or the agent changes again. Default: 15m
+	CheckinTimeout metav1.Duration `json:"checkinTimeout,omitempty"`
+	// OfflineClusterPolicy defines how offline clusters are treated, see
+	// RolloutStrategy. Default: Ignore
+	OfflineClusterPolicy string `json:"offlineClusterPolicy,omitempty"`
+}
+
+type AgentRolloutPartition struct {
+	Name string `json:"name,omitempty"`
+	// ClusterSelector selects the clusters of the partition by label.
+	ClusterSelector *metav1.LabelSelector `json:"clusterSelector,omitempty"`
+	// MaxUnavailable overrides the strategy's MaxUnavailable for the
+	// clusters of this partition.
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
 type Bootstrap struct {
 	Namespace      string `json:"namespace,omitempty"`
 	AgentNamespace string `json:"agentNamespace,omitempty"`
@@ -0,0 +1,141 @@
+// Package dependson resolves the dependsOn references of bundles.
+//
+// The agent uses the selectors to find the bundle deployments a bundle
+// deployment depends on, while the bundle controller uses them to detect
+// dependency cycles between bundles.
+package dependson
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Empty returns true if the reference does not refer to any bundle. This is
+// possible if there is a typo in the yaml.
+func Empty(ref fleet.BundleRef) bool {
+	return ref.Name == "" && ref.GitRepo == "" && ref.Selector == nil
+}
+
+// Selector returns a selector matching the labels of the bundle deployments
+// of the bundles referenced by ref. bundleNamespace is the namespace of the
+// depending bundle, it is used if the reference refers to a bundle or GitRepo
+// by name, but does not specify a namespace.
+func Selector(ref fleet.BundleRef, bundleNamespace string) (labels.Selector, error) {
+	ls := &metav1.LabelSelector{}
+	if ref.Selector != nil {
+		ls = ref.Selector.DeepCopy()
+	}
+
+	namespace := ref.Namespace
+	if namespace == "" && (ref.Name != "" || ref.GitRepo != "") {
+		namespace = bundleNamespace
+	}
+
+	// ref.Name is just a shortcut for matchLabels: {bundle-name: name}
+	if ref.Name != "" {
+		ls = metav1.AddLabelToSelector(ls, fleet.BundleLabel, ref.Name)
+	}
+	if ref.GitRepo != "" {
+		ls = metav1.AddLabelToSelector(ls, fleet.RepoLabel, ref.GitRepo)
+	}
+	if namespace != "" {
+		ls = metav1.AddLabelToSelector(ls, fleet.BundleNamespaceLabel, namespace)
+	}
+
+	return metav1.LabelSelectorAsSelector(ls)
+}
+
+// bundleLabels returns the labels of the bundle as they are set on its bundle
+// deployments, so the same selectors can be used for bundles and bundle
+// deployments.
+func bundleLabels(bundle *fleet.Bundle) labels.Set {
+	set := labels.Set{}
+	for k, v := range bundle.Labels {
+		set[k] = v
+	}
+	set[fleet.BundleLabel] = bundle.Name
+	set[fleet.BundleNamespaceLabel] = bundle.Namespace
+	return set
+}
+
+// Dependencies returns the bundles referenced by the dependsOn field of
+// bundle.
+func Dependencies(ctx context.Context, c client.Reader, bundle *fleet.Bundle) ([]*fleet.Bundle, error) {
+	var result []*fleet.Bundle
+	seen := map[client.ObjectKey]bool{}
+	for _, ref := range bundle.Spec.DependsOn {
+		if Empty(ref) {
+			continue
+		}
+
+		selector, err := Selector(ref, bundle.Namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		opts := []client.ListOption{client.UnsafeDisableDeepCopy}
+		if ns, ok := selector.RequiresExactMatch(fleet.BundleNamespaceLabel); ok {
+			opts = append(opts, client.InNamespace(ns))
+		}
+		bundles := &fleet.BundleList{}
+		if err := c.List(ctx, bundles, opts...); err != nil {
+			return nil, err
+		}
+
+		for i := range bundles.Items {
+			b := &bundles.Items[i]
+			key := client.ObjectKeyFromObject(b)
+			if seen[key] || !selector.Matches(bundleLabels(b)) {
+				continue
+			}
+			seen[key] = true
+			result = append(result, b)
+		}
+	}
+
+	return result, nil
+}
+
+// FindCycle returns the keys of the bundles forming a dependency cycle which
+// starts and ends at bundle, e.g. ["ns/a", "ns/b", "ns/a"]. It returns nil if
+// bundle is not part of a cycle.
+func FindCycle(ctx context.Context, c client.Reader, bundle *fleet.Bundle) ([]string, error) {
+	start := client.ObjectKeyFromObject(bundle)
+	visited := map[client.ObjectKey]bool{}
+
+	var visit func(b *fleet.Bundle, path []string) ([]string, error)
+	visit = func(b *fleet.Bundle, path []string) ([]string, error) {
+		deps, err := Dependencies(ctx, c, b)
+		if err != nil {
+			return nil, err
+		}
+		for _, dep := range deps {
+			key := client.ObjectKeyFromObject(dep)
+			if key == start {
+				return append(path, key.String()), nil
+			}
+			if visited[key] {
+				continue
+			}
+			visited[key] = true
+			if cycle, err := visit(dep, append(path, key.String())); err != nil || cycle != nil {
+				return cycle, err
+			}
+		}
+		return nil, nil
+	}
+
+	return visit(bundle, []string{start.String()})
+}
+
+// CycleError describes a dependency cycle, as returned by FindCycle.
+func CycleError(cycle []string) error {
+	return fmt.Errorf("dependency cycle detected: %s", strings.Join(cycle, " -> "))
+}
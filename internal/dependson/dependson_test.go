@@ -0,0 +1,172 @@
+package dependson_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/rancher/fleet/internal/dependson"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSelector(t *testing.T) {
+	tests := []struct {
+		name string
+		ref  fleet.BundleRef
+		want string
+	}{
+		{
+			name: "name defaults to the bundle's namespace",
+			ref:  fleet.BundleRef{Name: "db"},
+			want: "fleet.cattle.io/bundle-name=db,fleet.cattle.io/bundle-namespace=fleet-default",
+		},
+		{
+			name: "name in another namespace",
+			ref:  fleet.BundleRef{Name: "db", Namespace: "infra"},
+			want: "fleet.cattle.io/bundle-name=db,fleet.cattle.io/bundle-namespace=infra",
+		},
+		{
+			name: "gitrepo",
+			ref:  fleet.BundleRef{GitRepo: "infra", Namespace: "infra"},
+			want: "fleet.cattle.io/bundle-namespace=infra,fleet.cattle.io/repo-name=infra",
+		},
+		{
+			name: "selector matches all namespaces",
+			ref:  fleet.BundleRef{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"role": "root"}}},
+			want: "role=root",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := dependson.Selector(tt.ref, "fleet-default")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("Selector() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectorDoesNotModifyRef(t *testing.T) {
+	ref := fleet.BundleRef{Name: "db", Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"role": "root"}}}
+	if _, err := dependson.Selector(ref, "fleet-default"); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ref.Selector.MatchLabels, map[string]string{"role": "root"}) {
+		t.Errorf("selector of ref was modified: %v", ref.Selector.MatchLabels)
+	}
+}
+
+func bundle(namespace, name string, l map[string]string, deps ...fleet.BundleRef) *fleet.Bundle {
+	return &fleet.Bundle{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: l},
+		Spec:       fleet.BundleSpec{DependsOn: deps},
+	}
+}
+
+func newClient(t *testing.T, bundles ...*fleet.Bundle) client.Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := fleet.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	objs := make([]client.Object, 0, len(bundles))
+	for _, b := range bundles {
+		objs = append(objs, b)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func TestFindCycle(t *testing.T) {
+	repo := map[string]string{fleet.RepoLabel: "apps"}
+
+	tests := []struct {
+		name    string
+		bundles []*fleet.Bundle
+		want    []string
+	}{
+		{
+			name: "no dependencies",
+			bundles: []*fleet.Bundle{
+				bundle("fleet-default", "a", nil),
+			},
+		},
+		{
+			name: "chain without cycle",
+			bundles: []*fleet.Bundle{
+				bundle("fleet-default", "a", nil, fleet.BundleRef{Name: "b"}),
+				bundle("fleet-default", "b", nil, fleet.BundleRef{Name: "c", Namespace: "infra"}),
+				bundle("infra", "c", nil),
+			},
+		},
+		{
+			name: "cycle across namespaces",
+			bundles: []*fleet.Bundle{
+				bundle("fleet-default", "a", nil, fleet.BundleRef{Name: "b", Namespace: "infra"}),
+				bundle("infra", "b", nil, fleet.BundleRef{Name: "a", Namespace: "fleet-default"}),
+			},
+			want: []string{"fleet-default/a", "infra/b", "fleet-default/a"},
+		},
+		{
+			name: "cycle through gitrepo",
+			bundles: []*fleet.Bundle{
+				bundle("fleet-default", "a", repo, fleet.BundleRef{Name: "b"}),
+				bundle("fleet-default", "b", nil, fleet.BundleRef{GitRepo: "apps"}),
+			},
+			want: []string{"fleet-default/a", "fleet-default/b", "fleet-default/a"},
+		},
+		{
+			name: "cycle through selector",
+			bundles: []*fleet.Bundle{
+				bundle("fleet-default", "a", map[string]string{"role": "root"}, fleet.BundleRef{Name: "b"}),
+				bundle("fleet-default", "b", nil, fleet.BundleRef{Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"role": "root"},
+				}}),
+			},
+			want: []string{"fleet-default/a", "fleet-default/b", "fleet-default/a"},
+		},
+		{
+			name: "cycle not including the bundle",
+			bundles: []*fleet.Bundle{
+				bundle("fleet-default", "a", nil, fleet.BundleRef{Name: "b"}),
+				bundle("fleet-default", "b", nil, fleet.BundleRef{Name: "c"}),
+				bundle("fleet-default", "c", nil, fleet.BundleRef{Name: "b"}),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newClient(t, tt.bundles...)
+			got, err := dependson.FindCycle(context.TODO(), c, tt.bundles[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindCycle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDependenciesMatchBundleDeploymentLabels(t *testing.T) {
+	a := bundle("fleet-default", "a", nil, fleet.BundleRef{Selector: &metav1.LabelSelector{
+		MatchLabels: map[string]string{fleet.BundleLabel: "b"},
+	}})
+	b := bundle("infra", "b", nil)
+	c := newClient(t, a, b)
+
+	deps, err := dependson.Dependencies(context.TODO(), c, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deps) != 1 || deps[0].Name != "b" {
+		t.Errorf("expected dependency on bundle b, got %v", deps)
+	}
+}
@@ -0,0 +1,79 @@
+package forge
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const azureDevOpsAPIVersion = "7.1"
+
+// azureDevOps is a client for Azure DevOps Services, the token is a personal
+// access token.
+type azureDevOps struct {
+	*client
+	// project is "<organization>/<project>", repo the repository name.
+	project string
+	repo    string
+}
+
+// newAzureDevOps splits the repository path, which is either
+// "<organization>/<project>/_git/<repo>" for HTTPS URLs or
+// "v3/<organization>/<project>/<repo>" for SSH URLs.
+func newAzureDevOps(c *client) (*azureDevOps, error) {
+	if project, repo, found := strings.Cut(c.path, "/_git/"); found {
+		return &azureDevOps{client: c, project: project, repo: repo}, nil
+	}
+	if parts := strings.Split(c.path, "/"); len(parts) == 4 && parts[0] == "v3" {
+		return &azureDevOps{client: c, project: parts[1] + "/" + parts[2], repo: parts[3]}, nil
+	}
+	return nil, fmt.Errorf("cannot parse Azure DevOps repository path %q", c.path)
+}
+
+var azureDevOpsState = map[CommitState]string{
+	CommitStatePending: "pending",
+	CommitStateSuccess: "succeeded",
+	CommitStateFailure: "failed",
+}
+
+type azureDevOpsStatus struct {
+	State       string `json:"state"`
+	Description string `json:"description,omitempty"`
+	TargetURL   string `json:"targetUrl,omitempty"`
+	Context     struct {
+		Name  string `json:"name"`
+		Genre string `json:"genre"`
+	} `json:"context"`
+}
+
+func (a *azureDevOps) auth(req *http.Request) {
+	// personal access tokens are used as password with an empty user
+	req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(":"+a.token)))
+}
+
+func (a *azureDevOps) PullRequests(ctx context.Context) ([]PullRequest, error) {
+	return nil, fmt.Errorf("listing pull requests: %w", ErrNotSupported)
+}
+
+func (a *azureDevOps) SetCommitStatus(ctx context.Context, sha string, status CommitStatus) error {
+	body := azureDevOpsStatus{
+		State:       azureDevOpsState[status.State],
+		Description: status.Description,
+		TargetURL:   status.TargetURL,
+	}
+	body.Context.Name = status.Context
+	body.Context.Genre = "fleet"
+
+	path := fmt.Sprintf("/%s/_apis/git/repositories/%s/commits/%s/statuses?api-version=%s", a.project, a.repo, sha, azureDevOpsAPIVersion)
+	return a.post(ctx, path, a.auth, body)
+}
+
+func (a *azureDevOps) CreatePullRequest(ctx context.Context, opts PullRequestOptions) (PullRequest, error) {
+	return PullRequest{}, fmt.Errorf("creating pull requests: %w", ErrNotSupported)
+}
+
+func (a *azureDevOps) UpdatePullRequest(ctx context.Context, number int, opts PullRequestOptions) (PullRequest, error) {
+	return PullRequest{}, fmt.Errorf("updating pull requests: %w", ErrNotSupported)
+}
@@ -0,0 +1,57 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// bitbucket is a client for Bitbucket Cloud, the token is a repository or
+// workspace access token, or an app password if a username is set.
+type bitbucket struct {
+	*client
+}
+
+var bitbucketState = map[CommitState]string{
+	CommitStatePending: "INPROGRESS",
+	CommitStateSuccess: "SUCCESSFUL",
+	CommitStateFailure: "FAILED",
+}
+
+type bitbucketStatus struct {
+	State       string `json:"state"`
+	Key         string `json:"key"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url,omitempty"`
+}
+
+func (b *bitbucket) auth(req *http.Request) {
+	if b.username != "" {
+		req.SetBasicAuth(b.username, b.token)
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+b.token)
+}
+
+func (b *bitbucket) PullRequests(ctx context.Context) ([]PullRequest, error) {
+	return nil, fmt.Errorf("listing pull requests: %w", ErrNotSupported)
+}
+
+func (b *bitbucket) CreatePullRequest(ctx context.Context, opts PullRequestOptions) (PullRequest, error) {
+	return PullRequest{}, fmt.Errorf("creating pull requests: %w", ErrNotSupported)
+}
+
+func (b *bitbucket) UpdatePullRequest(ctx context.Context, number int, opts PullRequestOptions) (PullRequest, error) {
+	return PullRequest{}, fmt.Errorf("updating pull requests: %w", ErrNotSupported)
+}
+
+func (b *bitbucket) SetCommitStatus(ctx context.Context, sha string, status CommitStatus) error {
+	return b.post(ctx, fmt.Sprintf("/repositories/%s/commit/%s/statuses/build", b.path, sha), b.auth, bitbucketStatus{
+		State:       bitbucketState[status.State],
+		Key:         status.Context,
+		Name:        status.Context,
+		Description: status.Description,
+		URL:         status.TargetURL,
+	})
+}
@@ -0,0 +1,270 @@
+// Package forge provides minimal clients for the APIs of git hosting
+// providers, like GitHub, GitLab, Gitea, Bitbucket and Azure DevOps.
+//
+// The API base URL can be configured for all providers, so self-hosted
+// instances, or local stand-ins in tests, can be used.
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	GitHub      = "github"
+	GitLab      = "gitlab"
+	Gitea       = "gitea"
+	Bitbucket   = "bitbucket"
+	AzureDevOps = "azuredevops"
+
+	defaultGitHubAPIURL      = "https://api.github.com"
+	defaultGitLabAPIURL      = "https://gitlab.com/api/v4"
+	defaultBitbucketAPIURL   = "https://api.bitbucket.org/2.0"
+	defaultAzureDevOpsAPIURL = "https://dev.azure.com"
+
+	// pageSize is the number of items requested per page, pagination stops
+	// after maxPages.
+	pageSize = 100
+	maxPages = 10
+)
+
+// HTTPClient is shared by the API clients, its timeout keeps a slow
+// provider from blocking reconcilers and jobs.
+var HTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// PullRequest is an open pull request, or merge request in GitLab terms.
+type PullRequest struct {
+	Number     int
+	Title      string
+	HeadBranch string
+	HeadSHA    string
+	BaseBranch string
+	Labels     []string
+	// URL is the web page of the pull request.
+	URL string
+	// HeadRepo and BaseRepo identify the repositories of the head and the
+	// base branch. They differ for pull requests from forks.
+	HeadRepo string
+	BaseRepo string
+	// Author is the user name of the pull request's author.
+	Author string
+}
+
+// FromFork returns true if the head branch of the pull request is not in
+// the base repository, or if the head repository is unknown.
+func (p PullRequest) FromFork() bool {
+	return p.HeadRepo == "" || p.HeadRepo != p.BaseRepo
+}
+
+// HasLabels returns true if the pull request has all of the labels.
+func (p PullRequest) HasLabels(labels []string) bool {
+	for _, l := range labels {
+		found := false
+		for _, pl := range p.Labels {
+			if pl == l {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// PullRequestOptions is the content of a pull request, which is created or
+// updated.
+type PullRequestOptions struct {
+	Title string
+	Body  string
+	// HeadBranch and BaseBranch cannot be changed, when updating a pull
+	// request.
+	HeadBranch string
+	BaseBranch string
+}
+
+// CommitState is the state of a commit status, providers use different
+// names for these states.
+type CommitState string
+
+const (
+	CommitStatePending CommitState = "pending"
+	CommitStateSuccess CommitState = "success"
+	CommitStateFailure CommitState = "failure"
+)
+
+// CommitStatus is reported for a commit, it is shown next to the commit and
+// its pull requests by the provider.
+type CommitStatus struct {
+	State CommitState
+	// Context identifies the status, statuses with another context are
+	// not replaced.
+	Context     string
+	Description string
+	TargetURL   string
+}
+
+// ErrNotSupported is returned for operations which are not implemented for
+// a provider.
+var ErrNotSupported = errors.New("not supported by provider")
+
+// Client accesses the API of a git hosting provider for a single repository.
+type Client interface {
+	// PullRequests returns the open pull requests of the repository.
+	PullRequests(ctx context.Context) ([]PullRequest, error)
+	// SetCommitStatus creates or replaces the status with the same context
+	// for the commit.
+	SetCommitStatus(ctx context.Context, sha string, status CommitStatus) error
+	// CreatePullRequest opens a pull request from the head branch into the
+	// base branch.
+	CreatePullRequest(ctx context.Context, opts PullRequestOptions) (PullRequest, error)
+	// UpdatePullRequest replaces the title and the body of an open pull
+	// request.
+	UpdatePullRequest(ctx context.Context, number int, opts PullRequestOptions) (PullRequest, error)
+}
+
+// New returns a client for the repository at repoURL, which is hosted by
+// provider. apiURL and the credentials are optional. If httpClient is nil,
+// the shared HTTPClient is used.
+func New(provider, apiURL, repoURL string, creds Credentials, httpClient *http.Client) (Client, error) {
+	host, path, err := parseRepoURL(repoURL)
+	if err != nil {
+		return nil, err
+	}
+	if httpClient == nil {
+		httpClient = HTTPClient
+	}
+
+	c := &client{http: httpClient, username: creds.Username, token: creds.Token, path: path}
+	switch provider {
+	case GitHub:
+		c.apiURL = orDefault(apiURL, defaultGitHubAPIURL)
+		return &github{c}, nil
+	case GitLab:
+		c.apiURL = orDefault(apiURL, defaultGitLabAPIURL)
+		return &gitlab{c}, nil
+	case Gitea:
+		c.apiURL = orDefault(apiURL, "https://"+host+"/api/v1")
+		return &gitea{c}, nil
+	case Bitbucket:
+		c.apiURL = orDefault(apiURL, defaultBitbucketAPIURL)
+		return &bitbucket{c}, nil
+	case AzureDevOps:
+		c.apiURL = orDefault(apiURL, defaultAzureDevOpsAPIURL)
+		return newAzureDevOps(c)
+	}
+
+	return nil, fmt.Errorf("unsupported provider %q", provider)
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return strings.TrimSuffix(s, "/")
+}
+
+var scpLikeURL = regexp.MustCompile(`^(?:[\w.-]+@)?([\w.-]+):(.+)$`)
+
+// parseRepoURL returns the host and the path of a git repository URL, e.g.
+// "github.com" and "rancher/fleet" for "git@github.com:rancher/fleet.git".
+func parseRepoURL(repoURL string) (string, string, error) {
+	var host, path string
+	if u, err := url.Parse(repoURL); err == nil && u.Host != "" {
+		host, path = u.Hostname(), u.Path
+	} else if m := scpLikeURL.FindStringSubmatch(repoURL); m != nil {
+		host, path = m[1], m[2]
+	} else {
+		return "", "", fmt.Errorf("cannot parse repository URL %q", repoURL)
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if !strings.Contains(path, "/") {
+		return "", "", fmt.Errorf("repository URL %q does not contain an owner and a name", repoURL)
+	}
+
+	return host, path, nil
+}
+
+type client struct {
+	http     *http.Client
+	apiURL   string
+	username string
+	token    string
+	path     string
+}
+
+// get decodes the JSON response of a GET request to the API path into v.
+// header sets the authentication header, if a token is configured.
+func (c *client) get(ctx context.Context, path string, header func(*http.Request), v interface{}) error {
+	return c.do(ctx, http.MethodGet, path, header, nil, v)
+}
+
+// post sends body as JSON to the API path and discards the response.
+func (c *client) post(ctx context.Context, path string, header func(*http.Request), body interface{}) error {
+	return c.do(ctx, http.MethodPost, path, header, body, nil)
+}
+
+func (c *client) do(ctx context.Context, method, path string, header func(*http.Request), body, v interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.apiURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		header(req)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: unexpected status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// paginate calls page for increasing page numbers, until it returns less
+// than pageSize items.
+func paginate(page func(n int) (int, error)) error {
+	for n := 1; n <= maxPages; n++ {
+		count, err := page(n)
+		if err != nil {
+			return err
+		}
+		if count < pageSize {
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,372 @@
+package forge
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseRepoURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		wantHost string
+		wantPath string
+		wantErr  bool
+	}{
+		{url: "https://github.com/rancher/fleet", wantHost: "github.com", wantPath: "rancher/fleet"},
+		{url: "https://github.com/rancher/fleet.git", wantHost: "github.com", wantPath: "rancher/fleet"},
+		{url: "git@github.com:rancher/fleet.git", wantHost: "github.com", wantPath: "rancher/fleet"},
+		{url: "ssh://git@gitlab.example.com:2222/group/sub/repo.git", wantHost: "gitlab.example.com", wantPath: "group/sub/repo"},
+		{url: "https://github.com/rancher", wantErr: true},
+		{url: "not a url", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			host, path, err := parseRepoURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRepoURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if host != tt.wantHost || path != tt.wantPath {
+				t.Errorf("parseRepoURL() = %q, %q, want %q, %q", host, path, tt.wantHost, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestPullRequests(t *testing.T) {
+	want := func(headRepo, baseRepo string) []PullRequest {
+		return []PullRequest{{
+			Number:     7,
+			Title:      "Add feature",
+			HeadBranch: "feature",
+			HeadSHA:    "abc123",
+			BaseBranch: "main",
+			Labels:     []string{"preview"},
+			HeadRepo:   headRepo,
+			BaseRepo:   baseRepo,
+			Author:     "octocat",
+		}}
+	}
+
+	githubJSON := `[{"number":7,"title":"Add feature","head":{"ref":"feature","sha":"abc123","repo":{"full_name":"octocat/fleet"}},"base":{"ref":"main","repo":{"full_name":"rancher/fleet"}},"labels":[{"name":"preview"}],"user":{"login":"octocat"}}]`
+	gitlabJSON := `[{"iid":7,"title":"Add feature","source_branch":"feature","target_branch":"main","sha":"abc123","labels":["preview"],"source_project_id":2,"target_project_id":1,"author":{"username":"octocat"}}]`
+
+	tests := []struct {
+		provider   string
+		wantPath   string
+		wantHeader string
+		wantValue  string
+		body       string
+		want       []PullRequest
+	}{
+		{
+			provider:   GitHub,
+			wantPath:   "/repos/rancher/fleet/pulls",
+			wantHeader: "Authorization",
+			wantValue:  "Bearer secret",
+			body:       githubJSON,
+			want:       want("octocat/fleet", "rancher/fleet"),
+		},
+		{
+			provider:   GitLab,
+			wantPath:   "/projects/rancher%2Ffleet/merge_requests",
+			wantHeader: "PRIVATE-TOKEN",
+			wantValue:  "secret",
+			body:       gitlabJSON,
+			want:       want("2", "1"),
+		},
+		{
+			provider:   Gitea,
+			wantPath:   "/repos/rancher/fleet/pulls",
+			wantHeader: "Authorization",
+			wantValue:  "token secret",
+			body:       githubJSON,
+			want:       want("octocat/fleet", "rancher/fleet"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.EscapedPath() != tt.wantPath {
+					t.Errorf("unexpected path %q, want %q", r.URL.EscapedPath(), tt.wantPath)
+				}
+				if got := r.Header.Get(tt.wantHeader); got != tt.wantValue {
+					t.Errorf("unexpected %s header %q, want %q", tt.wantHeader, got, tt.wantValue)
+				}
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			c, err := New(tt.provider, srv.URL, "https://example.com/rancher/fleet.git", Credentials{Token: "secret"}, srv.Client())
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := c.PullRequests(context.TODO())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PullRequests() = %+v, want %+v", got, tt.want)
+			}
+			if !got[0].FromFork() {
+				t.Error("expected pull request to be from a fork")
+			}
+		})
+	}
+}
+
+func TestPullRequestsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad credentials", http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	c, err := New(GitHub, srv.URL, "https://github.com/rancher/fleet", Credentials{}, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.PullRequests(context.TODO()); err == nil {
+		t.Error("expected an error for unauthorized requests")
+	}
+}
+
+func TestHasLabels(t *testing.T) {
+	pr := PullRequest{Labels: []string{"preview", "bug"}}
+	if !pr.HasLabels(nil) {
+		t.Error("expected pull request to match empty labels")
+	}
+	if !pr.HasLabels([]string{"bug", "preview"}) {
+		t.Error("expected pull request to match all of its labels")
+	}
+	if pr.HasLabels([]string{"preview", "docs"}) {
+		t.Error("expected pull request not to match a missing label")
+	}
+}
+
+func TestNewUnsupportedProvider(t *testing.T) {
+	if _, err := New("gerrit", "", "https://gerrit.example.com/rancher/fleet", Credentials{}, nil); err == nil {
+		t.Error("expected an error for an unsupported provider")
+	}
+}
+
+func TestSetCommitStatus(t *testing.T) {
+	status := CommitStatus{
+		State:       CommitStateFailure,
+		Context:     "fleet/apps",
+		Description: "1/2 bundle deployments failed to deploy",
+		TargetURL:   "https://rancher.example.com",
+	}
+
+	tests := []struct {
+		provider   string
+		repoURL    string
+		wantPath   string
+		wantHeader string
+		wantValue  string
+		wantBody   string
+	}{
+		{
+			provider:   GitHub,
+			repoURL:    "https://github.com/rancher/fleet",
+			wantPath:   "/repos/rancher/fleet/statuses/abc123",
+			wantHeader: "Authorization",
+			wantValue:  "Bearer secret",
+			wantBody:   `{"state":"failure","context":"fleet/apps","description":"1/2 bundle deployments failed to deploy","target_url":"https://rancher.example.com"}`,
+		},
+		{
+			provider:   GitLab,
+			repoURL:    "https://gitlab.com/rancher/fleet",
+			wantPath:   "/projects/rancher%2Ffleet/statuses/abc123",
+			wantHeader: "PRIVATE-TOKEN",
+			wantValue:  "secret",
+			wantBody:   `{"state":"failed","name":"fleet/apps","description":"1/2 bundle deployments failed to deploy","target_url":"https://rancher.example.com"}`,
+		},
+		{
+			provider:   Gitea,
+			repoURL:    "https://gitea.example.com/rancher/fleet",
+			wantPath:   "/repos/rancher/fleet/statuses/abc123",
+			wantHeader: "Authorization",
+			wantValue:  "token secret",
+			wantBody:   `{"state":"failure","context":"fleet/apps","description":"1/2 bundle deployments failed to deploy","target_url":"https://rancher.example.com"}`,
+		},
+		{
+			provider:   Bitbucket,
+			repoURL:    "git@bitbucket.org:rancher/fleet.git",
+			wantPath:   "/repositories/rancher/fleet/commit/abc123/statuses/build",
+			wantHeader: "Authorization",
+			wantValue:  "Bearer secret",
+			wantBody:   `{"state":"FAILED","key":"fleet/apps","name":"fleet/apps","description":"1/2 bundle deployments failed to deploy","url":"https://rancher.example.com"}`,
+		},
+		{
+			provider:   AzureDevOps,
+			repoURL:    "https://dev.azure.com/rancher/fleet/_git/examples",
+			wantPath:   "/rancher/fleet/_apis/git/repositories/examples/commits/abc123/statuses",
+			wantHeader: "Authorization",
+			wantValue:  "Basic OnNlY3JldA==",
+			wantBody:   `{"state":"failed","description":"1/2 bundle deployments failed to deploy","targetUrl":"https://rancher.example.com","context":{"name":"fleet/apps","genre":"fleet"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost {
+					t.Errorf("unexpected method %s", r.Method)
+				}
+				if r.URL.EscapedPath() != tt.wantPath {
+					t.Errorf("unexpected path %q, want %q", r.URL.EscapedPath(), tt.wantPath)
+				}
+				if got := r.Header.Get(tt.wantHeader); got != tt.wantValue {
+					t.Errorf("unexpected %s header %q, want %q", tt.wantHeader, got, tt.wantValue)
+				}
+				body, _ := io.ReadAll(r.Body)
+				if string(body) != tt.wantBody {
+					t.Errorf("unexpected body %s, want %s", body, tt.wantBody)
+				}
+				w.WriteHeader(http.StatusCreated)
+			}))
+			defer srv.Close()
+
+			c, err := New(tt.provider, srv.URL, tt.repoURL, Credentials{Token: "secret"}, srv.Client())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := c.SetCommitStatus(context.TODO(), "abc123", status); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestBitbucketBasicAuth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "fleet" || password != "app-password" {
+			t.Errorf("unexpected basic auth %q, %q", user, password)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	c, err := New(Bitbucket, srv.URL, "git@bitbucket.org:rancher/fleet.git", Credentials{Username: "fleet", Token: "app-password"}, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetCommitStatus(context.TODO(), "abc123", CommitStatus{State: CommitStateSuccess, Context: "fleet/apps"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewAzureDevOpsSSH(t *testing.T) {
+	c, err := New(AzureDevOps, "", "git@ssh.dev.azure.com:v3/rancher/fleet/examples", Credentials{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := c.(*azureDevOps)
+	if a.project != "rancher/fleet" || a.repo != "examples" {
+		t.Errorf("unexpected project %q and repo %q", a.project, a.repo)
+	}
+}
+
+func TestCreateAndUpdatePullRequest(t *testing.T) {
+	opts := PullRequestOptions{
+		Title:      "Update images",
+		Body:       "Update from image update automation",
+		HeadBranch: "image-updates",
+		BaseBranch: "main",
+	}
+	want := PullRequest{
+		Number:     7,
+		Title:      "Update images",
+		HeadBranch: "image-updates",
+		BaseBranch: "main",
+		URL:        "https://example.com/rancher/fleet/pull/7",
+	}
+
+	githubJSON := `{"number":7,"title":"Update images","html_url":"https://example.com/rancher/fleet/pull/7","head":{"ref":"image-updates"},"base":{"ref":"main"}}`
+	gitlabJSON := `{"iid":7,"title":"Update images","web_url":"https://example.com/rancher/fleet/pull/7","source_branch":"image-updates","target_branch":"main"}`
+
+	tests := []struct {
+		provider     string
+		createPath   string
+		createBody   string
+		updateMethod string
+		updatePath   string
+		updateBody   string
+		response     string
+	}{
+		{
+			provider:     GitHub,
+			createPath:   "/repos/rancher/fleet/pulls",
+			createBody:   `{"title":"Update images","body":"Update from image update automation","head":"image-updates","base":"main"}`,
+			updateMethod: http.MethodPatch,
+			updatePath:   "/repos/rancher/fleet/pulls/7",
+			updateBody:   `{"title":"Update images","body":"Update from image update automation"}`,
+			response:     githubJSON,
+		},
+		{
+			provider:     GitLab,
+			createPath:   "/projects/rancher%2Ffleet/merge_requests",
+			createBody:   `{"title":"Update images","description":"Update from image update automation","source_branch":"image-updates","target_branch":"main"}`,
+			updateMethod: http.MethodPut,
+			updatePath:   "/projects/rancher%2Ffleet/merge_requests/7",
+			updateBody:   `{"title":"Update images","description":"Update from image update automation"}`,
+			response:     gitlabJSON,
+		},
+		{
+			provider:     Gitea,
+			createPath:   "/repos/rancher/fleet/pulls",
+			createBody:   `{"title":"Update images","body":"Update from image update automation","head":"image-updates","base":"main"}`,
+			updateMethod: http.MethodPatch,
+			updatePath:   "/repos/rancher/fleet/pulls/7",
+			updateBody:   `{"title":"Update images","body":"Update from image update automation"}`,
+			response:     githubJSON,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				switch {
+				case r.Method == http.MethodPost && r.URL.EscapedPath() == tt.createPath:
+					if string(body) != tt.createBody {
+						t.Errorf("unexpected create body %s, want %s", body, tt.createBody)
+					}
+					w.WriteHeader(http.StatusCreated)
+				case r.Method == tt.updateMethod && r.URL.EscapedPath() == tt.updatePath:
+					if string(body) != tt.updateBody {
+						t.Errorf("unexpected update body %s, want %s", body, tt.updateBody)
+					}
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.EscapedPath())
+					http.NotFound(w, r)
+					return
+				}
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer srv.Close()
+
+			c, err := New(tt.provider, srv.URL, "https://example.com/rancher/fleet.git", Credentials{Token: "secret"}, srv.Client())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := c.CreatePullRequest(context.TODO(), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("CreatePullRequest() = %+v, want %+v", got, want)
+			}
+
+			got, err = c.UpdatePullRequest(context.TODO(), 7, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("UpdatePullRequest() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
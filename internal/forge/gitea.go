@@ -0,0 +1,56 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// gitea uses the same pull request representation as GitHub.
+type gitea struct {
+	*client
+}
+
+func (g *gitea) auth(req *http.Request) {
+	req.Header.Set("Authorization", "token "+g.token)
+}
+
+func (g *gitea) PullRequests(ctx context.Context) ([]PullRequest, error) {
+	var result []PullRequest
+	err := paginate(func(n int) (int, error) {
+		var prs []githubPullRequest
+		path := fmt.Sprintf("/repos/%s/pulls?state=open&limit=%d&page=%d", g.path, pageSize, n)
+		if err := g.get(ctx, path, g.auth, &prs); err != nil {
+			return 0, err
+		}
+		for _, pr := range prs {
+			result = append(result, pr.toPullRequest())
+		}
+		return len(prs), nil
+	})
+	return result, err
+}
+
+func (g *gitea) SetCommitStatus(ctx context.Context, sha string, status CommitStatus) error {
+	return g.post(ctx, fmt.Sprintf("/repos/%s/statuses/%s", g.path, sha), g.auth, toGitHubStatus(status))
+}
+
+func (g *gitea) CreatePullRequest(ctx context.Context, opts PullRequestOptions) (PullRequest, error) {
+	var pr githubPullRequest
+	err := g.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/pulls", g.path), g.auth, githubNewPullRequest{
+		Title: opts.Title,
+		Body:  opts.Body,
+		Head:  opts.HeadBranch,
+		Base:  opts.BaseBranch,
+	}, &pr)
+	return pr.toPullRequest(), err
+}
+
+func (g *gitea) UpdatePullRequest(ctx context.Context, number int, opts PullRequestOptions) (PullRequest, error) {
+	var pr githubPullRequest
+	err := g.do(ctx, http.MethodPatch, fmt.Sprintf("/repos/%s/pulls/%d", g.path, number), g.auth, githubNewPullRequest{
+		Title: opts.Title,
+		Body:  opts.Body,
+	}, &pr)
+	return pr.toPullRequest(), err
+}
@@ -0,0 +1,135 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+type github struct {
+	*client
+}
+
+type githubPullRequest struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	HTMLURL string `json:"html_url"`
+	Head    struct {
+		Ref  string      `json:"ref"`
+		SHA  string      `json:"sha"`
+		Repo *githubRepo `json:"repo"`
+	} `json:"head"`
+	Base struct {
+		Ref  string      `json:"ref"`
+		Repo *githubRepo `json:"repo"`
+	} `json:"base"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	User struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+// githubRepo is null for the head repository of a deleted fork.
+type githubRepo struct {
+	FullName string `json:"full_name"`
+}
+
+func (pr githubPullRequest) toPullRequest() PullRequest {
+	p := PullRequest{
+		Number:     pr.Number,
+		Title:      pr.Title,
+		HeadBranch: pr.Head.Ref,
+		HeadSHA:    pr.Head.SHA,
+		BaseBranch: pr.Base.Ref,
+		URL:        pr.HTMLURL,
+		Author:     pr.User.Login,
+	}
+	if pr.Head.Repo != nil {
+		p.HeadRepo = pr.Head.Repo.FullName
+	}
+	if pr.Base.Repo != nil {
+		p.BaseRepo = pr.Base.Repo.FullName
+	}
+	for _, l := range pr.Labels {
+		p.Labels = append(p.Labels, l.Name)
+	}
+	return p
+}
+
+func (g *github) auth(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+g.token)
+}
+
+func (g *github) PullRequests(ctx context.Context) ([]PullRequest, error) {
+	var result []PullRequest
+	err := paginate(func(n int) (int, error) {
+		var prs []githubPullRequest
+		path := fmt.Sprintf("/repos/%s/pulls?state=open&per_page=%d&page=%d", g.path, pageSize, n)
+		if err := g.get(ctx, path, g.auth, &prs); err != nil {
+			return 0, err
+		}
+		for _, pr := range prs {
+			result = append(result, pr.toPullRequest())
+		}
+		return len(prs), nil
+	})
+	return result, err
+}
+
+// githubNewPullRequest is the request to create or update a pull request,
+// which Gitea uses as well.
+type githubNewPullRequest struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	Head  string `json:"head,omitempty"`
+	Base  string `json:"base,omitempty"`
+}
+
+func (g *github) CreatePullRequest(ctx context.Context, opts PullRequestOptions) (PullRequest, error) {
+	var pr githubPullRequest
+	err := g.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/pulls", g.path), g.auth, githubNewPullRequest{
+		Title: opts.Title,
+		Body:  opts.Body,
+		Head:  opts.HeadBranch,
+		Base:  opts.BaseBranch,
+	}, &pr)
+	return pr.toPullRequest(), err
+}
+
+func (g *github) UpdatePullRequest(ctx context.Context, number int, opts PullRequestOptions) (PullRequest, error) {
+	var pr githubPullRequest
+	err := g.do(ctx, http.MethodPatch, fmt.Sprintf("/repos/%s/pulls/%d", g.path, number), g.auth, githubNewPullRequest{
+		Title: opts.Title,
+		Body:  opts.Body,
+	}, &pr)
+	return pr.toPullRequest(), err
+}
+
+// githubState maps commit states to GitHub's, which Gitea uses as well.
+var githubState = map[CommitState]string{
+	CommitStatePending: "pending",
+	CommitStateSuccess: "success",
+	CommitStateFailure: "failure",
+}
+
+type githubStatus struct {
+	State       string `json:"state"`
+	Context     string `json:"context,omitempty"`
+	Description string `json:"description,omitempty"`
+	TargetURL   string `json:"target_url,omitempty"`
+}
+
+func toGitHubStatus(status CommitStatus) githubStatus {
+	return githubStatus{
+		State:       githubState[status.State],
+		Context:     status.Context,
+		Description: status.Description,
+		TargetURL:   status.TargetURL,
+	}
+}
+
+func (g *github) SetCommitStatus(ctx context.Context, sha string, status CommitStatus) error {
+	return g.post(ctx, fmt.Sprintf("/repos/%s/statuses/%s", g.path, sha), g.auth, toGitHubStatus(status))
+}
@@ -0,0 +1,125 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type gitlab struct {
+	*client
+}
+
+type gitlabMergeRequest struct {
+	IID          int      `json:"iid"`
+	Title        string   `json:"title"`
+	SourceBranch string   `json:"source_branch"`
+	TargetBranch string   `json:"target_branch"`
+	SHA          string   `json:"sha"`
+	Labels       []string `json:"labels"`
+	WebURL       string   `json:"web_url"`
+	// SourceProjectID differs from TargetProjectID for merge requests
+	// from forks.
+	SourceProjectID int `json:"source_project_id"`
+	TargetProjectID int `json:"target_project_id"`
+	Author          struct {
+		Username string `json:"username"`
+	} `json:"author"`
+}
+
+func (mr gitlabMergeRequest) toPullRequest() PullRequest {
+	p := PullRequest{
+		Number:     mr.IID,
+		Title:      mr.Title,
+		HeadBranch: mr.SourceBranch,
+		HeadSHA:    mr.SHA,
+		BaseBranch: mr.TargetBranch,
+		Labels:     mr.Labels,
+		URL:        mr.WebURL,
+		Author:     mr.Author.Username,
+	}
+	if mr.SourceProjectID != 0 {
+		p.HeadRepo = strconv.Itoa(mr.SourceProjectID)
+	}
+	if mr.TargetProjectID != 0 {
+		p.BaseRepo = strconv.Itoa(mr.TargetProjectID)
+	}
+	return p
+}
+
+func (g *gitlab) auth(req *http.Request) {
+	req.Header.Set("PRIVATE-TOKEN", g.token)
+}
+
+// project returns the URL encoded project path, which GitLab accepts
+// instead of the numerical project ID.
+func (g *gitlab) project() string {
+	return url.PathEscape(g.path)
+}
+
+func (g *gitlab) PullRequests(ctx context.Context) ([]PullRequest, error) {
+	var result []PullRequest
+	err := paginate(func(n int) (int, error) {
+		var mrs []gitlabMergeRequest
+		path := fmt.Sprintf("/projects/%s/merge_requests?state=opened&per_page=%d&page=%d", g.project(), pageSize, n)
+		if err := g.get(ctx, path, g.auth, &mrs); err != nil {
+			return 0, err
+		}
+		for _, mr := range mrs {
+			result = append(result, mr.toPullRequest())
+		}
+		return len(mrs), nil
+	})
+	return result, err
+}
+
+type gitlabNewMergeRequest struct {
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	SourceBranch string `json:"source_branch,omitempty"`
+	TargetBranch string `json:"target_branch,omitempty"`
+}
+
+func (g *gitlab) CreatePullRequest(ctx context.Context, opts PullRequestOptions) (PullRequest, error) {
+	var mr gitlabMergeRequest
+	err := g.do(ctx, http.MethodPost, fmt.Sprintf("/projects/%s/merge_requests", g.project()), g.auth, gitlabNewMergeRequest{
+		Title:        opts.Title,
+		Description:  opts.Body,
+		SourceBranch: opts.HeadBranch,
+		TargetBranch: opts.BaseBranch,
+	}, &mr)
+	return mr.toPullRequest(), err
+}
+
+func (g *gitlab) UpdatePullRequest(ctx context.Context, number int, opts PullRequestOptions) (PullRequest, error) {
+	var mr gitlabMergeRequest
+	err := g.do(ctx, http.MethodPut, fmt.Sprintf("/projects/%s/merge_requests/%d", g.project(), number), g.auth, gitlabNewMergeRequest{
+		Title:       opts.Title,
+		Description: opts.Body,
+	}, &mr)
+	return mr.toPullRequest(), err
+}
+
+var gitlabState = map[CommitState]string{
+	CommitStatePending: "running",
+	CommitStateSuccess: "success",
+	CommitStateFailure: "failed",
+}
+
+type gitlabStatus struct {
+	State       string `json:"state"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	TargetURL   string `json:"target_url,omitempty"`
+}
+
+func (g *gitlab) SetCommitStatus(ctx context.Context, sha string, status CommitStatus) error {
+	return g.post(ctx, fmt.Sprintf("/projects/%s/statuses/%s", g.project(), sha), g.auth, gitlabStatus{
+		State:       gitlabState[status.State],
+		Name:        status.Context,
+		Description: status.Description,
+		TargetURL:   status.TargetURL,
+	})
+}
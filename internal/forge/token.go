@@ -0,0 +1,62 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+
+	httpgit "github.com/go-git/go-git/v5/plumbing/transport/http"
+
+	"github.com/rancher/fleet/pkg/git"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TokenKey is the key of an API token in a secret, it takes precedence over
+// git credentials.
+const TokenKey = "token"
+
+// Credentials authenticate requests to the API of a provider.
+type Credentials struct {
+	// Username is only set for basic auth secrets. Providers, which
+	// support basic auth, use it together with the token as password.
+	Username string
+	Token    string
+}
+
+// LoadCredentials returns the API credentials from the secret. Besides a
+// "token" key, the same credentials as for cloning are supported: basic auth
+// secrets and GitHub App credentials, which are exchanged for an installation
+// token.
+func LoadCredentials(ctx context.Context, c crclient.Client, namespace, name, repoURL string) (Credentials, error) {
+	if name == "" {
+		return Credentials{}, nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, secret); err != nil {
+		return Credentials{}, fmt.Errorf("failed to get API secret: %w", err)
+	}
+	if token, ok := secret.Data[TokenKey]; ok {
+		return Credentials{Token: string(token)}, nil
+	}
+
+	auth, err := git.GetAuthFromSecret(repoURL, secret, "")
+	if err != nil {
+		return Credentials{}, err
+	}
+	switch a := auth.(type) {
+	case nil:
+		return Credentials{}, nil
+	case *httpgit.BasicAuth:
+		// basic auth secrets without a username store the
+		// password as username
+		if a.Password == "" {
+			return Credentials{Token: a.Username}, nil
+		}
+		return Credentials{Username: a.Username, Token: a.Password}, nil
+	}
+
+	return Credentials{}, fmt.Errorf("secret %s/%s of type %s cannot be used for API access", namespace, name, secret.Type)
+}
@@ -0,0 +1,156 @@
+package helmdeployer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/rancher/fleet/internal/cmd/agent/deployer/desiredset"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// helmReleaseNameAnnotation and helmReleaseNamespaceAnnotation are
+	// added by helm to the resources of a release.
+	helmReleaseNameAnnotation      = "meta.helm.sh/release-name"
+	helmReleaseNamespaceAnnotation = "meta.helm.sh/release-namespace"
+)
+
+// ConflictError is returned, if resources of a bundle deployment are already
+// owned by another bundle deployment on the cluster.
+type ConflictError struct {
+	// Owner is the ID of the bundle deployment, which owns the resources.
+	Owner string
+	// Resources lists the conflicting resources as "kind namespace/name".
+	Resources []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("resources are owned by bundle deployment %s: %s", e.Owner, strings.Join(e.Resources, ", "))
+}
+
+// owners maps the set IDs and helm releases of the deployed bundles to their
+// bundle IDs.
+type owners struct {
+	setIDs   map[string]string
+	releases map[string]string
+}
+
+func (h *Helm) newOwners(deployed []DeployedBundle) owners {
+	o := owners{setIDs: map[string]string{}, releases: map[string]string{}}
+	for _, d := range deployed {
+		o.setIDs[desiredset.GetSetID(d.BundleID, h.labelPrefix, h.labelSuffix)] = d.BundleID
+		if !d.ServerSideApply {
+			o.releases[d.ReleaseName] = d.BundleID
+		}
+	}
+	return o
+}
+
+// owner returns the ID of the deployed bundle, which owns the live object.
+// The owner is found by the set ID annotation of fleet's post renderer and by
+// helm's release annotations. Objects of bundles, which are no longer
+// deployed, e.g. kept CRDs, have no owner.
+func (o owners) owner(live *unstructured.Unstructured) string {
+	annotations := live.GetAnnotations()
+	if bundleID, ok := o.setIDs[annotations[desiredset.LabelID]]; ok {
+		return bundleID
+	}
+	name, ok := annotations[helmReleaseNameAnnotation]
+	if !ok {
+		return ""
+	}
+	return o.releases[annotations[helmReleaseNamespaceAnnotation]+"/"+name]
+}
+
+// checkConflicts returns a ConflictError if resources of the rendered
+// release already exist on the cluster and are owned by the deployment of
+// another bundle. This is checked before installing, so the bundle
+// deployment can name the owning bundle, instead of failing with helm's
+// ownership error. If takeOwnership is set, the conflict is only logged and
+// the resources are taken over from the other bundle.
+func (h *Helm) checkConflicts(ctx context.Context, bundleID string, rel *release.Release, takeOwnership bool, dynamicClient dynamic.Interface, mapper meta.RESTMapper) error {
+	if rel == nil {
+		return nil
+	}
+
+	deployed, err := h.ListDeployments(h.NewListAction())
+	if err != nil {
+		return err
+	}
+
+	objs, err := ReleaseToObjects(rel)
+	if err != nil {
+		return err
+	}
+
+	var us []*unstructured.Unstructured
+	for _, obj := range objs {
+		if u, ok := obj.(*unstructured.Unstructured); ok {
+			us = append(us, u)
+		}
+	}
+
+	logger := log.FromContext(ctx).WithName("helm-deployer")
+	err = findConflicts(ctx, dynamicClient, mapper, h.newOwners(deployed), bundleID, rel.Namespace, us)
+	var conflict *ConflictError
+	if errors.As(err, &conflict) && takeOwnership {
+		logger.Info("Taking ownership of resources owned by another bundle", "owner", conflict.Owner, "resources", conflict.Resources)
+		return nil
+	} else if err != nil {
+		logger.Info("Bundle resources are owned by another bundle", "error", err.Error())
+	}
+	return err
+}
+
+// findConflicts looks up the live objects of objs and returns a ConflictError
+// for the resources owned by another bundle than bundleID. If resources are
+// owned by several bundles, the conflict names the first owner.
+func findConflicts(ctx context.Context, dynamicClient dynamic.Interface, mapper meta.RESTMapper, o owners, bundleID, defaultNamespace string, objs []*unstructured.Unstructured) error {
+	conflicts := map[string][]string{}
+	for _, obj := range objs {
+		ri, err := resourceInterface(dynamicClient, mapper, obj, defaultNamespace)
+		if meta.IsNoMatchError(err) {
+			// the resource type does not exist yet, nothing can own the object
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		live, err := ri.Get(ctx, obj.GetName(), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if owner := o.owner(live); owner != "" && owner != bundleID {
+			ref := obj.GetKind() + " " + obj.GetName()
+			if obj.GetNamespace() != "" {
+				ref = obj.GetKind() + " " + obj.GetNamespace() + "/" + obj.GetName()
+			}
+			conflicts[owner] = append(conflicts[owner], ref)
+		}
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	var bundleIDs []string
+	for owner := range conflicts {
+		bundleIDs = append(bundleIDs, owner)
+	}
+	slices.Sort(bundleIDs)
+	return &ConflictError{Owner: bundleIDs[0], Resources: conflicts[bundleIDs[0]]}
+}
@@ -0,0 +1,139 @@
+package helmdeployer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rancher/fleet/internal/cmd/agent/deployer/desiredset"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestFindConflicts(t *testing.T) {
+	h := &Helm{labelPrefix: "fleet"}
+	o := h.newOwners([]DeployedBundle{
+		{BundleID: "mine", ReleaseName: "default/mine"},
+		{BundleID: "other", ReleaseName: "default/other"},
+		{BundleID: "applied", ReleaseName: "default/applied", ServerSideApply: true},
+	})
+
+	configMap := func(name string, annotations map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Annotations: annotations},
+		}
+	}
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	client := dynamicfake.NewSimpleDynamicClient(scheme,
+		configMap("owned", map[string]string{desiredset.LabelID: desiredset.GetSetID("mine", "fleet", "")}),
+		configMap("by-set-id", map[string]string{desiredset.LabelID: desiredset.GetSetID("other", "fleet", "")}),
+		configMap("by-release", map[string]string{helmReleaseNameAnnotation: "other", helmReleaseNamespaceAnnotation: "default"}),
+		configMap("by-applied", map[string]string{desiredset.LabelID: desiredset.GetSetID("applied", "fleet", "")}),
+		configMap("orphaned", map[string]string{desiredset.LabelID: desiredset.GetSetID("deleted", "fleet", "")}),
+		configMap("unmanaged", nil),
+	)
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+
+	objs := func(names ...string) []*unstructured.Unstructured {
+		var result []*unstructured.Unstructured
+		for _, name := range names {
+			u := &unstructured.Unstructured{}
+			u.SetAPIVersion("v1")
+			u.SetKind("ConfigMap")
+			u.SetName(name)
+			result = append(result, u)
+		}
+		// a custom resource, whose CRD is not installed yet
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("example.com/v1")
+		u.SetKind("Custom")
+		u.SetName("custom")
+		return append(result, u)
+	}
+
+	ctx := context.TODO()
+	assert.NoError(t, findConflicts(ctx, client, mapper, o, "mine", "default", objs("new", "owned", "orphaned", "unmanaged")))
+
+	err := findConflicts(ctx, client, mapper, o, "mine", "default", objs("owned", "by-set-id", "by-release", "by-applied"))
+	var conflict *ConflictError
+	require.True(t, errors.As(err, &conflict))
+	assert.Equal(t, &ConflictError{Owner: "applied", Resources: []string{"ConfigMap default/by-applied"}}, conflict)
+
+	err = findConflicts(ctx, client, mapper, o, "mine", "default", objs("by-set-id", "by-release"))
+	require.True(t, errors.As(err, &conflict))
+	assert.Equal(t, "other", conflict.Owner)
+	assert.Equal(t, []string{"ConfigMap default/by-set-id", "ConfigMap default/by-release"}, conflict.Resources)
+	assert.EqualError(t, err, "resources are owned by bundle deployment other: ConfigMap default/by-set-id, ConfigMap default/by-release")
+
+	// the owner is allowed to update its own resources
+	assert.NoError(t, findConflicts(ctx, client, mapper, o, "other", "default", objs("by-set-id", "by-release")))
+}
+
+func TestCheckConflictsTakeOwnership(t *testing.T) {
+	mem := driver.NewMemory()
+	mem.SetNamespace("default")
+	require.NoError(t, mem.Create("other.v1", &release.Release{
+		Name:      "other",
+		Namespace: "default",
+		Version:   1,
+		Info:      &release.Info{Status: release.StatusDeployed},
+		Chart: &chart.Chart{Metadata: &chart.Metadata{Annotations: map[string]string{
+			BundleIDAnnotation:       "other",
+			AgentNamespaceAnnotation: "cattle-fleet-system",
+		}}},
+	}))
+	h := &Helm{
+		agentNamespace: "cattle-fleet-system",
+		globalCfg: action.Configuration{
+			KubeClient: &kubefake.PrintingKubeClient{Out: io.Discard},
+			Releases:   storage.Init(mem),
+			Log:        func(string, ...interface{}) {},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	client := dynamicfake.NewSimpleDynamicClient(scheme, &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm", Annotations: map[string]string{
+			helmReleaseNameAnnotation:      "other",
+			helmReleaseNamespaceAnnotation: "default",
+		}},
+	})
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+
+	rel := &release.Release{Name: "mine", Namespace: "default", Manifest: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`}
+
+	ctx := context.TODO()
+	err := h.checkConflicts(ctx, "mine", rel, false, client, mapper)
+	var conflict *ConflictError
+	require.True(t, errors.As(err, &conflict))
+	assert.Equal(t, &ConflictError{Owner: "other", Resources: []string{"ConfigMap default/cm"}}, conflict)
+
+	// the resources are taken over from the other bundle
+	assert.NoError(t, h.checkConflicts(ctx, "mine", rel, true, client, mapper))
+}
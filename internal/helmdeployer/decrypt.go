@@ -0,0 +1,122 @@
+package helmdeployer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rancher/fleet/internal/content"
+	"github.com/rancher/fleet/internal/manifest"
+	"github.com/rancher/fleet/internal/sops"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+// decrypt returns the manifest and options with SOPS encrypted resources
+// decrypted and encrypted values merged into the values. The keys are only
+// looked up, if anything is encrypted.
+func (h *Helm) decrypt(ctx context.Context, m *manifest.Manifest, options fleet.BundleDeploymentOptions) (*manifest.Manifest, fleet.BundleDeploymentOptions, error) {
+	var keys *sops.Keys
+	getKeys := func() (*sops.Keys, error) {
+		if keys != nil {
+			return keys, nil
+		}
+		k, err := h.decryptionKeys(ctx, options.Decryption)
+		if err != nil {
+			return nil, err
+		}
+		keys = k
+		return keys, nil
+	}
+
+	var resources []fleet.BundleResource
+	for i, resource := range m.Resources {
+		if !isYAMLOrJSON(resource.Name) {
+			continue
+		}
+		data, err := content.Decode(resource.Content, resource.Encoding)
+		if err != nil {
+			return nil, options, err
+		}
+		if !sops.IsEncrypted(resource.Name, data) {
+			continue
+		}
+
+		k, err := getKeys()
+		if err != nil {
+			return nil, options, err
+		}
+		plain, err := sops.Decrypt(resource.Name, data, k)
+		if err != nil {
+			return nil, options, fmt.Errorf("failed to decrypt %s: %w", resource.Name, err)
+		}
+		if resources == nil {
+			resources = append([]fleet.BundleResource{}, m.Resources...)
+		}
+		resources[i] = fleet.BundleResource{Name: resource.Name, Content: string(plain)}
+	}
+	if resources != nil {
+		m = &manifest.Manifest{Commit: m.Commit, Resources: resources}
+	}
+
+	if options.Helm == nil || len(options.Helm.EncryptedValues) == 0 {
+		return m, options, nil
+	}
+
+	k, err := getKeys()
+	if err != nil {
+		return nil, options, err
+	}
+	helm := options.Helm.DeepCopy()
+	values := map[string]interface{}{}
+	if helm.Values != nil && helm.Values.Data != nil {
+		values = helm.Values.Data
+	}
+	for i, encrypted := range helm.EncryptedValues {
+		// the names of values files are not kept, JSON is detected by
+		// its content
+		path := "values.yaml"
+		if json.Valid([]byte(encrypted)) {
+			path = "values.json"
+		}
+		plain, err := sops.Decrypt(path, []byte(encrypted), k)
+		if err != nil {
+			return nil, options, fmt.Errorf("failed to decrypt values file %d: %w", i, err)
+		}
+		var v map[string]interface{}
+		if err := yaml.Unmarshal(plain, &v); err != nil {
+			return nil, options, fmt.Errorf("failed to parse values file %d: %w", i, err)
+		}
+		values = mergeValues(values, v)
+	}
+	helm.Values = &fleet.GenericMap{Data: values}
+	helm.EncryptedValues = nil
+	options.Helm = helm
+
+	return m, options, nil
+}
+
+func (h *Helm) decryptionKeys(ctx context.Context, decryption *fleet.DecryptionOptions) (*sops.Keys, error) {
+	if decryption == nil || decryption.SecretName == "" {
+		return nil, errors.New("bundle contains SOPS encrypted files, but no decryption secret is configured")
+	}
+
+	// keys are only read from the agent's namespace, so bundles cannot use
+	// secrets of other namespaces, which the agent can read
+	secret := &corev1.Secret{}
+	if err := h.client.Get(ctx, types.NamespacedName{Namespace: h.agentNamespace, Name: decryption.SecretName}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get decryption secret: %w", err)
+	}
+	return sops.KeysFromSecret(secret)
+}
+
+func isYAMLOrJSON(name string) bool {
+	return strings.HasSuffix(name, ".yaml") ||
+		strings.HasSuffix(name, ".yml") ||
+		strings.HasSuffix(name, ".json")
+}
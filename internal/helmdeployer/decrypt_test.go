@@ -0,0 +1,82 @@
+package helmdeployer
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rancher/fleet/internal/content"
+	"github.com/rancher/fleet/internal/manifest"
+	"github.com/rancher/fleet/internal/sops"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDecrypt(t *testing.T) {
+	ageKey, err := os.ReadFile("../sops/testdata/age.key")
+	require.NoError(t, err)
+	secretYAML, err := os.ReadFile("../sops/testdata/secret.enc.yaml")
+	require.NoError(t, err)
+	valuesJSON, err := os.ReadFile("../sops/testdata/values.enc.json")
+	require.NoError(t, err)
+	compressed, err := content.Base64GZ(secretYAML)
+	require.NoError(t, err)
+
+	h := &Helm{
+		agentNamespace: "cattle-fleet-system",
+		client: fake.NewClientBuilder().WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "sops-keys", Namespace: "cattle-fleet-system"},
+			Data:       map[string][]byte{sops.AgeKey: ageKey},
+		}).Build(),
+	}
+
+	m := manifest.New([]fleet.BundleResource{
+		{Name: "cm.yaml", Content: "apiVersion: v1\nkind: ConfigMap\n"},
+		{Name: "secret.yaml", Content: string(secretYAML)},
+		{Name: "compressed.yaml", Content: compressed, Encoding: "base64+gz"},
+	})
+	options := fleet.BundleDeploymentOptions{
+		Decryption: &fleet.DecryptionOptions{SecretName: "sops-keys"},
+		Helm: &fleet.HelmOptions{
+			Values:          &fleet.GenericMap{Data: map[string]interface{}{"replicas": 1, "name": "app"}},
+			EncryptedValues: []string{string(valuesJSON)},
+		},
+	}
+
+	got, gotOptions, err := h.decrypt(context.TODO(), m, options)
+	require.NoError(t, err)
+
+	assert.Equal(t, m.Resources[0], got.Resources[0])
+	for _, r := range got.Resources[1:] {
+		assert.Empty(t, r.Encoding)
+		assert.Contains(t, r.Content, "password: s3cr3t")
+		assert.Contains(t, r.Content, "# the database password")
+		assert.False(t, sops.IsEncrypted(r.Name, []byte(r.Content)))
+	}
+	// the original manifest is not modified
+	assert.Equal(t, string(secretYAML), m.Resources[1].Content)
+
+	assert.Empty(t, gotOptions.Helm.EncryptedValues)
+	assert.Equal(t, map[string]interface{}{
+		"replicas": float64(3),
+		"name":     "app",
+		"token":    "abc",
+		"image":    map[string]interface{}{"tag": "1.0"},
+	}, gotOptions.Helm.Values.Data)
+	assert.Len(t, options.Helm.EncryptedValues, 1)
+
+	_, _, err = h.decrypt(context.TODO(), m, fleet.BundleDeploymentOptions{})
+	assert.ErrorContains(t, err, "no decryption secret is configured")
+
+	// nothing is looked up, if nothing is encrypted
+	plain := manifest.New([]fleet.BundleResource{m.Resources[0]})
+	got, _, err = h.decrypt(context.TODO(), plain, fleet.BundleDeploymentOptions{})
+	require.NoError(t, err)
+	assert.Same(t, plain, got)
+}
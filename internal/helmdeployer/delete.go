@@ -21,30 +21,26 @@ import (
 
 // DeleteRelease deletes the release for the DeployedBundle.
 func (h *Helm) DeleteRelease(ctx context.Context, deployment DeployedBundle) error {
+	if deployment.ServerSideApply {
+		return h.deleteInventory(ctx, deployment)
+	}
 	return h.deleteByRelease(ctx, deployment.BundleID, deployment.ReleaseName, deployment.KeepResources)
 }
 
 // Delete the release for the given bundleID. The bundleID is the name of the
 // bundledeployment.
 func (h *Helm) Delete(ctx context.Context, bundleID string) error {
-	releaseName := ""
-	keepResources := false
 	deployments, err := h.ListDeployments(h.NewListAction())
 	if err != nil {
 		return err
 	}
 	for _, deployment := range deployments {
 		if deployment.BundleID == bundleID {
-			releaseName = deployment.ReleaseName
-			keepResources = deployment.KeepResources
-			break
+			return h.DeleteRelease(ctx, deployment)
 		}
 	}
-	if releaseName == "" {
-		// Never found anything to delete
-		return nil
-	}
-	return h.deleteByRelease(ctx, bundleID, releaseName, keepResources)
+	// Never found anything to delete
+	return nil
 }
 
 func (h *Helm) deleteByRelease(ctx context.Context, bundleID, releaseName string, keepResources bool) error {
@@ -67,6 +67,9 @@ type DeployedBundle struct {
 	ReleaseName string
 	// KeepResources indicate if resources should be kept when deleting a GitRepo or Bundle
 	KeepResources bool
+	// ServerSideApply is true if the bundle was deployed with server-side
+	// apply, instead of as a helm release
+	ServerSideApply bool
 }
 
 // New returns a new helm deployer
@@ -21,7 +21,7 @@ func (h *Helm) EnsureInstalled(bundleID, resourcesID string) (bool, error) {
 		return false, err
 	}
 
-	if _, err := h.getRelease(releaseName, namespace, version); err == ErrNoRelease {
+	if _, err := h.getRelease(bundleID, releaseName, namespace, version); err == ErrNoRelease {
 		return false, nil
 	} else if err != nil {
 		return false, err
@@ -36,7 +36,7 @@ func (h *Helm) Resources(bundleID, resourcesID string) (*Resources, error) {
 		return &Resources{}, err
 	}
 
-	release, err := h.getRelease(releaseName, namespace, version)
+	release, err := h.getRelease(bundleID, releaseName, namespace, version)
 	if err == ErrNoRelease {
 		return &Resources{}, nil
 	} else if err != nil {
@@ -54,7 +54,7 @@ func (h *Helm) ResourcesFromPreviousReleaseVersion(bundleID, resourcesID string)
 		return &Resources{}, err
 	}
 
-	release, err := h.getRelease(releaseName, namespace, version-1)
+	release, err := h.getRelease(bundleID, releaseName, namespace, version-1)
 	if err == ErrNoRelease {
 		return &Resources{}, nil
 	} else if err != nil {
@@ -83,13 +83,14 @@ func getReleaseNameVersionAndNamespace(bundleID, resourcesID string) (string, in
 	return releaseName, version, namespace, nil
 }
 
-func (h *Helm) getRelease(releaseName, namespace string, version int) (*release.Release, error) {
+// getRelease returns the release from the helm history. If it is not found,
+// the inventory of a bundle deployed with server-side apply is returned
+// instead, if it matches.
+func (h *Helm) getRelease(bundleID, releaseName, namespace string, version int) (*release.Release, error) {
 	hist := action.NewHistory(&h.globalCfg)
 
 	releases, err := hist.Run(releaseName)
-	if err == driver.ErrReleaseNotFound {
-		return nil, ErrNoRelease
-	} else if err != nil {
+	if err != nil && err != driver.ErrReleaseNotFound {
 		return nil, err
 	}
 
@@ -99,7 +100,7 @@ func (h *Helm) getRelease(releaseName, namespace string, version int) (*release.
 		}
 	}
 
-	return nil, ErrNoRelease
+	return h.inventoryRelease(bundleID, releaseName, namespace, version)
 }
 
 func ReleaseToResourceID(release *release.Release) string {
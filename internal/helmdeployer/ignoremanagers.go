@@ -0,0 +1,64 @@
+package helmdeployer
+
+import (
+	"context"
+
+	"github.com/rancher/fleet/internal/cmd/agent/deployer/managedfields"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+)
+
+// liveFields is used by the post renderer to keep the values of fields,
+// which are owned by ignored field managers in the live resources. Otherwise
+// helm's three-way merge on upgrade would revert changes made by these
+// managers, like the replicas set by a horizontal pod autoscaler.
+type liveFields struct {
+	ctx              context.Context
+	client           dynamic.Interface
+	mapper           meta.RESTMapper
+	defaultNamespace string
+	managers         []string
+}
+
+// keep copies the values of the fields owned by the ignored managers from the
+// live resource into obj. Resources, which do not exist yet, are not changed.
+func (l *liveFields) keep(obj runtime.Object) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil
+	}
+
+	gvk := u.GroupVersionKind()
+	mapping, err := l.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return err
+	}
+
+	var ri dynamic.ResourceInterface = l.client.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		ns := u.GetNamespace()
+		if ns == "" {
+			ns = l.defaultNamespace
+		}
+		ri = l.client.Resource(mapping.Resource).Namespace(ns)
+	}
+
+	live, err := ri.Get(l.ctx, u.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, path := range managedfields.Paths(live, l.managers) {
+		if v, ok := managedfields.Get(live.Object, path); ok {
+			managedfields.Set(u.Object, path, v)
+		}
+	}
+	return nil
+}
@@ -19,11 +19,87 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 // Deploy deploys an unpacked content resource with helm. bundleID is the name of the bundledeployment.
 func (h *Helm) Deploy(ctx context.Context, bundleID string, manifest *manifest.Manifest, options fleet.BundleDeploymentOptions) (*release.Release, error) {
+	return h.deploy(ctx, bundleID, manifest, options, nil)
+}
+
+// DeploySyncWave deploys like Deploy, but only applies the resources up to
+// the lowest sync wave, which is not below from. If from is nil, only the
+// resources of the lowest sync wave are applied. Resources of later sync
+// waves, which are already deployed, are always updated.
+func (h *Helm) DeploySyncWave(ctx context.Context, bundleID string, manifest *manifest.Manifest, options fleet.BundleDeploymentOptions, from *int) (*release.Release, SyncWave, error) {
+	filter := &syncWaveFilter{from: from}
+	release, err := h.deploy(ctx, bundleID, manifest, options, filter)
+	return release, filter.result, err
+}
+
+func (h *Helm) deploy(ctx context.Context, bundleID string, manifest *manifest.Manifest, options fleet.BundleDeploymentOptions, syncWave *syncWaveFilter) (*release.Release, error) {
+	manifest, chart, options, err := h.renderChart(ctx, bundleID, manifest, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if ptr.Deref(options.ServerSideApply, false) && !h.template {
+		return h.serverSideApply(ctx, bundleID, manifest, chart, options, syncWave)
+	}
+
+	release, err := h.install(ctx, bundleID, manifest, chart, options, syncWave, true)
+	if err != nil {
+		return nil, err
+	} else if h.template {
+		return release, nil
+	}
+
+	// fail early with the owning bundle, if resources are owned by another
+	// bundle, instead of helm's ownership error
+	dynamicClient, mapper, err := h.applyClient(ctx, options.ServiceAccount)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.checkConflicts(ctx, bundleID, release, options.Helm.TakeOwnership, dynamicClient, mapper); err != nil {
+		return nil, err
+	}
+
+	release, err = h.install(ctx, bundleID, manifest, chart, options, syncWave, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// the resources are now managed by the helm release, forget about the
+	// inventory of a previous deployment with server-side apply
+	return release, h.forgetInventory(ctx, bundleID)
+}
+
+// DryRun renders the resources like Deploy, but does not apply them. It
+// returns the resources the release would contain.
+func (h *Helm) DryRun(ctx context.Context, bundleID string, manifest *manifest.Manifest, options fleet.BundleDeploymentOptions) (*Resources, error) {
+	manifest, chart, options, err := h.renderChart(ctx, bundleID, manifest, options)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := h.install(ctx, bundleID, manifest, chart, options, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	if release == nil {
+		return nil, fmt.Errorf("helm release for %s is being uninstalled", bundleID)
+	}
+
+	resources := &Resources{DefaultNamespace: release.Namespace}
+	resources.Objects, err = ReleaseToObjects(release)
+	return resources, err
+}
+
+// renderChart decrypts the manifest and renders it into a helm chart. It returns
+// the decrypted manifest and options, which are used to install the chart.
+func (h *Helm) renderChart(ctx context.Context, bundleID string, manifest *manifest.Manifest, options fleet.BundleDeploymentOptions) (*manifest.Manifest, *chart.Chart, fleet.BundleDeploymentOptions, error) {
 	if options.Helm == nil {
 		options.Helm = &fleet.HelmOptions{}
 	}
@@ -31,14 +107,23 @@ func (h *Helm) Deploy(ctx context.Context, bundleID string, manifest *manifest.M
 		options.Kustomize = &fleet.KustomizeOptions{}
 	}
 
+	// templating has no access to the cluster's decryption keys
+	if !h.template {
+		var err error
+		manifest, options, err = h.decrypt(ctx, manifest, options)
+		if err != nil {
+			return nil, nil, options, err
+		}
+	}
+
 	tar, err := render.HelmChart(bundleID, manifest, options)
 	if err != nil {
-		return nil, err
+		return nil, nil, options, err
 	}
 
 	chart, err := loader.LoadArchive(tar)
 	if err != nil {
-		return nil, err
+		return nil, nil, options, err
 	}
 
 	if chart.Metadata.Annotations == nil {
@@ -53,17 +138,11 @@ func (h *Helm) Deploy(ctx context.Context, bundleID string, manifest *manifest.M
 		chart.Metadata.Annotations[CommitAnnotation] = manifest.Commit
 	}
 
-	if release, err := h.install(ctx, bundleID, manifest, chart, options, true); err != nil {
-		return nil, err
-	} else if h.template {
-		return release, nil
-	}
-
-	return h.install(ctx, bundleID, manifest, chart, options, false)
+	return manifest, chart, options, nil
 }
 
 // install runs helm install or upgrade and supports dry running the action. Will run helm rollback in case of a failed upgrade.
-func (h *Helm) install(ctx context.Context, bundleID string, manifest *manifest.Manifest, chart *chart.Chart, options fleet.BundleDeploymentOptions, dryRun bool) (*release.Release, error) {
+func (h *Helm) install(ctx context.Context, bundleID string, manifest *manifest.Manifest, chart *chart.Chart, options fleet.BundleDeploymentOptions, syncWave *syncWaveFilter, dryRun bool) (*release.Release, error) {
 	logger := log.FromContext(ctx).WithName("helm-deployer").WithName("install").WithValues("commit", manifest.Commit, "dryRun", dryRun)
 	timeout, defaultNamespace, releaseName := h.getOpts(bundleID, options)
 
@@ -97,6 +176,13 @@ func (h *Helm) install(ctx context.Context, bundleID string, manifest *manifest.
 		return nil, err
 	}
 
+	if syncWave != nil {
+		syncWave.deployed, err = h.deployedObjects(ctx, &cfg, bundleID, releaseName, install)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	pr := &postRender{
 		labelPrefix: h.labelPrefix,
 		labelSuffix: h.labelSuffix,
@@ -104,6 +190,7 @@ func (h *Helm) install(ctx context.Context, bundleID string, manifest *manifest.
 		manifest:    manifest,
 		opts:        options,
 		chart:       chart,
+		syncWave:    syncWave,
 	}
 
 	if !h.useGlobalCfg {
@@ -112,6 +199,26 @@ func (h *Helm) install(ctx context.Context, bundleID string, manifest *manifest.
 			return nil, err
 		}
 		pr.mapper = mapper
+
+		// upgrades keep the values of fields owned by ignored field
+		// managers in the live resources
+		if !install && !h.template && options.Diff != nil && len(options.Diff.IgnoreManagers) > 0 {
+			restConfig, err := cfg.RESTClientGetter.ToRESTConfig()
+			if err != nil {
+				return nil, err
+			}
+			client, err := dynamic.NewForConfig(restConfig)
+			if err != nil {
+				return nil, err
+			}
+			pr.liveFields = &liveFields{
+				ctx:              ctx,
+				client:           client,
+				mapper:           mapper,
+				defaultNamespace: defaultNamespace,
+				managers:         options.Diff.IgnoreManagers,
+			}
+		}
 	}
 
 	if install {
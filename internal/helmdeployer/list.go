@@ -20,7 +20,8 @@ func (h *Helm) NewListAction() *action.List {
 // ListDeployments returns a list of deployedBundles by listing all helm releases via
 // helm's storage driver (secrets)
 // It only returns deployedBundles for helm releases which have the
-// "fleet.cattle.io/bundle-id" annotation.
+// "fleet.cattle.io/bundle-id" annotation. Bundles deployed with server-side
+// apply are listed from their inventory config maps.
 func (h *Helm) ListDeployments(list ListAction) ([]DeployedBundle, error) {
 	releases, err := list.Run()
 	if err != nil {
@@ -55,5 +56,10 @@ func (h *Helm) ListDeployments(list ListAction) ([]DeployedBundle, error) {
 		})
 	}
 
-	return result, nil
+	inventories, err := h.listInventories()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(result, inventories...), nil
 }
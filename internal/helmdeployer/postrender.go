@@ -30,6 +30,8 @@ type postRender struct {
 	chart       *chart.Chart
 	mapper      meta.RESTMapper
 	opts        fleet.BundleDeploymentOptions
+	syncWave    *syncWaveFilter
+	liveFields  *liveFields
 }
 
 func (p *postRender) Run(renderedManifests *bytes.Buffer) (modifiedManifests *bytes.Buffer, err error) {
@@ -109,6 +111,21 @@ func (p *postRender) Run(renderedManifests *bytes.Buffer) (modifiedManifests *by
 			}
 			m.SetNamespace(p.opts.TargetNamespace)
 		}
+
+		if p.liveFields != nil {
+			if err := p.liveFields.keep(obj); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// filter after the namespaces are set, so resources can be compared
+	// with the deployed ones
+	if p.syncWave != nil {
+		objs, err = p.syncWave.filter(objs)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	data, err = yaml.ToBytes(objs)
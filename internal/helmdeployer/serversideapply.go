@@ -0,0 +1,579 @@
+package helmdeployer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+
+	"github.com/rancher/fleet/internal/bundlereader"
+	"github.com/rancher/fleet/internal/cmd/agent/deployer/managedfields"
+	"github.com/rancher/fleet/internal/manifest"
+	"github.com/rancher/fleet/internal/names"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"github.com/rancher/wrangler/v3/pkg/yaml"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// FieldManager is the field manager for resources deployed with
+	// server-side apply.
+	FieldManager = "fleet-agent"
+
+	// InventoryLabel marks the config maps, which contain the inventory of
+	// a bundle deployed with server-side apply.
+	InventoryLabel = "fleet.cattle.io/inventory"
+
+	inventoryNamespaceKey   = "namespace"
+	inventoryReleaseNameKey = "releaseName"
+	inventoryVersionKey     = "version"
+	inventoryManifestKey    = "manifest"
+)
+
+// serverSideApply deploys the rendered resources of a raw YAML or kustomize
+// bundle with server-side apply. Instead of a helm release, the applied
+// resources are stored in an inventory config map, which is used to prune
+// resources removed from the bundle and to monitor the deployed resources.
+// The returned release is not stored by helm, it only carries the resource
+// ID and the manifest of the inventory.
+func (h *Helm) serverSideApply(ctx context.Context, bundleID string, manifest *manifest.Manifest, chart *chart.Chart, options fleet.BundleDeploymentOptions, syncWave *syncWaveFilter) (*release.Release, error) {
+	logger := log.FromContext(ctx).WithName("helm-deployer").WithName("server-side-apply")
+
+	if bundlereader.DetermineStyle(manifest, options).IsHelm() {
+		return nil, errors.New("server-side apply only supports raw YAML and kustomize bundles, not helm charts")
+	}
+
+	// render the resources like a helm release would contain them
+	rel, err := h.install(ctx, bundleID, manifest, chart, options, syncWave, true)
+	if err != nil {
+		return nil, err
+	}
+	if rel == nil {
+		return nil, fmt.Errorf("helm release for %s is being uninstalled", bundleID)
+	}
+	objs, err := ReleaseToObjects(rel)
+	if err != nil {
+		return nil, err
+	}
+
+	dynamicClient, mapper, err := h.applyClient(ctx, options.ServiceAccount)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.checkConflicts(ctx, bundleID, rel, options.Helm.TakeOwnership, dynamicClient, mapper); err != nil {
+		return nil, err
+	}
+
+	previous, err := h.getInventory(ctx, bundleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ensureNamespace(ctx, dynamicClient, rel.Namespace); err != nil {
+		return nil, err
+	}
+
+	var ignoreManagers []string
+	if options.Diff != nil {
+		ignoreManagers = options.Diff.IgnoreManagers
+	}
+
+	sortObjects(objs, releaseutil.InstallOrder)
+	applied := map[objectRef]bool{}
+	for _, obj := range objs {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return nil, fmt.Errorf("unexpected object type %T", obj)
+		}
+		ri, err := resourceInterface(dynamicClient, mapper, u, rel.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		if err := stripIgnoredFields(ctx, ri, u, ignoreManagers); err != nil {
+			return nil, err
+		}
+		if _, err := ri.Apply(ctx, u.GetName(), u, metav1.ApplyOptions{FieldManager: FieldManager, Force: true}); err != nil {
+			return nil, fmt.Errorf("failed to apply %s %s: %w", u.GetKind(), u.GetName(), err)
+		}
+		applied[newObjectRef(u)] = true
+	}
+
+	// resources of later sync waves are not applied yet, but they are only
+	// pruned once they are removed from the bundle
+	rendered := applied
+	if syncWave != nil {
+		rendered = renderedRefs(dynamicClient, mapper, syncWave.rendered, rel.Namespace)
+	}
+
+	version := 1
+	if previous != nil {
+		version = previous.Version + 1
+		kept, err := prune(ctx, logger, dynamicClient, mapper, previous, rendered)
+		if err != nil {
+			return nil, err
+		}
+		// deployed resources, which were not applied again, stay in the
+		// inventory, so they are pruned once they are removed
+		var notApplied []runtime.Object
+		for _, u := range kept {
+			if !applied[newObjectRef(u)] {
+				notApplied = append(notApplied, u)
+			}
+		}
+		if rel.Manifest, err = appendManifest(rel.Manifest, notApplied); err != nil {
+			return nil, err
+		}
+	}
+
+	rel.Version = version
+	if err := h.saveInventory(ctx, bundleID, rel, options); err != nil {
+		return nil, err
+	}
+
+	// the resources are now managed by server-side apply, forget about a
+	// helm release of a previous deployment
+	if err := h.forgetRelease(ctx, bundleID, rel.Name); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Applied bundle resources", "resources", len(applied), "version", version)
+	return rel, nil
+}
+
+// applyClient returns a dynamic client and a REST mapper for the cluster,
+// which impersonate the service account of the bundle, like the helm client.
+func (h *Helm) applyClient(ctx context.Context, serviceAccount string) (dynamic.Interface, meta.RESTMapper, error) {
+	getter := h.getter
+	serviceAccountNamespace, serviceAccountName, err := h.getServiceAccount(ctx, serviceAccount)
+	if err != nil {
+		return nil, nil, err
+	}
+	if serviceAccountName != "" {
+		getter, err = newImpersonatingGetter(serviceAccountNamespace, serviceAccountName, h.getter)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	restConfig, err := getter.ToRESTConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	mapper, err := getter.ToRESTMapper()
+	if err != nil {
+		return nil, nil, err
+	}
+	return dynamicClient, mapper, nil
+}
+
+// resourceInterface returns the dynamic client for the resource of obj.
+// Namespaced resources without a namespace are placed in defaultNamespace.
+func resourceInterface(dynamicClient dynamic.Interface, mapper meta.RESTMapper, obj *unstructured.Unstructured, defaultNamespace string) (dynamic.ResourceInterface, error) {
+	gvk := obj.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		// the resource might be a custom resource, whose CRD was just applied
+		if m, ok := mapper.(meta.ResettableRESTMapper); ok {
+			m.Reset()
+			mapping, err = mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return dynamicClient.Resource(mapping.Resource), nil
+	}
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace(defaultNamespace)
+	}
+	return dynamicClient.Resource(mapping.Resource).Namespace(obj.GetNamespace()), nil
+}
+
+// stripIgnoredFields removes the fields, which are owned by ignored field
+// managers in the live resource, from obj. Server-side apply then leaves
+// these fields to their managers.
+func stripIgnoredFields(ctx context.Context, ri dynamic.ResourceInterface, obj *unstructured.Unstructured, managers []string) error {
+	if len(managers) == 0 {
+		return nil
+	}
+	live, err := ri.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, path := range managedfields.Paths(live, managers) {
+		managedfields.Remove(obj.Object, path)
+	}
+	return nil
+}
+
+func ensureNamespace(ctx context.Context, dynamicClient dynamic.Interface, namespace string) error {
+	namespaces := dynamicClient.Resource(corev1.SchemeGroupVersion.WithResource("namespaces"))
+	if _, err := namespaces.Get(ctx, namespace, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		return err
+	}
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName(namespace)
+	if _, err := namespaces.Create(ctx, ns, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// prune deletes the resources of the previous inventory, which are no longer
+// rendered. Resources with helm's keep resource policy, like CRDs, are not
+// deleted. It returns the resources of the previous inventory, which are
+// still rendered.
+func prune(ctx context.Context, logger logr.Logger, dynamicClient dynamic.Interface, mapper meta.RESTMapper, previous *release.Release, rendered map[objectRef]bool) ([]*unstructured.Unstructured, error) {
+	objs, err := ReleaseToObjects(previous)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		removed []runtime.Object
+		kept    []*unstructured.Unstructured
+	)
+	for _, obj := range objs {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		// resolve the namespace, like on apply
+		if _, err := resourceInterface(dynamicClient, mapper, u, previous.Namespace); err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, err
+		}
+		if rendered[newObjectRef(u)] {
+			kept = append(kept, u)
+		} else {
+			removed = append(removed, u)
+		}
+	}
+
+	for _, obj := range removed {
+		u := obj.(*unstructured.Unstructured)
+		logger.Info("Pruning resource", "kind", u.GetKind(), "namespace", u.GetNamespace(), "name", u.GetName())
+	}
+	return kept, deleteObjects(ctx, dynamicClient, mapper, removed, previous.Namespace)
+}
+
+// renderedRefs returns the references of the rendered resources, with their
+// namespace resolved like on apply.
+func renderedRefs(dynamicClient dynamic.Interface, mapper meta.RESTMapper, objs []runtime.Object, defaultNamespace string) map[objectRef]bool {
+	refs := map[objectRef]bool{}
+	for _, obj := range objs {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		u = u.DeepCopy()
+		// resources, whose kind is not known yet, keep their namespace
+		_, _ = resourceInterface(dynamicClient, mapper, u, defaultNamespace)
+		refs[newObjectRef(u)] = true
+	}
+	return refs
+}
+
+// appendManifest appends the resources to a release manifest.
+func appendManifest(manifest string, objs []runtime.Object) (string, error) {
+	if len(objs) == 0 {
+		return manifest, nil
+	}
+	data, err := yaml.ToBytes(objs)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(manifest, "\n") + "\n---\n" + string(data), nil
+}
+
+// deleteObjects deletes the resources in helm's uninstall order.
+func deleteObjects(ctx context.Context, dynamicClient dynamic.Interface, mapper meta.RESTMapper, objs []runtime.Object, defaultNamespace string) error {
+	sortObjects(objs, releaseutil.UninstallOrder)
+	var errs []error
+	for _, obj := range objs {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok || u.GetAnnotations()[kube.ResourcePolicyAnno] == kube.KeepPolicy {
+			continue
+		}
+		ri, err := resourceInterface(dynamicClient, mapper, u, defaultNamespace)
+		if meta.IsNoMatchError(err) {
+			continue
+		} else if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		propagation := metav1.DeletePropagationBackground
+		err = ri.Delete(ctx, u.GetName(), metav1.DeleteOptions{PropagationPolicy: &propagation})
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete %s %s: %w", u.GetKind(), u.GetName(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sortObjects sorts the resources by kind, in the given order. Kinds not in
+// the order are sorted last.
+func sortObjects(objs []runtime.Object, order releaseutil.KindSortOrder) {
+	rank := func(obj runtime.Object) int {
+		if i := slices.Index(order, obj.GetObjectKind().GroupVersionKind().Kind); i >= 0 {
+			return i
+		}
+		return len(order)
+	}
+	slices.SortStableFunc(objs, func(a, b runtime.Object) int {
+		return rank(a) - rank(b)
+	})
+}
+
+type objectRef struct {
+	gk        schema.GroupKind
+	namespace string
+	name      string
+}
+
+func newObjectRef(obj *unstructured.Unstructured) objectRef {
+	return objectRef{
+		gk:        obj.GroupVersionKind().GroupKind(),
+		namespace: obj.GetNamespace(),
+		name:      obj.GetName(),
+	}
+}
+
+func inventoryName(bundleID string) string {
+	return names.SafeConcatName("fleet-inventory", bundleID)
+}
+
+// getInventory returns the inventory of a bundle deployed with server-side
+// apply as a release. It returns nil, if there is no inventory.
+func (h *Helm) getInventory(ctx context.Context, bundleID string) (*release.Release, error) {
+	if h.client == nil {
+		return nil, nil
+	}
+	cm := &corev1.ConfigMap{}
+	err := h.client.Get(ctx, client.ObjectKey{Namespace: h.agentNamespace, Name: inventoryName(bundleID)}, cm)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return inventoryToRelease(cm)
+}
+
+func (h *Helm) saveInventory(ctx context.Context, bundleID string, rel *release.Release, options fleet.BundleDeploymentOptions) error {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(rel.Manifest)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: h.agentNamespace,
+			Name:      inventoryName(bundleID),
+		},
+	}
+	_, err := controllerutil.CreateOrUpdate(ctx, h.client, cm, func() error {
+		cm.Labels = map[string]string{InventoryLabel: "true"}
+		cm.Annotations = map[string]string{
+			BundleIDAnnotation:           bundleID,
+			AgentNamespaceAnnotation:     h.agentNamespace,
+			ServiceAccountNameAnnotation: options.ServiceAccount,
+			KeepResourcesAnnotation:      strconv.FormatBool(options.KeepResources),
+		}
+		if rel.Chart != nil && rel.Chart.Metadata != nil && rel.Chart.Metadata.Annotations[CommitAnnotation] != "" {
+			cm.Annotations[CommitAnnotation] = rel.Chart.Metadata.Annotations[CommitAnnotation]
+		}
+		cm.Data = map[string]string{
+			inventoryNamespaceKey:   rel.Namespace,
+			inventoryReleaseNameKey: rel.Name,
+			inventoryVersionKey:     strconv.Itoa(rel.Version),
+		}
+		cm.BinaryData = map[string][]byte{
+			inventoryManifestKey: buf.Bytes(),
+		}
+		return nil
+	})
+	return err
+}
+
+func inventoryToRelease(cm *corev1.ConfigMap) (*release.Release, error) {
+	r, err := gzip.NewReader(bytes.NewReader(cm.BinaryData[inventoryManifestKey]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid inventory %s/%s: %w", cm.Namespace, cm.Name, err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid inventory %s/%s: %w", cm.Namespace, cm.Name, err)
+	}
+	version, _ := strconv.Atoi(cm.Data[inventoryVersionKey])
+
+	return &release.Release{
+		Name:      cm.Data[inventoryReleaseNameKey],
+		Namespace: cm.Data[inventoryNamespaceKey],
+		Version:   version,
+		Manifest:  string(data),
+	}, nil
+}
+
+// inventoryRelease returns the inventory of the bundle as a release, if it
+// matches the release name, namespace and version.
+func (h *Helm) inventoryRelease(bundleID, releaseName, namespace string, version int) (*release.Release, error) {
+	rel, err := h.getInventory(context.Background(), bundleID)
+	if err != nil {
+		return nil, err
+	}
+	if rel == nil || rel.Name != releaseName || rel.Namespace != namespace || rel.Version != version {
+		return nil, ErrNoRelease
+	}
+	return rel, nil
+}
+
+// listInventories returns the bundles deployed with server-side apply by
+// this agent.
+func (h *Helm) listInventories() ([]DeployedBundle, error) {
+	if h.client == nil {
+		return nil, nil
+	}
+	cms := &corev1.ConfigMapList{}
+	if err := h.client.List(context.Background(), cms, client.InNamespace(h.agentNamespace), client.MatchingLabels{InventoryLabel: "true"}); err != nil {
+		return nil, err
+	}
+
+	var result []DeployedBundle
+	for _, cm := range cms.Items {
+		bundleID := cm.Annotations[BundleIDAnnotation]
+		if bundleID == "" || cm.Annotations[AgentNamespaceAnnotation] != h.agentNamespace {
+			continue
+		}
+		keepResources, _ := strconv.ParseBool(cm.Annotations[KeepResourcesAnnotation])
+		result = append(result, DeployedBundle{
+			BundleID:        bundleID,
+			ReleaseName:     cm.Data[inventoryNamespaceKey] + "/" + cm.Data[inventoryReleaseNameKey],
+			KeepResources:   keepResources,
+			ServerSideApply: true,
+		})
+	}
+	return result, nil
+}
+
+// deleteInventory deletes the resources of a bundle deployed with
+// server-side apply and its inventory.
+func (h *Helm) deleteInventory(ctx context.Context, deployment DeployedBundle) error {
+	logger := log.FromContext(ctx).WithName("delete-inventory").WithValues("releaseName", deployment.ReleaseName, "keepResources", deployment.KeepResources)
+
+	cm := &corev1.ConfigMap{}
+	err := h.client.Get(ctx, client.ObjectKey{Namespace: h.agentNamespace, Name: inventoryName(deployment.BundleID)}, cm)
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	// never delete the fleet-agent, just "forget" it
+	if !deployment.KeepResources && !strings.HasPrefix(deployment.BundleID, "fleet-agent") {
+		rel, err := inventoryToRelease(cm)
+		if err != nil {
+			return err
+		}
+		objs, err := ReleaseToObjects(rel)
+		if err != nil {
+			return err
+		}
+		dynamicClient, mapper, err := h.applyClient(ctx, cm.Annotations[ServiceAccountNameAnnotation])
+		if err != nil {
+			return err
+		}
+		logger.Info("Deleting resources deployed with server-side apply", "resources", len(objs))
+		if err := deleteObjects(ctx, dynamicClient, mapper, objs, rel.Namespace); err != nil {
+			return err
+		}
+	}
+
+	if err := h.client.Delete(ctx, cm); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	if deployment.KeepResources || strings.HasPrefix(deployment.BundleID, "fleet-agent") {
+		return nil
+	}
+	return deleteResourcesCopiedFromUpstream(ctx, h.client, deployment.BundleID)
+}
+
+// forgetInventory deletes the inventory of the bundle, but keeps the
+// resources. This is used when a bundle is deployed with helm again.
+func (h *Helm) forgetInventory(ctx context.Context, bundleID string) error {
+	if h.client == nil {
+		return nil
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: h.agentNamespace, Name: inventoryName(bundleID)},
+	}
+	if err := h.client.Delete(ctx, cm); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// forgetRelease deletes the helm release history of the bundle, but keeps the
+// resources. This is used when a bundle is deployed with server-side apply.
+func (h *Helm) forgetRelease(ctx context.Context, bundleID, releaseName string) error {
+	rels, err := h.globalCfg.Releases.List(func(r *release.Release) bool {
+		return r.Name == releaseName &&
+			r.Chart != nil && r.Chart.Metadata != nil &&
+			r.Chart.Metadata.Annotations[BundleIDAnnotation] == bundleID &&
+			r.Chart.Metadata.Annotations[AgentNamespaceAnnotation] == h.agentNamespace
+	})
+	if err != nil {
+		return err
+	}
+	for _, rel := range rels {
+		log.FromContext(ctx).Info("Forgetting helm release, resources are deployed with server-side apply", "releaseName", rel.Name, "releaseVersion", rel.Version)
+		cfg, err := h.createCfg(ctx, rel.Namespace)
+		if err != nil {
+			return err
+		}
+		if _, err := cfg.Releases.Delete(rel.Name, rel.Version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package helmdeployer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const inventoryManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: kept
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: removed
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: policy
+  annotations:
+    helm.sh/resource-policy: keep
+`
+
+func TestInventory(t *testing.T) {
+	h := &Helm{
+		agentNamespace: "cattle-fleet-system",
+		client:         fake.NewClientBuilder().Build(),
+	}
+	ctx := context.TODO()
+
+	rel, err := h.getInventory(ctx, "bundle")
+	require.NoError(t, err)
+	assert.Nil(t, rel)
+
+	options := fleet.BundleDeploymentOptions{KeepResources: true}
+	for version := 1; version <= 2; version++ {
+		require.NoError(t, h.saveInventory(ctx, "bundle", &release.Release{
+			Name:      "release",
+			Namespace: "default",
+			Version:   version,
+			Manifest:  inventoryManifest,
+		}, options))
+	}
+
+	rel, err = h.getInventory(ctx, "bundle")
+	require.NoError(t, err)
+	assert.Equal(t, &release.Release{Name: "release", Namespace: "default", Version: 2, Manifest: inventoryManifest}, rel)
+
+	_, err = h.inventoryRelease("bundle", "release", "default", 1)
+	assert.ErrorIs(t, err, ErrNoRelease)
+	rel, err = h.inventoryRelease("bundle", "release", "default", 2)
+	require.NoError(t, err)
+	assert.Equal(t, "default/release:2", ReleaseToResourceID(rel))
+
+	deployed, err := h.listInventories()
+	require.NoError(t, err)
+	assert.Equal(t, []DeployedBundle{{
+		BundleID:        "bundle",
+		ReleaseName:     "default/release",
+		KeepResources:   true,
+		ServerSideApply: true,
+	}}, deployed)
+
+	// resources are kept, only the inventory is deleted
+	require.NoError(t, h.DeleteRelease(ctx, deployed[0]))
+	rel, err = h.getInventory(ctx, "bundle")
+	require.NoError(t, err)
+	assert.Nil(t, rel)
+}
+
+func TestPrune(t *testing.T) {
+	configMap := func(name string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		}
+	}
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	client := dynamicfake.NewSimpleDynamicClient(scheme, configMap("kept"), configMap("removed"), configMap("policy"))
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+
+	// the rendered resources include the ones of later sync waves, which
+	// are not applied
+	rendered := renderedRefs(client, mapper, []runtime.Object{
+		unstructuredConfigMap("kept"),
+		unstructuredConfigMap("later-wave"),
+	}, "default")
+	previous := &release.Release{Namespace: "default", Manifest: inventoryManifest + `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: later-wave
+`}
+	kept, err := prune(context.TODO(), logr.Discard(), client, mapper, previous, rendered)
+	require.NoError(t, err)
+	var keptNames []string
+	for _, u := range kept {
+		keptNames = append(keptNames, u.GetName())
+	}
+	assert.Equal(t, []string{"kept", "later-wave"}, keptNames)
+
+	configMaps := client.Resource(corev1.SchemeGroupVersion.WithResource("configmaps")).Namespace("default")
+	for name, exists := range map[string]bool{"kept": true, "removed": false, "policy": true} {
+		_, err := configMaps.Get(context.TODO(), name, metav1.GetOptions{})
+		if exists {
+			assert.NoError(t, err, name)
+		} else {
+			assert.True(t, apierrors.IsNotFound(err), name)
+		}
+	}
+}
+
+func unstructuredConfigMap(name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("v1")
+	u.SetKind("ConfigMap")
+	u.SetName(name)
+	return u
+}
+
+func TestAppendManifest(t *testing.T) {
+	manifest, err := appendManifest(inventoryManifest, []runtime.Object{unstructuredConfigMap("later-wave")})
+	require.NoError(t, err)
+	objs, err := ReleaseToObjects(&release.Release{Manifest: manifest})
+	require.NoError(t, err)
+	var names []string
+	for _, obj := range objs {
+		names = append(names, obj.(*unstructured.Unstructured).GetName())
+	}
+	assert.Equal(t, []string{"kept", "removed", "policy", "later-wave"}, names)
+
+	manifest, err = appendManifest(inventoryManifest, nil)
+	require.NoError(t, err)
+	assert.Equal(t, inventoryManifest, manifest)
+}
+
+func TestSortObjects(t *testing.T) {
+	obj := func(kind string) runtime.Object {
+		u := &unstructured.Unstructured{}
+		u.SetKind(kind)
+		return u
+	}
+	kinds := func(objs []runtime.Object) []string {
+		var result []string
+		for _, o := range objs {
+			result = append(result, o.GetObjectKind().GroupVersionKind().Kind)
+		}
+		return result
+	}
+
+	objs := []runtime.Object{obj("Custom"), obj("Deployment"), obj("CustomResourceDefinition"), obj("Namespace")}
+	sortObjects(objs, releaseutil.InstallOrder)
+	assert.Equal(t, []string{"Namespace", "CustomResourceDefinition", "Deployment", "Custom"}, kinds(objs))
+
+	sortObjects(objs, releaseutil.UninstallOrder)
+	assert.Equal(t, []string{"Deployment", "CustomResourceDefinition", "Namespace", "Custom"}, kinds(objs))
+}
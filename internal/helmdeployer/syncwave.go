@@ -0,0 +1,145 @@
+package helmdeployer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// SyncWave is the result of deploying a bundle, whose resources are ordered
+// into sync waves by the fleet.SyncWaveAnnotation.
+type SyncWave struct {
+	// Wave is the highest sync wave, which was applied.
+	Wave int
+	// Waves are the distinct sync waves of the rendered resources, in
+	// ascending order.
+	Waves []int
+	// Pending is true if the resources of later sync waves were not applied.
+	Pending bool
+}
+
+// syncWaveFilter is used by the post renderer to only apply the resources
+// up to the lowest sync wave, which is not below from. If from is nil, the
+// lowest sync wave is applied. If all sync waves are below from, all
+// resources are applied.
+// Resources of later sync waves, which are already deployed, are kept at
+// their deployed content until their sync wave is applied. Otherwise
+// upgrading the release would delete them.
+type syncWaveFilter struct {
+	from     *int
+	deployed map[objectRef]runtime.Object
+	result   SyncWave
+	// rendered are all resources, before they were filtered
+	rendered []runtime.Object
+}
+
+func (f *syncWaveFilter) filter(objs []runtime.Object) ([]runtime.Object, error) {
+	f.rendered = objs
+	waves := make([]int, len(objs))
+	for i, obj := range objs {
+		m, err := meta.Accessor(obj)
+		if err != nil {
+			return nil, err
+		}
+		wave, err := syncWave(m.GetAnnotations())
+		if err != nil {
+			return nil, fmt.Errorf("invalid sync wave for %s %s: %w", obj.GetObjectKind().GroupVersionKind().Kind, m.GetName(), err)
+		}
+		waves[i] = wave
+	}
+
+	distinct := slices.Clone(waves)
+	slices.Sort(distinct)
+	distinct = slices.Compact(distinct)
+	if len(distinct) == 0 {
+		f.result = SyncWave{}
+		return objs, nil
+	}
+
+	i := 0
+	if f.from != nil {
+		i, _ = slices.BinarySearch(distinct, *f.from)
+		i = min(i, len(distinct)-1)
+	}
+	f.result = SyncWave{
+		Wave:    distinct[i],
+		Waves:   distinct,
+		Pending: i < len(distinct)-1,
+	}
+
+	result := make([]runtime.Object, 0, len(objs))
+	for i, obj := range objs {
+		if waves[i] <= f.result.Wave {
+			result = append(result, obj)
+		} else if deployed := f.deployedObject(obj); deployed != nil {
+			result = append(result, deployed)
+		}
+	}
+	return result, nil
+}
+
+// deployedObject returns the deployed content of the resource, or nil if it
+// is not deployed.
+func (f *syncWaveFilter) deployedObject(obj runtime.Object) runtime.Object {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil
+	}
+	return f.deployed[newObjectRef(u)]
+}
+
+// deployedObjects returns the resources of the deployed helm release and of
+// the inventory of a deployment with server-side apply, with the content
+// they were deployed with.
+func (h *Helm) deployedObjects(ctx context.Context, cfg *action.Configuration, bundleID, releaseName string, install bool) (map[objectRef]runtime.Object, error) {
+	var releases []*release.Release
+	if !install {
+		rel, err := cfg.Releases.Deployed(releaseName)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, rel)
+	}
+	inventory, err := h.getInventory(ctx, bundleID)
+	if err != nil {
+		return nil, err
+	}
+	if inventory != nil {
+		releases = append(releases, inventory)
+	}
+
+	deployed := map[objectRef]runtime.Object{}
+	for _, rel := range releases {
+		objs, err := ReleaseToObjects(rel)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			if u, ok := obj.(*unstructured.Unstructured); ok {
+				deployed[newObjectRef(u)] = u
+			}
+		}
+	}
+	return deployed, nil
+}
+
+// syncWave returns the sync wave from the annotations of a resource.
+// Resources without the annotation belong to wave 0.
+func syncWave(annotations map[string]string) (int, error) {
+	v, ok := annotations[fleet.SyncWaveAnnotation]
+	if !ok {
+		return 0, nil
+	}
+	return strconv.Atoi(strings.TrimSpace(v))
+}
@@ -0,0 +1,198 @@
+package helmdeployer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rancher/fleet/internal/manifest"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	"github.com/rancher/wrangler/v3/pkg/yaml"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const syncWaveManifests = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: crd
+  annotations:
+    fleet.cattle.io/sync-wave: "-1"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: operator
+---
+apiVersion: example.com/v1
+kind: Custom
+metadata:
+  name: cr
+  annotations:
+    fleet.cattle.io/sync-wave: "5"
+`
+
+func postRenderSyncWave(t *testing.T, manifests string, filter *syncWaveFilter) []string {
+	t.Helper()
+	pr := postRender{
+		manifest: &manifest.Manifest{Resources: []fleet.BundleResource{}},
+		chart:    &chart.Chart{},
+		syncWave: filter,
+	}
+	out, err := pr.Run(bytes.NewBufferString(manifests))
+	require.NoError(t, err)
+
+	objs, err := yaml.ToObjects(out)
+	require.NoError(t, err)
+	var names []string
+	for _, obj := range objs {
+		m, err := meta.Accessor(obj)
+		require.NoError(t, err)
+		names = append(names, m.GetName())
+	}
+	return names
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestSyncWaveFilter(t *testing.T) {
+	tests := map[string]struct {
+		from     *int
+		expected []string
+		result   SyncWave
+	}{
+		"lowest wave first": {
+			expected: []string{"crd"},
+			result:   SyncWave{Wave: -1, Waves: []int{-1, 0, 5}, Pending: true},
+		},
+		"next wave": {
+			from:     intPtr(0),
+			expected: []string{"crd", "operator"},
+			result:   SyncWave{Wave: 0, Waves: []int{-1, 0, 5}, Pending: true},
+		},
+		"skips to the next existing wave": {
+			from:     intPtr(1),
+			expected: []string{"crd", "operator", "cr"},
+			result:   SyncWave{Wave: 5, Waves: []int{-1, 0, 5}},
+		},
+		"applies everything after the last wave": {
+			from:     intPtr(10),
+			expected: []string{"crd", "operator", "cr"},
+			result:   SyncWave{Wave: 5, Waves: []int{-1, 0, 5}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			filter := &syncWaveFilter{from: test.from}
+			assert.Equal(t, test.expected, postRenderSyncWave(t, syncWaveManifests, filter))
+			assert.Equal(t, test.result, filter.result)
+		})
+	}
+
+	t.Run("resources without sync waves", func(t *testing.T) {
+		filter := &syncWaveFilter{}
+		assert.Equal(t, []string{"a", "b"}, postRenderSyncWave(t, "kind: ConfigMap\napiVersion: v1\nmetadata:\n  name: a\n---\nkind: ConfigMap\napiVersion: v1\nmetadata:\n  name: b\n", filter))
+		assert.Equal(t, SyncWave{Wave: 0, Waves: []int{0}}, filter.result)
+	})
+
+	t.Run("no filter", func(t *testing.T) {
+		assert.Equal(t, []string{"crd", "operator", "cr"}, postRenderSyncWave(t, syncWaveManifests, nil))
+	})
+
+	t.Run("invalid sync wave", func(t *testing.T) {
+		pr := postRender{
+			manifest: &manifest.Manifest{Resources: []fleet.BundleResource{}},
+			chart:    &chart.Chart{},
+			syncWave: &syncWaveFilter{},
+		}
+		_, err := pr.Run(bytes.NewBufferString("kind: ConfigMap\napiVersion: v1\nmetadata:\n  name: a\n  annotations:\n    fleet.cattle.io/sync-wave: first\n"))
+		assert.ErrorContains(t, err, "invalid sync wave for ConfigMap a")
+	})
+}
+
+func TestSyncWaveUpgradeKeepsDeployedResources(t *testing.T) {
+	mem := driver.NewMemory()
+	mem.SetNamespace("default")
+	h := &Helm{
+		useGlobalCfg: true,
+		globalCfg: action.Configuration{
+			Capabilities: chartutil.DefaultCapabilities.Copy(),
+			KubeClient:   &kubefake.PrintingKubeClient{Out: io.Discard},
+			Releases:     storage.Init(mem),
+			Log:          func(string, ...interface{}) {},
+		},
+	}
+
+	const v1 = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: operator
+data:
+  version: v1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+  annotations:
+    fleet.cattle.io/sync-wave: "1"
+data:
+  version: v1
+`
+	v2 := strings.ReplaceAll(v1, "version: v1", "version: v2") + `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: new-app
+  annotations:
+    fleet.cattle.io/sync-wave: "1"
+`
+
+	var previous map[string]string
+	deploy := func(manifests string, from *int) map[string]string {
+		t.Helper()
+		m := manifest.New([]fleet.BundleResource{{Name: "manifests.yaml", Content: manifests}})
+		m, chart, options, err := h.renderChart(context.TODO(), "bundle", m, fleet.BundleDeploymentOptions{})
+		require.NoError(t, err)
+		rel, err := h.install(context.TODO(), "bundle", m, chart, options, &syncWaveFilter{from: from}, false)
+		require.NoError(t, err)
+
+		objs, err := ReleaseToObjects(rel)
+		require.NoError(t, err)
+		versions := map[string]string{}
+		for _, obj := range objs {
+			u := obj.(*unstructured.Unstructured)
+			versions[u.GetName()], _, _ = unstructured.NestedString(u.Object, "data", "version")
+		}
+		// helm deletes the resources, which are missing in the release
+		for name := range previous {
+			assert.Contains(t, versions, name, "resource %s was removed from the release", name)
+		}
+		previous = versions
+		return versions
+	}
+
+	assert.Equal(t, map[string]string{"operator": "v1"}, deploy(v1, nil))
+	assert.Equal(t, map[string]string{"operator": "v1", "app": "v1"}, deploy(v1, intPtr(1)))
+
+	// a new deployment starts with the lowest sync wave again, deployed
+	// resources of later waves keep their deployed content and new ones are
+	// held back, until their wave is applied
+	assert.Equal(t, map[string]string{"operator": "v2", "app": "v1"}, deploy(v2, nil))
+	assert.Equal(t, map[string]string{"operator": "v2", "app": "v2", "new-app": ""}, deploy(v2, intPtr(1)))
+}
@@ -42,6 +42,24 @@ func ExtractOptions(bd *fleet.BundleDeployment) (string, []byte, []byte, error)
 	return hash, options, staged, nil
 }
 
+// ExtractRollbackOptions extracts the values from the rollback options in a
+// bundle deployment.
+func ExtractRollbackOptions(bd *fleet.BundleDeployment) ([]byte, error) {
+	if bd.Spec.RollbackOptions == nil || bd.Spec.RollbackOptions.Helm == nil || bd.Spec.RollbackOptions.Helm.Values == nil {
+		return []byte{}, nil
+	}
+
+	rollback, err := bd.Spec.RollbackOptions.Helm.Values.MarshalJSON()
+	if err != nil {
+		return []byte{}, fmt.Errorf("failed to marshal rollback values: %w", err)
+	}
+	if string(rollback) == "null" || string(rollback) == "{}" {
+		return []byte{}, nil
+	}
+
+	return rollback, nil
+}
+
 // ClearOptions removes values from the new bundle deployment
 func ClearOptions(bd *fleet.BundleDeployment) {
 	if bd.Spec.Options.Helm != nil {
@@ -50,6 +68,9 @@ func ClearOptions(bd *fleet.BundleDeployment) {
 	if bd.Spec.StagedOptions.Helm != nil {
 		bd.Spec.StagedOptions.Helm.Values = nil
 	}
+	if bd.Spec.RollbackOptions != nil && bd.Spec.RollbackOptions.Helm != nil {
+		bd.Spec.RollbackOptions.Helm.Values = nil
+	}
 }
 
 // ExtractValues extracts the values from the bundle and returns the values and
@@ -7,8 +7,9 @@ import (
 )
 
 const (
-	ValuesKey       = "values"
-	StagedValuesKey = "stagedValues"
+	ValuesKey         = "values"
+	StagedValuesKey   = "stagedValues"
+	RollbackValuesKey = "rollbackValues"
 )
 
 // HashValuesSecret hashes the data of a secret. This is used for the bundle
@@ -24,7 +25,8 @@ func HashValuesSecret(data map[string][]byte) (string, error) {
 }
 
 // HashOptions hashes the bytes passed in. This is used to create a hash of the
-// bundledeployment's helm options and staged helm options.
+// bundledeployment's helm options, staged helm options and rollback helm
+// options. Empty rollback options do not change the hash.
 func HashOptions(bytes ...[]byte) string {
 	hasher := sha256.New()
 	for _, b := range bytes {
@@ -32,3 +34,9 @@ func HashOptions(bytes ...[]byte) string {
 	}
 	return fmt.Sprintf("%x", hasher.Sum(nil))
 }
+
+// HashOptionsSecret hashes the options stored in a bundledeployment's options
+// secret, see HashOptions.
+func HashOptionsSecret(data map[string][]byte) string {
+	return HashOptions(data[ValuesKey], data[StagedValuesKey], data[RollbackValuesKey])
+}
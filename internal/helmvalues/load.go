@@ -0,0 +1,31 @@
+package helmvalues
+
+import (
+	"context"
+	"fmt"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// LoadOptions loads the helm values of the bundle deployment from its options
+// secret, if present. It mutates the bundle deployment.
+func LoadOptions(ctx context.Context, reader client.Reader, bd *fleet.BundleDeployment) error {
+	if bd.Spec.ValuesHash == "" {
+		return nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := reader.Get(ctx, client.ObjectKey{Namespace: bd.Namespace, Name: bd.Name}, secret); err != nil {
+		return err
+	}
+
+	h := HashOptionsSecret(secret.Data)
+	if h != bd.Spec.ValuesHash {
+		return fmt.Errorf("retrying, hash mismatch between secret and bundledeployment: actual %s != expected %s", h, bd.Spec.ValuesHash)
+	}
+
+	return SetOptions(bd, secret.Data)
+}
@@ -37,7 +37,8 @@ func SetValues(bundle *fleet.Bundle, data map[string][]byte) error {
 
 // SetOptions sets the values in the options of the bundle deployment from the
 // data map. It mutates the bundle deployment.
-// It sets the staged options, however they are not used by the agent.
+// It sets the staged and rollback options, however they are not used by the
+// agent.
 func SetOptions(bd *fleet.BundleDeployment, data map[string][]byte) error {
 	if v, ok := data[ValuesKey]; ok && string(v) != "" {
 		gm := fleet.GenericMap{}
@@ -61,5 +62,16 @@ func SetOptions(bd *fleet.BundleDeployment, data map[string][]byte) error {
 		bd.Spec.StagedOptions.Helm.Values = &gm
 	}
 
+	if v, ok := data[RollbackValuesKey]; ok && string(v) != "" && bd.Spec.RollbackOptions != nil {
+		gm := fleet.GenericMap{}
+		if err := gm.UnmarshalJSON(v); err != nil {
+			return fmt.Errorf("failed to unmarshal rollback values: %w", err)
+		}
+		if bd.Spec.RollbackOptions.Helm == nil {
+			bd.Spec.RollbackOptions.Helm = &fleet.HelmOptions{}
+		}
+		bd.Spec.RollbackOptions.Helm.Values = &gm
+	}
+
 	return nil
 }
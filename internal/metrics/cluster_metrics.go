@@ -29,6 +29,7 @@ var (
 		string(fleet.NotReady),
 		string(fleet.Ready),
 		"WaitCheckIn",
+		"Offline",
 	}
 
 	ClusterCollector = CollectorCollection{
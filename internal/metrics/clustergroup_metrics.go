@@ -40,6 +40,15 @@ var (
 			},
 			clusterGroupLabels,
 		),
+		"offline_cluster_count": promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: metricPrefix,
+				Subsystem: clusterGroupSubsystem,
+				Name:      "offline_cluster_count",
+				Help:      "The count of offline clusters in this cluster group.",
+			},
+			clusterGroupLabels,
+		),
 		"resource_count_desired_ready": promauto.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: metricPrefix,
@@ -159,6 +168,8 @@ func collectClusterGroupMetrics(obj any, metrics map[string]prometheus.Collector
 		Set(float64(clusterGroup.Status.ClusterCount))
 	metrics["non_ready_cluster_count"].(*prometheus.GaugeVec).With(labels).
 		Set(float64(clusterGroup.Status.NonReadyClusterCount))
+	metrics["offline_cluster_count"].(*prometheus.GaugeVec).With(labels).
+		Set(float64(clusterGroup.Status.OfflineClusterCount))
 	metrics["resource_count_desired_ready"].(*prometheus.GaugeVec).With(labels).
 		Set(float64(clusterGroup.Status.ResourceCounts.DesiredReady))
 	metrics["resource_count_missing"].(*prometheus.GaugeVec).With(labels).
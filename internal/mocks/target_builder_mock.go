@@ -22,6 +22,7 @@ import (
 type MockTargetBuilder struct {
 	ctrl     *gomock.Controller
 	recorder *MockTargetBuilderMockRecorder
+	isgomock struct{}
 }
 
 // MockTargetBuilderMockRecorder is the mock recorder for MockTargetBuilder.
@@ -41,17 +42,32 @@ func (m *MockTargetBuilder) EXPECT() *MockTargetBuilderMockRecorder {
 	return m.recorder
 }
 
+// Overlaps mocks base method.
+func (m *MockTargetBuilder) Overlaps(ctx context.Context, bundle *v1alpha1.Bundle, targets []*target.Target) ([]target.Overlap, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Overlaps", ctx, bundle, targets)
+	ret0, _ := ret[0].([]target.Overlap)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Overlaps indicates an expected call of Overlaps.
+func (mr *MockTargetBuilderMockRecorder) Overlaps(ctx, bundle, targets any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Overlaps", reflect.TypeOf((*MockTargetBuilder)(nil).Overlaps), ctx, bundle, targets)
+}
+
 // Targets mocks base method.
-func (m *MockTargetBuilder) Targets(arg0 context.Context, arg1 *v1alpha1.Bundle, arg2 string) ([]*target.Target, error) {
+func (m *MockTargetBuilder) Targets(ctx context.Context, bundle *v1alpha1.Bundle, manifestID string) ([]*target.Target, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Targets", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "Targets", ctx, bundle, manifestID)
 	ret0, _ := ret[0].([]*target.Target)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Targets indicates an expected call of Targets.
-func (mr *MockTargetBuilderMockRecorder) Targets(arg0, arg1, arg2 any) *gomock.Call {
+func (mr *MockTargetBuilderMockRecorder) Targets(ctx, bundle, manifestID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Targets", reflect.TypeOf((*MockTargetBuilder)(nil).Targets), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Targets", reflect.TypeOf((*MockTargetBuilder)(nil).Targets), ctx, bundle, manifestID)
 }
@@ -1,6 +1,7 @@
 package registration
 
 import (
+	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 )
@@ -11,3 +12,14 @@ func SecretName(clientID, clientRandom string) string {
 	d.Write([]byte(clientRandom))
 	return ("c-" + hex.EncodeToString(d.Sum(nil)))[:63]
 }
+
+// TokenSignature signs the client ID and client random of a cluster
+// registration with the secret of its cluster registration token. Only
+// agents holding the token can compute it.
+func TokenSignature(tokenSecret, clientID, clientRandom string) string {
+	mac := hmac.New(sha256.New, []byte(tokenSecret))
+	mac.Write([]byte(clientID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(clientRandom))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,201 @@
+// Package sops decrypts YAML and JSON documents, which were encrypted with
+// SOPS (https://github.com/getsops/sops) using age or PGP keys.
+//
+// Documents are decrypted by the upstream SOPS packages. Only the data key is
+// decrypted here, with the keys of a decryption secret instead of the keys of
+// the local user.
+package sops
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	pgparmor "github.com/ProtonMail/go-crypto/openpgp/armor"
+	getsops "github.com/getsops/sops/v3"
+	"github.com/getsops/sops/v3/aes"
+	sopsage "github.com/getsops/sops/v3/age"
+	"github.com/getsops/sops/v3/cmd/sops/formats"
+	"github.com/getsops/sops/v3/config"
+	"github.com/getsops/sops/v3/keyservice"
+	"github.com/getsops/sops/v3/logging"
+	"github.com/getsops/sops/v3/stores/json"
+	"github.com/getsops/sops/v3/stores/yaml"
+	"github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// AgeKey is the key of a decryption secret, which contains age
+	// identities, one per line.
+	AgeKey = "age"
+	// PGPKey is the key of a decryption secret, which contains ASCII
+	// armored PGP private keys.
+	PGPKey = "pgp"
+)
+
+func init() {
+	// SOPS logs every data key decryption at info level
+	logging.SetLevel(logrus.WarnLevel)
+}
+
+// Keys are the private keys used to decrypt the data key of documents.
+type Keys struct {
+	age sopsage.ParsedIdentities
+	pgp openpgp.EntityList
+}
+
+// NewKeys parses age identities and ASCII armored PGP private keys. Either
+// may be empty.
+func NewKeys(ageIdentities, pgpKeys []byte) (*Keys, error) {
+	keys := &Keys{}
+	if len(bytes.TrimSpace(ageIdentities)) > 0 {
+		ids, err := age.ParseIdentities(bytes.NewReader(ageIdentities))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse age identities: %w", err)
+		}
+		keys.age = ids
+	}
+	if len(bytes.TrimSpace(pgpKeys)) > 0 {
+		entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(pgpKeys))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PGP keys: %w", err)
+		}
+		keys.pgp = entities
+	}
+	if len(keys.age) == 0 && len(keys.pgp) == 0 {
+		return nil, errors.New("no age or PGP keys found")
+	}
+	return keys, nil
+}
+
+// KeysFromSecret returns the keys stored in the secret.
+func KeysFromSecret(secret *corev1.Secret) (*Keys, error) {
+	keys, err := NewKeys(secret.Data[AgeKey], secret.Data[PGPKey])
+	if err != nil {
+		return nil, fmt.Errorf("secret %s/%s: %w", secret.Namespace, secret.Name, err)
+	}
+	return keys, nil
+}
+
+// store is implemented by the YAML and JSON stores of SOPS.
+type store interface {
+	LoadEncryptedFile(in []byte) (getsops.Tree, error)
+	EmitPlainFile(in getsops.TreeBranches) ([]byte, error)
+}
+
+// storeForPath returns the JSON store for ".json" files and the YAML store
+// for all other files.
+func storeForPath(path string) store {
+	if formats.IsJSONFile(path) {
+		return json.NewStore(&config.JSONStoreConfig{})
+	}
+	return yaml.NewStore(&config.YAMLStoreConfig{})
+}
+
+// IsEncrypted returns true if data is a YAML or JSON document containing
+// SOPS metadata. The path selects the format.
+func IsEncrypted(path string, data []byte) bool {
+	if !bytes.Contains(data, []byte("sops")) || !bytes.Contains(data, []byte("mac")) {
+		return false
+	}
+	_, err := storeForPath(path).LoadEncryptedFile(data)
+	return err == nil
+}
+
+// Decrypt decrypts all encrypted values of the YAML or JSON document and
+// verifies its message authentication code. The path selects the format,
+// the result has the same format.
+func Decrypt(path string, data []byte, keys *Keys) ([]byte, error) {
+	s := storeForPath(path)
+	tree, err := s.LoadEncryptedFile(data)
+	if err != nil {
+		return nil, err
+	}
+
+	svcs := []keyservice.KeyServiceClient{keyservice.NewCustomLocalClient(keyService{keys: keys})}
+	key, err := tree.Metadata.GetDataKeyWithKeyServices(svcs, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data key: %w", err)
+	}
+
+	cipher := aes.NewCipher()
+	mac, err := tree.Decrypt(key, cipher)
+	if err != nil {
+		return nil, err
+	}
+	originalMAC, err := cipher.Decrypt(
+		tree.Metadata.MessageAuthenticationCode,
+		key,
+		tree.Metadata.LastModified.Format(time.RFC3339),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt MAC: %w", err)
+	}
+	if originalMAC != mac {
+		return nil, errors.New("MAC mismatch, the document was modified after it was encrypted")
+	}
+
+	return s.EmitPlainFile(tree.Branches)
+}
+
+// keyService decrypts data keys with the age identities and PGP keys of a
+// decryption secret. Unlike the local key service of SOPS, it never falls
+// back to the keys of the user running the agent.
+type keyService struct {
+	keys *Keys
+}
+
+var _ keyservice.KeyServiceServer = keyService{}
+
+func (ks keyService) Decrypt(_ context.Context, req *keyservice.DecryptRequest) (*keyservice.DecryptResponse, error) {
+	var (
+		plaintext []byte
+		err       error
+	)
+	switch k := req.Key.KeyType.(type) {
+	case *keyservice.Key_AgeKey:
+		if len(ks.keys.age) == 0 {
+			return nil, errors.New("no age identities")
+		}
+		key := &sopsage.MasterKey{Recipient: k.AgeKey.Recipient, EncryptedKey: string(req.Ciphertext)}
+		ks.keys.age.ApplyToMasterKey(key)
+		plaintext, err = key.Decrypt()
+	case *keyservice.Key_PgpKey:
+		if len(ks.keys.pgp) == 0 {
+			return nil, errors.New("no PGP keys")
+		}
+		plaintext, err = decryptPGP(string(req.Ciphertext), ks.keys.pgp)
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, only age and PGP keys are supported", k)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &keyservice.DecryptResponse{Plaintext: plaintext}, nil
+}
+
+func (keyService) Encrypt(context.Context, *keyservice.EncryptRequest) (*keyservice.EncryptResponse, error) {
+	return nil, errors.New("encryption is not supported")
+}
+
+// decryptPGP decrypts the data key with the keyring in memory. The PGP key
+// source of SOPS only reads keyrings from files or uses the gpg binary.
+func decryptPGP(armored string, keyring openpgp.EntityList) ([]byte, error) {
+	block, err := pgparmor.Decode(strings.NewReader(armored))
+	if err != nil {
+		return nil, err
+	}
+	md, err := openpgp.ReadMessage(block.Body, keyring, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(md.UnverifiedBody)
+}
@@ -0,0 +1,173 @@
+package sops
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+// The documents in testdata were encrypted with the sops CLI 3.9.4, for the
+// age identity in age.key and the PGP key in pgp.asc. keygroups.enc.yaml
+// needs both keys, it uses two key groups with a threshold of two.
+
+func readFile(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func ageKeys(t *testing.T) *Keys {
+	t.Helper()
+	keys, err := NewKeys(readFile(t, "age.key"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func pgpKeys(t *testing.T) *Keys {
+	t.Helper()
+	keys, err := NewKeys(nil, readFile(t, "pgp.asc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func TestIsEncrypted(t *testing.T) {
+	for _, name := range []string{"secret.enc.yaml", "values.enc.json", "cm.enc.yaml", "multi.enc.yaml", "keygroups.enc.yaml"} {
+		if !IsEncrypted(name, readFile(t, name)) {
+			t.Errorf("expected %s to be encrypted", name)
+		}
+	}
+	for _, data := range []string{
+		"apiVersion: v1\nkind: ConfigMap\n",
+		`{"sops": "ENC[AES256_GCM,data:abc]", "mac": "abc"}`,
+		"not: [valid",
+	} {
+		if IsEncrypted("file.yaml", []byte(data)) {
+			t.Errorf("expected %q not to be encrypted", data)
+		}
+	}
+}
+
+func TestDecrypt(t *testing.T) {
+	bothKeys, err := NewKeys(readFile(t, "age.key"), readFile(t, "pgp.asc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		file string
+		keys *Keys
+		want string
+	}{
+		{
+			file: "secret.enc.yaml",
+			keys: ageKeys(t),
+			want: `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"db"},"stringData":{"empty":"","enabled":true,"hosts":["a","b"],"password":"s3cr3t","port":5432,"ratio":0.5}}`,
+		},
+		{
+			file: "values.enc.json",
+			keys: ageKeys(t),
+			want: `{"image":{"tag":"1.0"},"replicas":3,"token":"abc"}`,
+		},
+		{
+			file: "cm.enc.yaml",
+			keys: pgpKeys(t),
+			want: `{"apiVersion":"v1","data":{"key":"value"},"kind":"ConfigMap","metadata":{"name":"app"}}`,
+		},
+		{
+			file: "keygroups.enc.yaml",
+			keys: bothKeys,
+			want: `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"shared"},"stringData":{"token":"abc"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			plain, err := Decrypt(tt.file, readFile(t, tt.file), tt.keys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := yaml.YAMLToJSON(plain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Decrypt() = %s, want %s", got, tt.want)
+			}
+			if IsEncrypted(tt.file, plain) {
+				t.Errorf("expected decrypted document not to contain SOPS metadata")
+			}
+		})
+	}
+}
+
+func TestDecryptComments(t *testing.T) {
+	plain, err := Decrypt("secret.enc.yaml", readFile(t, "secret.enc.yaml"), ageKeys(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(plain), "# the database password\n") {
+		t.Errorf("expected encrypted comment to be decrypted, got %q", plain)
+	}
+}
+
+func TestDecryptMultipleDocuments(t *testing.T) {
+	plain, err := Decrypt("multi.enc.yaml", readFile(t, "multi.enc.yaml"), ageKeys(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := strings.Split(string(plain), "---\n")
+	if len(docs) != 2 {
+		t.Fatalf("expected 2 documents, got %q", plain)
+	}
+	if !strings.Contains(docs[0], "a: 1\n") || !strings.Contains(docs[0], "b_unencrypted: two\n") {
+		t.Errorf("unexpected first document %q", docs[0])
+	}
+	if !strings.Contains(docs[1], "multi\n") || !strings.Contains(docs[1], "line\n") {
+		t.Errorf("unexpected second document %q", docs[1])
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	other, err := NewKeys([]byte("AGE-SECRET-KEY-1GFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPQ4EGAEX\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Decrypt("secret.enc.yaml", readFile(t, "secret.enc.yaml"), other); err == nil || !strings.Contains(err.Error(), "failed to decrypt data key") {
+		t.Errorf("expected data key error, got %v", err)
+	}
+
+	// one key group is not enough for a threshold of two
+	if _, err := Decrypt("keygroups.enc.yaml", readFile(t, "keygroups.enc.yaml"), ageKeys(t)); err == nil || !strings.Contains(err.Error(), "failed to decrypt data key") {
+		t.Errorf("expected data key error, got %v", err)
+	}
+
+	secret := string(readFile(t, "secret.enc.yaml"))
+	tampered := strings.Replace(string(readFile(t, "multi.enc.yaml")), "b_unencrypted: two", "b_unencrypted: three", 1)
+	if _, err := Decrypt("multi.enc.yaml", []byte(tampered), ageKeys(t)); err == nil || !strings.Contains(err.Error(), "MAC mismatch") {
+		t.Errorf("expected MAC mismatch, got %v", err)
+	}
+
+	// an encrypted empty value must still be authenticated
+	forged := regexp.MustCompile(`password: ENC\[AES256_GCM,data:[^,]*,`).ReplaceAllString(secret, "password: ENC[AES256_GCM,data:,")
+	if _, err := Decrypt("secret.enc.yaml", []byte(forged), ageKeys(t)); err == nil {
+		t.Error("expected an error for a forged empty value")
+	}
+
+	if _, err := Decrypt("file.yaml", []byte("a: b\n"), ageKeys(t)); err == nil {
+		t.Error("expected an error for a document without metadata")
+	}
+
+	if _, err := NewKeys(nil, nil); err == nil {
+		t.Error("expected an error without keys")
+	}
+}
@@ -43,6 +43,14 @@ const (
 	// InternalSecretLabel is a label added to any secret created by Fleet to propagate Bundle or
 	// BundleDeployment secrets storing credential details for OCI storage or HelmOps.
 	InternalSecretLabel = "fleet.cattle.io/bundle-internal-secret"
+
+	// PromotionApprovalAnnotation is the bundle annotation used to approve
+	// the promotion of a bundle past partitions which require an approval.
+	// Its value is a comma separated list of "<partition>=<approval id>"
+	// pairs, where the approval id is reported as pendingApproval in the
+	// partition status. The annotation is kept when fleet apply updates the
+	// bundle.
+	PromotionApprovalAnnotation = "fleet.cattle.io/approved-promotions"
 )
 
 var (
@@ -143,6 +151,15 @@ type BundleRef struct {
 	// Name of the bundle.
 	// +nullable
 	Name string `json:"name,omitempty"`
+	// Namespace of the bundle. Defaults to the namespace of the depending
+	// bundle if Name or GitRepo is set, otherwise Selector matches bundles
+	// in all namespaces.
+	// +nullable
+	Namespace string `json:"namespace,omitempty"`
+	// GitRepo is a shortcut to depend on all bundles created from the named
+	// GitRepo.
+	// +nullable
+	GitRepo string `json:"gitRepo,omitempty"`
 	// Selector matching bundle's labels.
 	// +nullable
 	Selector *metav1.LabelSelector `json:"selector,omitempty"`
@@ -185,6 +202,42 @@ type RolloutStrategy struct {
 	// autoPartitionSize.
 	// +nullable
 	Partitions []Partition `json:"partitions,omitempty"`
+	// AutoRollback configures the automatic rollback of partitions in
+	// which clusters fail to deploy a new version of the bundle.
+	// +nullable
+	AutoRollback *AutoRollback `json:"autoRollback,omitempty"`
+	// OfflineClusterPolicy defines how clusters, whose agent is offline,
+	// are treated during a rollout. "Ignore" does not count them as
+	// unavailable, so they do not block the rollout. "Unavailable" counts
+	// them as unavailable.
+	// default: Ignore
+	// +kubebuilder:validation:Enum=Ignore;Unavailable
+	// +optional
+	OfflineClusterPolicy string `json:"offlineClusterPolicy,omitempty"`
+}
+
+const (
+	// OfflineClusterPolicyIgnore does not count offline clusters as
+	// unavailable during a rollout.
+	OfflineClusterPolicyIgnore = "Ignore"
+	// OfflineClusterPolicyUnavailable counts offline clusters as
+	// unavailable during a rollout.
+	OfflineClusterPolicyUnavailable = "Unavailable"
+)
+
+// AutoRollback configures the automatic rollback of a bundle. If a cluster
+// stays in ErrApplied or NotReady state for longer than the timeout after a
+// new version was deployed, all bundle deployments in the cluster's partition
+// are reverted to the last version known to be ready and no further
+// partitions are updated until the bundle changes again.
+type AutoRollback struct {
+	// Enabled enables automatic rollbacks.
+	Enabled bool `json:"enabled,omitempty"`
+	// Timeout is how long a cluster may fail to deploy a new version,
+	// before its partition is rolled back.
+	// default: 10m
+	// +nullable
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
 }
 
 // Partition defines a separate rollout strategy for a set of clusters.
@@ -205,6 +258,61 @@ type Partition struct {
 	// Selector matching cluster group labels to include in this partition
 	// +nullable
 	ClusterGroupSelector *metav1.LabelSelector `json:"clusterGroupSelector,omitempty"`
+	// BakeDuration is the time all clusters of this partition must stay
+	// ready after being updated, before the rollout proceeds to the next
+	// partition.
+	// +nullable
+	BakeDuration *metav1.Duration `json:"bakeDuration,omitempty"`
+	// Analysis is an optional check, which has to succeed after the bake
+	// duration has elapsed, before the rollout proceeds to the next
+	// partition.
+	// +nullable
+	Analysis *PartitionAnalysis `json:"analysis,omitempty"`
+	// RequireApproval holds the rollout after this partition until the
+	// promotion is approved via the fleet.cattle.io/approved-promotions
+	// annotation on the bundle.
+	RequireApproval bool `json:"requireApproval,omitempty"`
+}
+
+// PartitionAnalysis defines a check, which gates the promotion of a bundle
+// from one partition to the next. Exactly one of the checks should be set.
+type PartitionAnalysis struct {
+	// Prometheus evaluates a query against a Prometheus compatible API.
+	// +nullable
+	Prometheus *PrometheusAnalysis `json:"prometheus,omitempty"`
+	// HTTP sends a request to a user supplied endpoint.
+	// +nullable
+	HTTP *HTTPAnalysis `json:"http,omitempty"`
+	// Timeout for a single evaluation of the check, at most 30s.
+	// default: 10s
+	// +nullable
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// PrometheusAnalysis queries a Prometheus compatible API. The check succeeds
+// if the query returns at least one sample and all returned samples are
+// non-zero, e.g. for a query like `sum(rate(http_errors[5m])) < 1`.
+type PrometheusAnalysis struct {
+	// Address is the base URL of the Prometheus API, e.g.
+	// http://prometheus.monitoring:9090.
+	Address string `json:"address"`
+	// Query is the PromQL instant query to evaluate.
+	Query string `json:"query"`
+}
+
+// HTTPAnalysis checks an HTTP endpoint. The check succeeds if the endpoint
+// responds with one of the expected status codes.
+type HTTPAnalysis struct {
+	// URL of the endpoint to check.
+	URL string `json:"url"`
+	// Method is the HTTP method used for the request.
+	// default: GET
+	// +nullable
+	Method string `json:"method,omitempty"`
+	// ExpectedStatusCodes lists the status codes considered as success.
+	// Any 2xx code is considered a success if this is empty.
+	// +nullable
+	ExpectedStatusCodes []int `json:"expectedStatusCodes,omitempty"`
 }
 
 // BundleTargetRestriction is used internally by Fleet and should not be modified.
@@ -332,6 +440,16 @@ const (
 	// succeeded.
 	BundleDeploymentConditionDeployed  = "Deployed"
 	BundleDeploymentConditionMonitored = "Monitored"
+	// BundleDeploymentConditionConflict is true if resources of the
+	// bundledeployment are owned by another bundle on the cluster. The
+	// message names the owning bundle.
+	BundleDeploymentConditionConflict = "Conflict"
+	// BundleConditionRolledBack indicates that the bundle was
+	// automatically rolled back on some clusters.
+	BundleConditionRolledBack = "RolledBack"
+	// BundleConditionDependencyCycle is true if the bundle's dependsOn
+	// references lead back to the bundle itself.
+	BundleConditionDependencyCycle = "DependencyCycle"
 )
 
 type BundleStatus struct {
@@ -368,6 +486,10 @@ type BundleStatus struct {
 	MaxNew int `json:"maxNew,omitempty"`
 	// PartitionStatus lists the status of each partition.
 	PartitionStatus []PartitionStatus `json:"partitions,omitempty"`
+	// Rollback describes the last automatic rollback, if staging is
+	// halted because of it.
+	// +nullable
+	Rollback *BundleRollbackStatus `json:"rollback,omitempty"`
 	// Display contains the number of ready, desiredready clusters and a
 	// summary state for the bundle's resources.
 	Display BundleDisplay `json:"display,omitempty"`
@@ -428,6 +550,41 @@ type PartitionStatus struct {
 	Unavailable int `json:"unavailable,omitempty"`
 	// Summary is a summary state for the partition, calculated over its non-ready resources.
 	Summary BundleSummary `json:"summary,omitempty"`
+	// ReadySince is the time at which all clusters in the partition were
+	// found to be up-to-date and ready. It is used to compute the bake
+	// duration of the partition.
+	// +nullable
+	ReadySince *metav1.Time `json:"readySince,omitempty"`
+	// HeldReason explains why the rollout does not proceed past this
+	// partition, e.g. because the partition is still baking or its
+	// analysis failed.
+	// +nullable
+	HeldReason string `json:"heldReason,omitempty"`
+	// FailingSince is the time at which clusters in the partition were
+	// first found to fail deploying the current version. It is used to
+	// trigger automatic rollbacks.
+	// +nullable
+	FailingSince *metav1.Time `json:"failingSince,omitempty"`
+	// PendingApproval is the approval id, which needs to be approved
+	// before the rollout proceeds past this partition.
+	// +nullable
+	PendingApproval string `json:"pendingApproval,omitempty"`
+}
+
+// BundleRollbackStatus describes the last automatic rollback of a bundle.
+type BundleRollbackStatus struct {
+	// Generation is the generation of the bundle which was rolled back.
+	// Staging is halted until the bundle changes.
+	Generation int64 `json:"generation,omitempty"`
+	// Partition is the name of the partition which was rolled back.
+	// +nullable
+	Partition string `json:"partition,omitempty"`
+	// Clusters lists the clusters which failed to deploy the bundle, in
+	// the form namespace/name.
+	// +nullable
+	Clusters []string `json:"clusters,omitempty"`
+	// Time is the time of the rollback.
+	Time metav1.Time `json:"time,omitempty"`
 }
 
 type BundleHelmOptions struct {
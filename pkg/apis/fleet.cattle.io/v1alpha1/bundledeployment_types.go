@@ -27,6 +27,12 @@ const (
 	SecretTypeBundleDeploymentOptions = "fleet.cattle.io/bundle-deployment/v1alpha1"
 
 	BundleDeploymentOwnershipLabel = "fleet.cattle.io/bundledeployment"
+
+	// SyncWaveAnnotation orders the resources of a bundle into sync waves.
+	// The value is an integer, resources without the annotation belong to
+	// wave 0. The agent applies the waves in ascending order and waits for
+	// the resources of a wave to be ready, before it applies the next one.
+	SyncWaveAnnotation = "fleet.cattle.io/sync-wave"
 )
 
 const IgnoreOp = "ignore"
@@ -98,10 +104,37 @@ type BundleDeploymentOptions struct {
 	// DeleteNamespace can be used to delete the deployed namespace when removing the bundle
 	DeleteNamespace bool `json:"deleteNamespace,omitempty"`
 
+	// DryRun makes the agent compare the rendered resources to the live
+	// state of the cluster, without applying anything. The would-be
+	// changes are reported in the status of the bundle deployment.
+	// Target customizations can enable or disable it for a cluster.
+	// +nullable
+	DryRun *bool `json:"dryRun,omitempty"`
+
+	// ServerSideApply deploys raw YAML and kustomize bundles with
+	// server-side apply, instead of installing them as a helm release. The
+	// deployed resources are tracked in an inventory config map in the
+	// agent's namespace. Target customizations can enable or disable it
+	// for a cluster.
+	// +nullable
+	ServerSideApply *bool `json:"serverSideApply,omitempty"`
+
 	//IgnoreOptions can be used to ignore fields when monitoring the bundle.
 	// +nullable
 	IgnoreOptions *IgnoreOptions `json:"ignore,omitempty"`
 
+	// HealthChecks define custom readiness checks for resources of a given
+	// kind. They replace the built-in readiness checks of the agent for
+	// matching resources.
+	// +nullable
+	HealthChecks []HealthCheck `json:"healthChecks,omitempty"`
+
+	// Decryption configures the keys the agent uses to decrypt SOPS
+	// encrypted resources and values files of the bundle. Defaults to the
+	// decryption options of the Cluster.
+	// +nullable
+	Decryption *DecryptionOptions `json:"decryption,omitempty"`
+
 	// CorrectDrift specifies how drift correction should work.
 	CorrectDrift *CorrectDrift `json:"correctDrift,omitempty"`
 
@@ -138,6 +171,12 @@ type DiffOptions struct {
 	// ComparePatches match a resource and remove fields, or the resource itself from the check for modifications.
 	// +nullable
 	ComparePatches []ComparePatch `json:"comparePatches,omitempty"`
+	// IgnoreManagers are the names of field managers, e.g.
+	// "horizontal-pod-autoscaler". Fields owned by these managers are
+	// ignored in the check for modifications, for all resources, and are
+	// not reverted when correcting drift.
+	// +nullable
+	IgnoreManagers []string `json:"ignoreManagers,omitempty"`
 }
 
 // ComparePatch matches a resource and removes fields from the check for modifications.
@@ -245,6 +284,12 @@ type HelmOptions struct {
 	// ValuesFrom loads the values from configmaps and secrets.
 	ValuesFrom []ValuesFrom `json:"valuesFrom,omitempty"`
 
+	// EncryptedValues contains the SOPS encrypted files of ValuesFiles.
+	// They stay encrypted until the agent merges them into the values,
+	// after Values and before ValuesFrom.
+	// +nullable
+	EncryptedValues []string `json:"encryptedValues,omitempty"`
+
 	// Force allows to override immutable resources. This could be dangerous.
 	Force bool `json:"force,omitempty"`
 
@@ -282,6 +327,35 @@ type GitOpsHelmOptions struct {
 	ValuesFiles []string `json:"valuesFiles,omitempty"`
 }
 
+// HealthCheck defines CEL expressions, which are evaluated against the live
+// state of deployed resources to compute their readiness. The resource is
+// available as the variable "self" in all expressions.
+type HealthCheck struct {
+	// APIVersion of the resources to check, e.g. "cert-manager.io/v1".
+	APIVersion string `json:"apiVersion"`
+	// Kind of the resources to check, e.g. "Certificate".
+	Kind string `json:"kind"`
+	// Ready is a CEL expression returning true if the resource is ready.
+	Ready string `json:"ready"`
+	// Failed is an optional CEL expression returning true if the resource
+	// failed and will not become ready without intervention.
+	// +nullable
+	Failed string `json:"failed,omitempty"`
+	// Message is an optional CEL expression returning a string, which
+	// explains why the resource is not ready.
+	// +nullable
+	Message string `json:"message,omitempty"`
+}
+
+// DecryptionOptions refers to a secret in the agent's namespace on the
+// downstream cluster, which contains the private keys to decrypt SOPS
+// encrypted files. Its "age" key contains age identities and its "pgp" key
+// ASCII armored PGP private keys.
+type DecryptionOptions struct {
+	// SecretName is the name of the secret in the agent's namespace.
+	SecretName string `json:"secretName"`
+}
+
 // IgnoreOptions defines conditions to be ignored when monitoring the Bundle.
 type IgnoreOptions struct {
 	// Conditions is a list of conditions to be ignored when monitoring the Bundle.
@@ -344,6 +418,14 @@ type BundleDeploymentSpec struct {
 	// DeploymentID is the ID of the currently applied deployment.
 	// +nullable
 	DeploymentID string `json:"deploymentID,omitempty"`
+	// RollbackDeploymentID is the ID of the last deployment which was
+	// known to be ready. It is only set if automatic rollbacks are enabled.
+	// +nullable
+	RollbackDeploymentID string `json:"rollbackDeploymentID,omitempty"`
+	// RollbackOptions are the deployment options of the deployment
+	// identified by RollbackDeploymentID.
+	// +nullable
+	RollbackOptions *BundleDeploymentOptions `json:"rollbackOptions,omitempty"`
 	// DependsOn refers to the bundles which must be ready before this bundle can be deployed.
 	// +nullable
 	DependsOn []BundleRef `json:"dependsOn,omitempty"`
@@ -413,6 +495,42 @@ type BundleDeploymentStatus struct {
 	Resources []BundleDeploymentResource `json:"resources,omitempty"`
 	// ResourceCounts contains the number of resources in each state.
 	ResourceCounts ResourceCounts `json:"resourceCounts,omitempty"`
+	// SyncWave is the highest sync wave, whose resources have been
+	// applied. It is only set if the resources of the bundle are spread
+	// over several sync waves.
+	// +nullable
+	SyncWave *int `json:"syncWave,omitempty"`
+	// SyncWavesPending is true while resources of later sync waves wait
+	// for the resources of the current sync wave to become ready.
+	SyncWavesPending bool `json:"syncWavesPending,omitempty"`
+	// DryRun contains the changes deploying the bundle would apply, if the
+	// dry run option is set.
+	// +nullable
+	DryRun *DryRunStatus `json:"dryRun,omitempty"`
+}
+
+// DryRunStatus reports the changes a dry run of a bundle deployment found
+// between the rendered resources and the live state of the cluster.
+type DryRunStatus struct {
+	// DeploymentID is the deployment ID the dry run was computed for.
+	// +nullable
+	DeploymentID string `json:"deploymentID,omitempty"`
+	// Create is the number of resources which would be created.
+	Create int `json:"create,omitempty"`
+	// Update is the number of resources which would be updated.
+	Update int `json:"update,omitempty"`
+	// Delete is the number of resources which would be deleted.
+	Delete int `json:"delete,omitempty"`
+	// Changes lists the would-be changes. The list and the patches are
+	// size-limited, in which case Truncated is true.
+	// +nullable
+	Changes []ModifiedStatus `json:"changes,omitempty"`
+	// Truncated is true if Changes does not contain all changes.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+func (in DryRunStatus) String() string {
+	return fmt.Sprintf("dry run: %d to create, %d to update, %d to delete", in.Create, in.Update, in.Delete)
 }
 
 type BundleDeploymentDisplay struct {
@@ -473,6 +591,30 @@ type ModifiedStatus struct {
 	Delete bool `json:"delete,omitempty"`
 	// +nullable
 	Patch string `json:"patch,omitempty"`
+	// Drift lists the modified fields of the resource, with their desired
+	// and live values. It is limited to 10 fields per resource.
+	// +nullable
+	Drift []FieldDrift `json:"drift,omitempty"`
+}
+
+// FieldDrift describes a field of a resource, whose live value differs from
+// the desired value.
+type FieldDrift struct {
+	// Path is the JSON path of the field, e.g. ".spec.replicas".
+	// +nullable
+	Path string `json:"path,omitempty"`
+	// Desired is the JSON encoded desired value. It is empty if the field
+	// should not be present, or if it is the data of a secret.
+	// +nullable
+	Desired string `json:"desired,omitempty"`
+	// Live is the JSON encoded live value. It is empty if the field is not
+	// present, or if it is the data of a secret.
+	// +nullable
+	Live string `json:"live,omitempty"`
+	// Manager is the field manager, which last wrote the field according to
+	// the managed fields of the resource.
+	// +nullable
+	Manager string `json:"manager,omitempty"`
 }
 
 func (in ModifiedStatus) String() string {
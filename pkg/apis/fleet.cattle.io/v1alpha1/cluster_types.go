@@ -19,6 +19,9 @@ var (
 	// ClusterConditionProcessed indicates that the status fields have been
 	// processed.
 	ClusterConditionProcessed = "Processed"
+	// ClusterConditionOffline is true if the agent did not check in for
+	// longer than the configured multiple of the agent check-in interval.
+	ClusterConditionOffline = "Offline"
 	// ClusterNamespaceAnnotation used on a cluster namespace to refer to
 	// the cluster registration namespace, which contains the cluster
 	// resource.
@@ -151,6 +154,11 @@ type ClusterSpec struct {
 	HostNetwork *bool `json:"hostNetwork,omitempty"`
 
 	AgentSchedulingCustomization *AgentSchedulingCustomization `json:"agentSchedulingCustomization,omitempty"`
+
+	// Decryption is used to decrypt SOPS encrypted files of bundles, which
+	// do not configure their own decryption options.
+	// +nullable
+	Decryption *DecryptionOptions `json:"decryption,omitempty"`
 }
 
 type ClusterStatus struct {
@@ -266,7 +274,8 @@ type ClusterDisplay struct {
 	// number of bundles that are ready vs. the number of bundles desired
 	// to be ready.
 	ReadyBundles string `json:"readyBundles,omitempty"`
-	// State of the cluster, either one of the bundle states, or "WaitCheckIn".
+	// State of the cluster, either one of the bundle states, "WaitCheckIn"
+	// or "Offline".
 	// +nullable
 	State string `json:"state,omitempty"`
 }
@@ -281,4 +290,8 @@ type AgentStatus struct {
 	// +nullable
 	// +optional
 	Namespace string `json:"namespace"`
+	// Revision identifies the agent bundle the agent was deployed with.
+	// It is used to track agent rollouts.
+	// +optional
+	Revision string `json:"revision,omitempty"`
 }
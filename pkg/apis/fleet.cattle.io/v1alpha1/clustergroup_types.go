@@ -57,6 +57,13 @@ type ClusterGroupStatus struct {
 	// NonReadyClusters is a list of cluster names that are not ready.
 	// +nullable
 	NonReadyClusters []string `json:"nonReadyClusters,omitempty"`
+	// OfflineClusterCount is the number of clusters, whose agent did not
+	// check in recently.
+	// +optional
+	OfflineClusterCount int `json:"offlineClusterCount"`
+	// OfflineClusters is a list of cluster names that are offline.
+	// +nullable
+	OfflineClusters []string `json:"offlineClusters,omitempty"`
 	// Conditions is a list of conditions and their statuses for the cluster group.
 	Conditions []genericcondition.GenericCondition `json:"conditions,omitempty"`
 	// Summary is a summary of the bundle deployments and their resources
@@ -4,7 +4,23 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-const ClusterRegistrationResourceNamePlural = "clusterregistrations"
+const (
+	ClusterRegistrationResourceNamePlural = "clusterregistrations"
+
+	// ClusterRegistrationApprovedAnnotation approves a pending cluster
+	// registration if set to "true" and denies it if set to "false".
+	ClusterRegistrationApprovedAnnotation = "fleet.cattle.io/cluster-registration-approved"
+
+	// ClusterRegistrationStatePending is the state of a registration,
+	// which waits for approval.
+	ClusterRegistrationStatePending = "Pending"
+	// ClusterRegistrationStateApproved is the state of a registration,
+	// which was approved or did not need approval.
+	ClusterRegistrationStateApproved = "Approved"
+	// ClusterRegistrationStateDenied is the state of a registration, which
+	// was denied or violates the restrictions of its token.
+	ClusterRegistrationStateDenied = "Denied"
+)
 
 func init() {
 	InternalSchemeBuilder.Register(&ClusterRegistration{}, &ClusterRegistrationList{})
@@ -16,6 +32,7 @@ func init() {
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Cluster-Name",type=string,JSONPath=`.status.clusterName`
 // +kubebuilder:printcolumn:name="Labels",type=string,JSONPath=`.spec.clusterLabels`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
 
 // ClusterRegistration is used internally by Fleet and should not be used directly.
 type ClusterRegistration struct {
@@ -48,6 +65,16 @@ type ClusterRegistrationSpec struct {
 	// ClusterLabels are copied to the cluster resource during the registration.
 	// +nullable
 	ClusterLabels map[string]string `json:"clusterLabels,omitempty"`
+	// TokenName is the name of the ClusterRegistrationToken, which the
+	// agent uses to register. The restrictions of the token are enforced
+	// for the registration, if its TokenSignature is valid.
+	// +nullable
+	TokenName string `json:"tokenName,omitempty"`
+	// TokenSignature is the HMAC of the client ID and client random,
+	// keyed with the secret of the ClusterRegistrationToken. It proves
+	// that the agent holds the token named by TokenName.
+	// +nullable
+	TokenSignature string `json:"tokenSignature,omitempty"`
 }
 
 type ClusterRegistrationStatus struct {
@@ -59,4 +86,12 @@ type ClusterRegistrationStatus struct {
 	// and its token secret exists. This happens directly before creating
 	// the registration secret, roles and rolebindings.
 	Granted bool `json:"granted,omitempty"`
+	// State is Pending while the registration waits for approval, Approved
+	// once it is approved and Denied if it was denied or violates the
+	// restrictions of its token.
+	// +nullable
+	State string `json:"state,omitempty"`
+	// Message explains why the registration is pending or was denied.
+	// +nullable
+	Message string `json:"message,omitempty"`
 }
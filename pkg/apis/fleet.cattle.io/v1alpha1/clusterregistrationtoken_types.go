@@ -13,6 +13,7 @@ func init() {
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Secret-Name",type=string,JSONPath=`.status.secretName`
+// +kubebuilder:printcolumn:name="Uses",type=integer,JSONPath=`.status.uses`
 
 // ClusterRegistrationToken is used by agents to register a new cluster.
 type ClusterRegistrationToken struct {
@@ -37,6 +38,21 @@ type ClusterRegistrationTokenSpec struct {
 	// expiration time. If the token expires, it will be deleted.
 	// +nullable
 	TTL *metav1.Duration `json:"ttl,omitempty"`
+	// MaxUses is the maximum number of cluster registrations, which are
+	// approved with this token. Zero means unlimited.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxUses int `json:"maxUses,omitempty"`
+	// AllowedClusterLabels restricts the labels, which agents registering
+	// with this token can request for their cluster. The requested labels
+	// must be a subset of one of the label sets. If empty, any labels are
+	// allowed.
+	// +optional
+	AllowedClusterLabels []map[string]string `json:"allowedClusterLabels,omitempty"`
+	// RequireApproval keeps cluster registrations with this token pending,
+	// until they are approved.
+	// +optional
+	RequireApproval bool `json:"requireApproval,omitempty"`
 }
 
 type ClusterRegistrationTokenStatus struct {
@@ -45,4 +61,8 @@ type ClusterRegistrationTokenStatus struct {
 	// SecretName is the name of the secret containing the token.
 	// +nullable
 	SecretName string `json:"secretName,omitempty"`
+	// Uses is the number of cluster registrations, which were approved
+	// with this token.
+	// +optional
+	Uses int `json:"uses,omitempty"`
 }
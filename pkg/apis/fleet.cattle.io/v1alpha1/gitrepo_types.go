@@ -14,10 +14,20 @@ var (
 	BundleLabel          = "fleet.cattle.io/bundle-name"
 	BundleNamespaceLabel = "fleet.cattle.io/bundle-namespace"
 	CreatedByUserIDLabel = "fleet.cattle.io/created-by-user-id"
+
+	// PreviewOfLabel is set on preview GitRepos to the name of the GitRepo
+	// they were created from.
+	PreviewOfLabel = "fleet.cattle.io/preview-of"
+	// PullRequestLabel is set on preview GitRepos to the number of the pull
+	// request they deploy.
+	PullRequestLabel = "fleet.cattle.io/pull-request"
 )
 
 const (
 	GitRepoAcceptedCondition = "Accepted"
+	// GitRepoVerificationFailedCondition is true, if the signature of the
+	// commit or tag to deploy could not be verified.
+	GitRepoVerificationFailedCondition = "VerificationFailed"
 )
 
 // +genclient
@@ -146,6 +156,106 @@ type GitRepoSpec struct {
 	// Bundles defines the paths of bundles to be read.
 	// This drives the fleet resource scanner that simply loads the specified folders
 	Bundles []BundlePath `json:"bundles,omitempty"`
+
+	// PullRequests enables preview environments. For each open pull request
+	// targeting Branch, a GitRepo is created which deploys the head commit
+	// of the pull request. It is deleted once the pull request is closed.
+	// +nullable
+	PullRequests *PullRequestPreviews `json:"pullRequests,omitempty"`
+
+	// CommitStatus reports the deployment state of the current commit back
+	// to the git hosting provider, as a commit status or check.
+	// +nullable
+	CommitStatus *CommitStatusReporting `json:"commitStatus,omitempty"`
+
+	// Verification requires the commit to deploy to be signed by a trusted
+	// key. When deploying a revision which is an annotated tag, the tag's
+	// signature is verified instead.
+	// +nullable
+	Verification *GitVerification `json:"verification,omitempty"`
+}
+
+// GitVerification configures the keys trusted to sign commits and tags.
+type GitVerification struct {
+	// SecretName is the name of a secret in the GitRepo's namespace. Its
+	// "gpg" key contains ASCII armored GPG public keys and its
+	// "allowed_signers" key contains SSH public keys, in the format of
+	// git's gpg.ssh.allowedSignersFile. The principals of an SSH key must
+	// match the email address of the committer or tagger.
+	SecretName string `json:"secretName"`
+}
+
+// CommitStatusReporting configures how commit statuses are posted to the
+// git hosting provider. The commit is pending until all bundle deployments
+// are ready, failed if any of them failed to deploy and successful once all
+// of them are ready.
+type CommitStatusReporting struct {
+	// Provider hosting the git repository.
+	// +kubebuilder:validation:Enum=github;gitlab;gitea;bitbucket;azuredevops
+	Provider string `json:"provider"`
+	// APIURL is the base URL of the provider's API. It defaults to the
+	// public API for GitHub, GitLab, Bitbucket and Azure DevOps and to the
+	// repository's host for Gitea.
+	// +nullable
+	APIURL string `json:"apiURL,omitempty"`
+	// SecretName is the name of a secret in the GitRepo's namespace,
+	// which contains the credentials for the API. It can contain a "token"
+	// key, basic auth or GitHub App credentials. Defaults to
+	// ClientSecretName.
+	// +nullable
+	SecretName string `json:"secretName,omitempty"`
+	// Context identifies the status on the commit. Defaults to
+	// "fleet/<gitrepo name>".
+	// +nullable
+	Context string `json:"context,omitempty"`
+	// TargetURL is linked from the status, e.g. to a dashboard.
+	// +nullable
+	TargetURL string `json:"targetURL,omitempty"`
+}
+
+// PullRequestPreviews configures how open pull requests are discovered and
+// how their preview GitRepos are created.
+type PullRequestPreviews struct {
+	// Provider hosting the git repository, its API is used to list open
+	// pull requests.
+	// +kubebuilder:validation:Enum=github;gitlab;gitea
+	Provider string `json:"provider"`
+	// APIURL is the base URL of the provider's API. It defaults to the
+	// public API for GitHub and GitLab and to the repository's host for
+	// Gitea.
+	// +nullable
+	APIURL string `json:"apiURL,omitempty"`
+	// SecretName is the name of a secret in the GitRepo's namespace, which
+	// contains the credentials for the API. It can contain a "token" key,
+	// basic auth or GitHub App credentials.
+	// +nullable
+	SecretName string `json:"secretName,omitempty"`
+	// Labels enables previews for pull requests which have all of these
+	// labels. Previews are only created for pull requests, which have the
+	// labels or whose author is listed in Authors. If neither is
+	// configured, no previews are created.
+	// +nullable
+	Labels []string `json:"labels,omitempty"`
+	// Authors enables previews for pull requests of these users, without
+	// the labels.
+	// +nullable
+	Authors []string `json:"authors,omitempty"`
+	// AllowForks enables previews for pull requests from forks. Previews
+	// deploy with the credentials, targets and service account of the
+	// GitRepo, so by default only pull requests from branches of the
+	// repository itself are previewed.
+	// +optional
+	AllowForks bool `json:"allowForks,omitempty"`
+	// TargetNamespace is a Go template for the target namespace of preview
+	// GitRepos. It can use .Name, the name of this GitRepo, .Number and
+	// .Branch, the source branch of the pull request. Defaults to
+	// "{{ .Name }}-pr-{{ .Number }}".
+	// +nullable
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+	// PollingInterval is how often open pull requests are listed. Defaults
+	// to 1 minute.
+	// +nullable
+	PollingInterval *metav1.Duration `json:"pollingInterval,omitempty"`
 }
 
 type BundlePath struct {
@@ -189,6 +299,15 @@ type GitRepoStatus struct {
 	// WebhookCommit is the latest Git commit hash received from a webhook
 	// +optional
 	WebhookCommit string `json:"webhookCommit,omitempty"`
+	// WebhookPullRequest identifies the latest pull request event received
+	// from a webhook, as "<number>:<action>:<head commit>". Changes trigger a refresh
+	// of the preview GitRepos.
+	// +optional
+	WebhookPullRequest string `json:"webhookPullRequest,omitempty"`
+	// CommitStatus is the last commit status reported to the git hosting
+	// provider, as "<commit>:<state>".
+	// +optional
+	CommitStatus string `json:"commitStatus,omitempty"`
 	// GitJobStatus is the status of the last Git job run, e.g. "Current" if there was no error.
 	GitJobStatus string `json:"gitJobStatus,omitempty"`
 	// LastSyncedImageScanTime is the time of the last image scan.
@@ -222,6 +341,40 @@ type CommitSpec struct {
 	// into which will be interpolated the details of the change made.
 	// +optional
 	MessageTemplate string `json:"messageTemplate,omitempty"`
+	// PushBranch is the branch to push commits to, instead of the
+	// GitRepo's branch. It is reset to the GitRepo's branch with the
+	// latest image updates on every sync.
+	// +optional
+	// +nullable
+	PushBranch string `json:"pushBranch,omitempty"`
+	// PullRequest opens a pull request from the PushBranch into the
+	// GitRepo's branch. An open pull request for the PushBranch is
+	// updated instead of opening another one.
+	// +optional
+	// +nullable
+	PullRequest *CommitPullRequest `json:"pullRequest,omitempty"`
+}
+
+// CommitPullRequest configures the pull request, or merge request in GitLab
+// terms, for image update commits.
+type CommitPullRequest struct {
+	// Provider hosting the git repository, its API is used to open the
+	// pull request.
+	// +kubebuilder:validation:Enum=github;gitlab;gitea
+	Provider string `json:"provider"`
+	// APIURL is the base URL of the provider's API. It defaults to the
+	// public API for GitHub and GitLab and to the repository's host for
+	// Gitea.
+	// +nullable
+	APIURL string `json:"apiURL,omitempty"`
+	// SecretName is the name of a secret in the GitRepo's namespace, which
+	// contains the credentials for the API. It can contain a "token" key,
+	// basic auth or GitHub App credentials. Defaults to ClientSecretName.
+	// +nullable
+	SecretName string `json:"secretName,omitempty"`
+	// Title of the pull request. Defaults to the commit message.
+	// +nullable
+	Title string `json:"title,omitempty"`
 }
 
 type CorrectDrift struct {
@@ -71,6 +71,108 @@ type ImageScanSpec struct {
 	// selecting the most recent image
 	// +optional
 	Policy ImagePolicyChoice `json:"policy"`
+
+	// FilterTags filters the tags before the policy is applied and
+	// extracts the part of the tags, which the policy orders.
+	// +optional
+	// +nullable
+	FilterTags *TagFilter `json:"filterTags,omitempty"`
+
+	// DigestReflectionPolicy governs when the digest of the latest tag is
+	// resolved, which is written by the digest setters. "Always" resolves
+	// the digest on every scan, so images are committed again when a
+	// mutable tag is moved to a new digest. "IfNotPresent" resolves the
+	// digest only when the latest tag changes. "Never" does not resolve
+	// digests. Defaults to "Always".
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+	// +optional
+	DigestReflectionPolicy DigestReflectionPolicy `json:"digestReflectionPolicy,omitempty"`
+
+	// Verify the cosign signatures of the images. Tags of images without
+	// a valid signature are skipped. Unless the digest reflection policy is
+	// Never, the verified digest is reflected on every scan.
+	// +optional
+	// +nullable
+	Verify *ImageVerification `json:"verify,omitempty"`
+
+	// WebhookSecret contains the name of the secret to use for verifying
+	// registry webhooks, which trigger the scan. It takes precedence over
+	// the global webhook secret.
+	// +optional
+	WebhookSecret string `json:"webhookSecret,omitempty"`
+}
+
+// ImageVerification verifies cosign signatures of images, which are stored
+// in the image repository as "sha256-<digest>.sig" tags. An image is
+// verified, if it has a valid signature of the public key or of a keyless
+// identity.
+type ImageVerification struct {
+	// PublicKey is a PEM encoded public key, which signs the images.
+	// +optional
+	PublicKey string `json:"publicKey,omitempty"`
+
+	// Keyless verifies signatures made with short-lived certificates,
+	// which were issued for an identity.
+	// +optional
+	// +nullable
+	Keyless *KeylessVerification `json:"keyless,omitempty"`
+}
+
+// KeylessVerification verifies signatures made with short-lived
+// certificates, e.g. by Fulcio. The signed entry timestamp of the
+// transparency log proves that the certificate was valid when signing.
+type KeylessVerification struct {
+	// Identities which are allowed to sign the images, at least one has
+	// to match the certificate.
+	Identities []SignatureIdentity `json:"identities,omitempty"`
+
+	// Roots are the PEM encoded root and intermediate certificates, which
+	// issue the signing certificates.
+	Roots string `json:"roots,omitempty"`
+
+	// RekorPublicKey is the PEM encoded public key of the transparency
+	// log, which signs the entry timestamps.
+	RekorPublicKey string `json:"rekorPublicKey,omitempty"`
+}
+
+// SignatureIdentity is matched against the signing certificate.
+type SignatureIdentity struct {
+	// Issuer is a regular expression, which has to match the whole OIDC
+	// issuer of the certificate.
+	Issuer string `json:"issuer,omitempty"`
+
+	// Subject is a regular expression, which has to match the whole email
+	// or URI subject alternative name of the certificate.
+	Subject string `json:"subject,omitempty"`
+}
+
+// DigestReflectionPolicy describes when the digest of the latest tag is
+// resolved.
+type DigestReflectionPolicy string
+
+const (
+	// ReflectAlways resolves the digest of the latest tag on every scan.
+	ReflectAlways DigestReflectionPolicy = "Always"
+	// ReflectIfNotPresent resolves the digest when the latest tag changes.
+	ReflectIfNotPresent DigestReflectionPolicy = "IfNotPresent"
+	// ReflectNever does not resolve digests.
+	ReflectNever DigestReflectionPolicy = "Never"
+)
+
+// TagFilter enables filtering for only a subset of tags based on a set of
+// rules.
+type TagFilter struct {
+	// Pattern specifies a regular expression pattern used to filter for
+	// image tags.
+	// +optional
+	Pattern string `json:"pattern,omitempty"`
+	// Extract allows a capture group to be extracted from the specified
+	// regular expression pattern, useful before tag evaluation. For
+	// example, with the pattern `^main-[a-f0-9]+-(?P<ts>[0-9]+)$`, the
+	// extract `$ts` orders the tags by their timestamp. Defaults to the
+	// whole tag.
+	// +optional
+	Extract string `json:"extract,omitempty"`
 }
 
 // ImagePolicyChoice is a union of all the types of policy that can be
@@ -85,6 +187,10 @@ type ImagePolicyChoice struct {
 	// +optional
 	// +nullable
 	Alphabetical *AlphabeticalPolicy `json:"alphabetical,omitempty"`
+	// Numerical set of rules to use for numerical ordering of the tags.
+	// +optional
+	// +nullable
+	Numerical *NumericalPolicy `json:"numerical,omitempty"`
 }
 
 // SemVerPolicy specifies a semantic version policy.
@@ -106,9 +212,27 @@ type AlphabeticalPolicy struct {
 	Order string `json:"order,omitempty"`
 }
 
+// NumericalPolicy specifies a numerical ordering policy.
+type NumericalPolicy struct {
+	// Order specifies the sorting order of the tags. Given the integer
+	// values from 0 to 9 as tags, ascending order would select 0, and
+	// descending order would select 9. Defaults to descending.
+	// +optional
+	// +nullable
+	Order string `json:"order,omitempty"`
+}
+
 const (
 	ImageScanScanCondition = "ImageScanned"
 	ImageScanSyncCondition = "ImageSynced"
+	// ImageScanVerifyCondition reports the tags which were skipped, because
+	// their images have no valid signature.
+	ImageScanVerifyCondition = "ImageVerified"
+
+	// ImageScanRequestedAnnotation is set by the registry webhook to the
+	// time an image was pushed. The image scan is started immediately, if
+	// it was not scanned since.
+	ImageScanRequestedAnnotation = "fleet.cattle.io/scan-requested"
 )
 
 type ImageScanStatus struct {
@@ -126,6 +250,11 @@ type ImageScanStatus struct {
 	// Latest tag is the latest tag filtered by the policy
 	LatestTag string `json:"latestTag,omitempty"`
 
+	// LatestExtractedValue is the value, which FilterTags extracted from
+	// the latest tag.
+	// +optional
+	LatestExtractedValue string `json:"latestExtractedValue,omitempty"`
+
 	// LatestDigest is the digest of latest tag
 	LatestDigest string `json:"latestDigest,omitempty"`
 
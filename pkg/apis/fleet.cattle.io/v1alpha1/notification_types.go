@@ -0,0 +1,123 @@
+package v1alpha1
+
+import (
+	"github.com/rancher/wrangler/v3/pkg/genericcondition"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func init() {
+	InternalSchemeBuilder.Register(&Notification{}, &NotificationList{})
+}
+
+const (
+	// NotificationKindGitRepo selects GitRepos.
+	NotificationKindGitRepo = "GitRepo"
+	// NotificationKindHelmOp selects HelmOps.
+	NotificationKindHelmOp = "HelmOp"
+	// NotificationKindBundle selects Bundles.
+	NotificationKindBundle = "Bundle"
+	// NotificationKindCluster selects Clusters.
+	NotificationKindCluster = "Cluster"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:categories=fleet,path=notifications
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.spec.webhook.url`
+// +kubebuilder:printcolumn:name="Suspended",type=boolean,JSONPath=`.spec.suspend`
+// +kubebuilder:printcolumn:name="Last Sent",type=string,JSONPath=`.status.lastSentTime`
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].message`
+
+// Notification sends a request to an HTTP webhook, whenever one of the
+// selected Fleet resources transitions into one of the configured states.
+type Notification struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NotificationSpec   `json:"spec,omitempty"`
+	Status NotificationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NotificationList contains a list of Notification
+type NotificationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Notification `json:"items"`
+}
+
+type NotificationSpec struct {
+	// Resources selects the Fleet resources in the Notification's
+	// namespace, whose state transitions are notified.
+	Resources []NotificationResource `json:"resources"`
+	// States which trigger a notification, when a resource transitions
+	// into them. The state of a resource is the highest ranked state of
+	// its bundle deployments. Defaults to ErrApplied, Modified and
+	// NotReady.
+	// +nullable
+	States []BundleState `json:"states,omitempty"`
+	// Webhook is the HTTP endpoint notifications are posted to.
+	Webhook NotificationWebhook `json:"webhook"`
+	// Template is a Go template for the request body. It can use .Kind,
+	// .Namespace, .Name, .State, .PreviousState, .Message and .Summary,
+	// which is the resource's BundleSummary. Defaults to a JSON document
+	// containing these fields and a "text" field, which is understood by
+	// Slack and compatible incoming webhooks.
+	// +nullable
+	Template string `json:"template,omitempty"`
+	// Suspend stops sending notifications. Transitions which happen while
+	// suspended are not notified.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// NotificationResource selects Fleet resources of a kind, by name or by
+// labels. All resources of the kind are selected, if neither is set.
+type NotificationResource struct {
+	// Kind of the selected resources.
+	// +kubebuilder:validation:Enum=GitRepo;HelmOp;Bundle;Cluster
+	Kind string `json:"kind"`
+	// Name of a single resource.
+	// +nullable
+	Name string `json:"name,omitempty"`
+	// Selector is a label selector for resources.
+	// +nullable
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+type NotificationWebhook struct {
+	// URL of the endpoint, requests are sent as HTTP POST.
+	// +nullable
+	URL string `json:"url,omitempty"`
+	// SecretName is the name of a secret in the Notification's namespace.
+	// Its "url" key takes precedence over URL, e.g. for URLs which contain
+	// a token, and its "token" key is sent as bearer token.
+	// +nullable
+	SecretName string `json:"secretName,omitempty"`
+	// Headers are added to each request.
+	// +nullable
+	Headers map[string]string `json:"headers,omitempty"`
+	// ContentType of the request body. Defaults to application/json.
+	// +nullable
+	ContentType string `json:"contentType,omitempty"`
+}
+
+type NotificationStatus struct {
+	// ObservedGeneration is the generation of the Notification, which
+	// was last reconciled.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions is a list of Wrangler conditions that describe the state
+	// of the resource.
+	Conditions []genericcondition.GenericCondition `json:"conditions,omitempty"`
+	// LastSentTime is the time the last notification was delivered.
+	// +nullable
+	LastSentTime *metav1.Time `json:"lastSentTime,omitempty"`
+	// ResourceStates contains the last observed state of each selected
+	// resource, keyed by "<kind>/<name>". Only changes of these states
+	// are notified, so each transition is delivered once.
+	// +nullable
+	ResourceStates map[string]BundleState `json:"resourceStates,omitempty"`
+}
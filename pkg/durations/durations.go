@@ -13,17 +13,21 @@ const (
 	ClusterRegisterDelay           = time.Second * 15
 	ClusterRegistrationDeleteDelay = time.Minute * 40
 	ClusterSecretRetry             = time.Second * 2
+	CommitStatusRetry              = time.Minute * 1
 	ContentPurgeInterval           = time.Minute * 5
 	CreateClusterSecretTimeout     = time.Minute * 30
 	DefaultClusterCheckInterval    = time.Minute * 15
 	DefaultImageInterval           = time.Minute * 15
 	DefaultRequeueAfter            = time.Second * 5
 	DefaultResyncAgent             = time.Minute * 30
+	DependencyCycleRetry           = time.Minute * 1
 	FailureRateLimiterBase         = time.Millisecond * 5
 	FailureRateLimiterMax          = time.Second * 60
 	SlowFailureRateLimiterBase     = time.Second * 2
 	SlowFailureRateLimiterMax      = time.Minute * 10 // hit after 10 failures in a row
 	GarbageCollect                 = time.Minute * 15
+	PartitionAnalysisPoll          = time.Second * 5
+	PartitionAnalysisRetry         = time.Second * 30
 	RestConfigTimeout              = time.Second * 15
 	ServiceTokenSleep              = time.Second * 2
 	TokenClusterEnqueueDelay       = time.Second * 2
@@ -43,6 +43,7 @@ type Interface interface {
 	GitRepoRestriction() GitRepoRestrictionController
 	HelmOp() HelmOpController
 	ImageScan() ImageScanController
+	Notification() NotificationController
 	Schedule() ScheduleController
 }
 
@@ -104,6 +105,10 @@ func (v *version) ImageScan() ImageScanController {
 	return generic.NewController[*v1alpha1.ImageScan, *v1alpha1.ImageScanList](schema.GroupVersionKind{Group: "fleet.cattle.io", Version: "v1alpha1", Kind: "ImageScan"}, "imagescans", true, v.controllerFactory)
 }
 
+func (v *version) Notification() NotificationController {
+	return generic.NewController[*v1alpha1.Notification, *v1alpha1.NotificationList](schema.GroupVersionKind{Group: "fleet.cattle.io", Version: "v1alpha1", Kind: "Notification"}, "notifications", true, v.controllerFactory)
+}
+
 func (v *version) Schedule() ScheduleController {
 	return generic.NewController[*v1alpha1.Schedule, *v1alpha1.ScheduleList](schema.GroupVersionKind{Group: "fleet.cattle.io", Version: "v1alpha1", Kind: "Schedule"}, "schedules", true, v.controllerFactory)
 }
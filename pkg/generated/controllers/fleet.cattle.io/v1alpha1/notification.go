@@ -0,0 +1,208 @@
+/*
+Copyright (c) 2020 - 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	v1alpha1 "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	"github.com/rancher/wrangler/v3/pkg/apply"
+	"github.com/rancher/wrangler/v3/pkg/condition"
+	"github.com/rancher/wrangler/v3/pkg/generic"
+	"github.com/rancher/wrangler/v3/pkg/kv"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// NotificationController interface for managing Notification resources.
+type NotificationController interface {
+	generic.ControllerInterface[*v1alpha1.Notification, *v1alpha1.NotificationList]
+}
+
+// NotificationClient interface for managing Notification resources in Kubernetes.
+type NotificationClient interface {
+	generic.ClientInterface[*v1alpha1.Notification, *v1alpha1.NotificationList]
+}
+
+// NotificationCache interface for retrieving Notification resources in memory.
+type NotificationCache interface {
+	generic.CacheInterface[*v1alpha1.Notification]
+}
+
+// NotificationStatusHandler is executed for every added or modified Notification. Should return the new status to be updated
+type NotificationStatusHandler func(obj *v1alpha1.Notification, status v1alpha1.NotificationStatus) (v1alpha1.NotificationStatus, error)
+
+// NotificationGeneratingHandler is the top-level handler that is executed for every Notification event. It extends NotificationStatusHandler by a returning a slice of child objects to be passed to apply.Apply
+type NotificationGeneratingHandler func(obj *v1alpha1.Notification, status v1alpha1.NotificationStatus) ([]runtime.Object, v1alpha1.NotificationStatus, error)
+
+// RegisterNotificationStatusHandler configures a NotificationController to execute a NotificationStatusHandler for every events observed.
+// If a non-empty condition is provided, it will be updated in the status conditions for every handler execution
+func RegisterNotificationStatusHandler(ctx context.Context, controller NotificationController, condition condition.Cond, name string, handler NotificationStatusHandler) {
+	statusHandler := &notificationStatusHandler{
+		client:    controller,
+		condition: condition,
+		handler:   handler,
+	}
+	controller.AddGenericHandler(ctx, name, generic.FromObjectHandlerToHandler(statusHandler.sync))
+}
+
+// RegisterNotificationGeneratingHandler configures a NotificationController to execute a NotificationGeneratingHandler for every events observed, passing the returned objects to the provided apply.Apply.
+// If a non-empty condition is provided, it will be updated in the status conditions for every handler execution
+func RegisterNotificationGeneratingHandler(ctx context.Context, controller NotificationController, apply apply.Apply,
+	condition condition.Cond, name string, handler NotificationGeneratingHandler, opts *generic.GeneratingHandlerOptions) {
+	statusHandler := &notificationGeneratingHandler{
+		NotificationGeneratingHandler: handler,
+		apply:                         apply,
+		name:                          name,
+		gvk:                           controller.GroupVersionKind(),
+	}
+	if opts != nil {
+		statusHandler.opts = *opts
+	}
+	controller.OnChange(ctx, name, statusHandler.Remove)
+	RegisterNotificationStatusHandler(ctx, controller, condition, name, statusHandler.Handle)
+}
+
+type notificationStatusHandler struct {
+	client    NotificationClient
+	condition condition.Cond
+	handler   NotificationStatusHandler
+}
+
+// sync is executed on every resource addition or modification. Executes the configured handlers and sends the updated status to the Kubernetes API
+func (a *notificationStatusHandler) sync(key string, obj *v1alpha1.Notification) (*v1alpha1.Notification, error) {
+	if obj == nil {
+		return obj, nil
+	}
+
+	origStatus := obj.Status.DeepCopy()
+	obj = obj.DeepCopy()
+	newStatus, err := a.handler(obj, obj.Status)
+	if err != nil {
+		// Revert to old status on error
+		newStatus = *origStatus.DeepCopy()
+	}
+
+	if a.condition != "" {
+		if errors.IsConflict(err) {
+			a.condition.SetError(&newStatus, "", nil)
+		} else {
+			a.condition.SetError(&newStatus, "", err)
+		}
+	}
+	if !equality.Semantic.DeepEqual(origStatus, &newStatus) {
+		if a.condition != "" {
+			// Since status has changed, update the lastUpdatedTime
+			a.condition.LastUpdated(&newStatus, time.Now().UTC().Format(time.RFC3339))
+		}
+
+		var newErr error
+		obj.Status = newStatus
+		newObj, newErr := a.client.UpdateStatus(obj)
+		if err == nil {
+			err = newErr
+		}
+		if newErr == nil {
+			obj = newObj
+		}
+	}
+	return obj, err
+}
+
+type notificationGeneratingHandler struct {
+	NotificationGeneratingHandler
+	apply apply.Apply
+	opts  generic.GeneratingHandlerOptions
+	gvk   schema.GroupVersionKind
+	name  string
+	seen  sync.Map
+}
+
+// Remove handles the observed deletion of a resource, cascade deleting every associated resource previously applied
+func (a *notificationGeneratingHandler) Remove(key string, obj *v1alpha1.Notification) (*v1alpha1.Notification, error) {
+	if obj != nil {
+		return obj, nil
+	}
+
+	obj = &v1alpha1.Notification{}
+	obj.Namespace, obj.Name = kv.RSplit(key, "/")
+	obj.SetGroupVersionKind(a.gvk)
+
+	if a.opts.UniqueApplyForResourceVersion {
+		a.seen.Delete(key)
+	}
+
+	return nil, generic.ConfigureApplyForObject(a.apply, obj, &a.opts).
+		WithOwner(obj).
+		WithSetID(a.name).
+		ApplyObjects()
+}
+
+// Handle executes the configured NotificationGeneratingHandler and pass the resulting objects to apply.Apply, finally returning the new status of the resource
+func (a *notificationGeneratingHandler) Handle(obj *v1alpha1.Notification, status v1alpha1.NotificationStatus) (v1alpha1.NotificationStatus, error) {
+	if !obj.DeletionTimestamp.IsZero() {
+		return status, nil
+	}
+
+	objs, newStatus, err := a.NotificationGeneratingHandler(obj, status)
+	if err != nil {
+		return newStatus, err
+	}
+	if !a.isNewResourceVersion(obj) {
+		return newStatus, nil
+	}
+
+	err = generic.ConfigureApplyForObject(a.apply, obj, &a.opts).
+		WithOwner(obj).
+		WithSetID(a.name).
+		ApplyObjects(objs...)
+	if err != nil {
+		return newStatus, err
+	}
+	a.storeResourceVersion(obj)
+	return newStatus, nil
+}
+
+// isNewResourceVersion detects if a specific resource version was already successfully processed.
+// Only used if UniqueApplyForResourceVersion is set in generic.GeneratingHandlerOptions
+func (a *notificationGeneratingHandler) isNewResourceVersion(obj *v1alpha1.Notification) bool {
+	if !a.opts.UniqueApplyForResourceVersion {
+		return true
+	}
+
+	// Apply once per resource version
+	key := obj.Namespace + "/" + obj.Name
+	previous, ok := a.seen.Load(key)
+	return !ok || previous != obj.ResourceVersion
+}
+
+// storeResourceVersion keeps track of the latest resource version of an object for which Apply was executed
+// Only used if UniqueApplyForResourceVersion is set in generic.GeneratingHandlerOptions
+func (a *notificationGeneratingHandler) storeResourceVersion(obj *v1alpha1.Notification) {
+	if !a.opts.UniqueApplyForResourceVersion {
+		return
+	}
+
+	key := obj.Namespace + "/" + obj.Name
+	a.seen.Store(key, obj.ResourceVersion)
+}
@@ -2,6 +2,8 @@ package git
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/rancher/fleet/internal/config"
 	"github.com/rancher/fleet/internal/ssh"
@@ -9,7 +11,7 @@ import (
 	"github.com/rancher/fleet/pkg/cert"
 
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -39,7 +41,7 @@ func (f *Fetch) LatestCommit(ctx context.Context, gitrepo *v1alpha1.GitRepo, cli
 		Name:      secretName,
 	}, &secret)
 
-	if err != nil && !errors.IsNotFound(err) {
+	if err != nil && !apierrors.IsNotFound(err) {
 		return "", err
 	}
 
@@ -87,8 +89,51 @@ func (f *Fetch) LatestCommit(ctx context.Context, gitrepo *v1alpha1.GitRepo, cli
 		return "", err
 	}
 
+	if gitrepo.Spec.Verification != nil {
+		return verifiedCommit(ctx, gitrepo, client, r, branch)
+	}
+
 	if gitrepo.Spec.Revision != "" {
 		return r.RevisionCommit(gitrepo.Spec.Revision)
 	}
 	return r.LatestBranchCommit(branch)
 }
+
+// verifiedCommit returns the latest commit of the branch or revision, after
+// verifying the signature of the commit, or of the tag for annotated tags.
+// Only the signed object is fetched. If the remote cannot send single
+// objects, the signature is verified when cloning.
+func verifiedCommit(ctx context.Context, gitrepo *v1alpha1.GitRepo, client client.Client, r *Remote, branch string) (string, error) {
+	var secret corev1.Secret
+	if err := client.Get(ctx, types.NamespacedName{
+		Namespace: gitrepo.Namespace,
+		Name:      gitrepo.Spec.Verification.SecretName,
+	}, &secret); err != nil {
+		return "", fmt.Errorf("failed to get verification secret: %w", err)
+	}
+	v, err := VerifierFromSecret(&secret)
+	if err != nil {
+		return "", fmt.Errorf("%w: verification secret %s: %w", ErrVerificationFailed, secret.Name, err)
+	}
+
+	var commit, signed string
+	if gitrepo.Spec.Revision != "" {
+		commit, signed, err = r.revisionObjects(gitrepo.Spec.Revision)
+	} else {
+		commit, err = r.LatestBranchCommit(branch)
+		signed = commit
+	}
+	if err != nil {
+		return "", err
+	}
+
+	err = r.VerifyObject(ctx, signed, v)
+	if errors.Is(err, errPartialFetchUnsupported) {
+		log.FromContext(ctx).V(1).Info("Remote does not support partial fetches, deferring signature verification to the git job", "commit", commit)
+		return commit, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return commit, nil
+}
@@ -1,19 +1,32 @@
 package git
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
 	gogit "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp/capability"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp/sideband"
 	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
 	"github.com/go-git/go-git/v5/storage/memory"
 	"github.com/go-logr/logr"
 	giturls "github.com/rancher/fleet/pkg/git-urls"
 	corev1 "k8s.io/api/core/v1"
 )
 
+// errPartialFetchUnsupported is returned if the remote cannot send a single
+// commit or tag object.
+var errPartialFetchUnsupported = errors.New("remote does not support fetching single objects")
+
 type options struct {
 	Credential        *corev1.Secret
 	CABundle          []byte
@@ -70,6 +83,7 @@ type Remote struct {
 	Lister  RemoteLister
 	URL     string
 	Options *options
+	auth    transport.AuthMethod
 }
 
 func NewRemote(url string, opts *options) (*Remote, error) {
@@ -91,6 +105,7 @@ func NewRemote(url string, opts *options) (*Remote, error) {
 	return &Remote{
 		URL:     url,
 		Options: opts,
+		auth:    auth,
 		Lister: &GoGitRemoteLister{
 			URL:             url,
 			Auth:            auth,
@@ -102,33 +117,44 @@ func NewRemote(url string, opts *options) (*Remote, error) {
 
 // RevisionCommit returns the commit for the given revision
 func (r *Remote) RevisionCommit(revision string) (string, error) {
+	commit, _, err := r.revisionObjects(revision)
+	return commit, err
+}
+
+// revisionObjects returns the commit for the given revision and the object
+// carrying its signature, which is the tag object for annotated tags and the
+// commit otherwise.
+func (r *Remote) revisionObjects(revision string) (string, string, error) {
 	if err := validateCommit(revision); err == nil {
 		// revision is a commit already
-		return revision, nil
+		return revision, revision, nil
 	}
 	refs, err := r.Lister.List(true)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	refLightweightTag := formatRefForTag(revision, false)
 	refAnnotatedTag := formatRefForTag(revision, true)
 	commit := ""
+	tag := ""
 	for _, ref := range refs {
-		// if the annotated form is found, we can return the value now
+		// the annotated form is the peeled commit, the lightweight form
+		// is the tag object in that case
 		if ref.Name == refAnnotatedTag {
-			return ref.Hash, nil
+			commit = ref.Hash
 		}
-		// if the lightweight form is found, we store and keep looking
-		// because we could have the annotated one
 		if ref.Name == refLightweightTag {
-			commit = ref.Hash
+			tag = ref.Hash
 		}
 	}
 	if commit != "" {
-		return commit, nil
+		return commit, tag, nil
+	}
+	if tag != "" {
+		return tag, tag, nil
 	}
-	return "", fmt.Errorf("commit not found for revision: %s", revision)
+	return "", "", fmt.Errorf("commit not found for revision: %s", revision)
 }
 
 // LatestBranchCommit returns the latest commit for the given branch
@@ -175,3 +201,83 @@ func formatRefForTag(tag string, annotated bool) string {
 	}
 	return fmt.Sprintf("refs/tags/%s%s", tag, suffix)
 }
+
+// VerifyObject fetches the commit or annotated tag object with the given
+// hash, without any trees or blobs, and verifies its signature. It returns
+// errPartialFetchUnsupported, if the remote cannot send a single object.
+func (r *Remote) VerifyObject(ctx context.Context, hash string, v *Verifier) error {
+	ep, err := transport.NewEndpoint(r.URL)
+	if err != nil {
+		return err
+	}
+	ep.CaBundle = r.Options.CABundle
+	ep.InsecureSkipTLS = r.Options.InsecureTLSVerify
+
+	c, err := client.NewClient(ep)
+	if err != nil {
+		return err
+	}
+	s, err := c.NewUploadPackSession(ep, r.auth)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	adv, err := s.AdvertisedReferencesContext(ctx)
+	if err != nil {
+		return err
+	}
+	h := plumbing.NewHash(hash)
+	if !adv.Capabilities.Supports(capability.Filter) || !adv.Capabilities.Supports(capability.Shallow) {
+		return errPartialFetchUnsupported
+	}
+	if !advertised(adv, h) && !adv.Capabilities.Supports(capability.AllowReachableSHA1InWant) {
+		return errPartialFetchUnsupported
+	}
+
+	req := packp.NewUploadPackRequestFromCapabilities(adv.Capabilities)
+	if err := req.Capabilities.Set(capability.Shallow); err != nil {
+		return err
+	}
+	if err := req.Capabilities.Set(capability.Filter); err != nil {
+		return err
+	}
+	req.Wants = []plumbing.Hash{h}
+	req.Depth = packp.DepthCommits(1)
+	req.Filter = packp.FilterTreeDepth(0)
+
+	res, err := s.UploadPack(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s for verification: %w", hash, err)
+	}
+	defer res.Close()
+
+	var pack io.Reader = res
+	if req.Capabilities.Supports(capability.Sideband64k) {
+		pack = sideband.NewDemuxer(sideband.Sideband64k, res)
+	} else if req.Capabilities.Supports(capability.Sideband) {
+		pack = sideband.NewDemuxer(sideband.Sideband, res)
+	}
+
+	repo, err := gogit.Init(memory.NewStorage(), nil)
+	if err != nil {
+		return err
+	}
+	if err := packfile.UpdateObjectStorage(repo.Storer, pack); err != nil {
+		return fmt.Errorf("failed to fetch %s for verification: %w", hash, err)
+	}
+	return v.VerifyObject(repo, h)
+}
+
+// advertised returns true if the hash is the tip of an advertised reference.
+func advertised(adv *packp.AdvRefs, h plumbing.Hash) bool {
+	if adv.Head != nil && *adv.Head == h {
+		return true
+	}
+	for _, ref := range adv.References {
+		if ref == h {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,327 @@
+package git
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// VerificationGPGKey is the key of the verification secret, which
+	// contains ASCII armored GPG public keys.
+	VerificationGPGKey = "gpg"
+	// VerificationAllowedSignersKey is the key of the verification secret,
+	// which contains SSH public keys in the format of git's
+	// gpg.ssh.allowedSignersFile.
+	VerificationAllowedSignersKey = "allowed_signers"
+
+	// VerificationFailedExitCode is the exit code of the fleet CLI, if a
+	// signature verification failed.
+	VerificationFailedExitCode = 3
+
+	sshSignatureMagic     = "SSHSIG"
+	sshSignatureNamespace = "git"
+	sshSignatureArmor     = "SSH SIGNATURE"
+)
+
+// ErrVerificationFailed is returned if a commit or tag is not signed, or not
+// signed by a trusted key.
+var ErrVerificationFailed = errors.New("signature verification failed")
+
+// Verifier verifies GPG and SSH signatures of commits and tags against a set
+// of trusted keys.
+type Verifier struct {
+	gpgKeys    openpgp.EntityList
+	sshSigners []allowedSigner
+}
+
+// allowedSigner is a line of an allowed signers file.
+type allowedSigner struct {
+	// principals is a comma separated list of patterns, matching the
+	// email addresses the key may sign for.
+	principals string
+	key        ssh.PublicKey
+}
+
+// NewVerifier returns a verifier trusting the given ASCII armored GPG public
+// keys and SSH allowed signers. Either may be empty.
+func NewVerifier(gpgKeys, allowedSigners []byte) (*Verifier, error) {
+	v := &Verifier{}
+	if len(bytes.TrimSpace(gpgKeys)) > 0 {
+		keys, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(gpgKeys))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GPG keys: %w", err)
+		}
+		v.gpgKeys = keys
+	}
+	signers, err := parseAllowedSigners(allowedSigners)
+	if err != nil {
+		return nil, err
+	}
+	v.sshSigners = signers
+
+	if len(v.gpgKeys) == 0 && len(v.sshSigners) == 0 {
+		return nil, errors.New("no trusted keys configured for signature verification")
+	}
+	return v, nil
+}
+
+// VerifierFromSecret returns a verifier trusting the keys of the verification
+// secret. It returns an error, if the secret contains no trusted keys.
+func VerifierFromSecret(secret *corev1.Secret) (*Verifier, error) {
+	return NewVerifier(secret.Data[VerificationGPGKey], secret.Data[VerificationAllowedSignersKey])
+}
+
+// VerifyCommit returns an error wrapping ErrVerificationFailed, unless the
+// commit is signed by a trusted key. SSH keys must be allowed to sign for the
+// committer's email address.
+func (v *Verifier) VerifyCommit(c *object.Commit) error {
+	if c.PGPSignature == "" {
+		return fmt.Errorf("%w: commit %s is not signed", ErrVerificationFailed, c.Hash)
+	}
+	encoded := &plumbing.MemoryObject{}
+	if err := c.EncodeWithoutSignature(encoded); err != nil {
+		return err
+	}
+	if err := v.verify(encoded, c.PGPSignature, c.Committer.Email); err != nil {
+		return fmt.Errorf("%w: commit %s: %w", ErrVerificationFailed, c.Hash, err)
+	}
+	return nil
+}
+
+// VerifyTag returns an error wrapping ErrVerificationFailed, unless the
+// annotated tag is signed by a trusted key. SSH keys must be allowed to sign
+// for the tagger's email address.
+func (v *Verifier) VerifyTag(t *object.Tag) error {
+	if t.PGPSignature == "" {
+		return fmt.Errorf("%w: tag %s is not signed", ErrVerificationFailed, t.Name)
+	}
+	encoded := &plumbing.MemoryObject{}
+	if err := t.EncodeWithoutSignature(encoded); err != nil {
+		return err
+	}
+	if err := v.verify(encoded, t.PGPSignature, t.Tagger.Email); err != nil {
+		return fmt.Errorf("%w: tag %s: %w", ErrVerificationFailed, t.Name, err)
+	}
+	return nil
+}
+
+// VerifyObject verifies the annotated tag, if the hash refers to one,
+// otherwise the commit.
+func (v *Verifier) VerifyObject(r *gogit.Repository, h plumbing.Hash) error {
+	if tag, err := r.TagObject(h); err == nil {
+		return v.VerifyTag(tag)
+	} else if !errors.Is(err, plumbing.ErrObjectNotFound) {
+		return err
+	}
+	commit, err := r.CommitObject(h)
+	if err != nil {
+		return err
+	}
+	return v.VerifyCommit(commit)
+}
+
+func (v *Verifier) verify(encoded *plumbing.MemoryObject, signature, email string) error {
+	reader, err := encoded.Reader()
+	if err != nil {
+		return err
+	}
+	payload, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(signature), "-----BEGIN "+sshSignatureArmor+"-----") {
+		return v.verifySSH(payload, []byte(signature), email)
+	}
+	if len(v.gpgKeys) == 0 {
+		return errors.New("signed with GPG, but no trusted GPG keys are configured")
+	}
+	if _, err := openpgp.CheckArmoredDetachedSignature(v.gpgKeys, bytes.NewReader(payload), strings.NewReader(signature), nil); err != nil {
+		return err
+	}
+	return nil
+}
+
+// sshSignature is the blob of an SSH signature, following the "SSHSIG"
+// magic, see https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.sshsig
+type sshSignature struct {
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+// sshSignedData is the data signed by an SSH signature, following the
+// "SSHSIG" magic.
+type sshSignedData struct {
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Hash          []byte
+}
+
+func (v *Verifier) verifySSH(payload, armored []byte, email string) error {
+	if len(v.sshSigners) == 0 {
+		return errors.New("signed with SSH, but no allowed signers are configured")
+	}
+
+	block, _ := pem.Decode(armored)
+	if block == nil || block.Type != sshSignatureArmor {
+		return errors.New("invalid SSH signature armor")
+	}
+	blob, ok := bytes.CutPrefix(block.Bytes, []byte(sshSignatureMagic))
+	if !ok {
+		return errors.New("invalid SSH signature magic")
+	}
+	var sig sshSignature
+	if err := ssh.Unmarshal(blob, &sig); err != nil {
+		return fmt.Errorf("invalid SSH signature: %w", err)
+	}
+	if sig.Version != 1 {
+		return fmt.Errorf("unsupported SSH signature version %d", sig.Version)
+	}
+	if sig.Namespace != sshSignatureNamespace {
+		return fmt.Errorf("unexpected SSH signature namespace %q", sig.Namespace)
+	}
+
+	pub, err := ssh.ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		return fmt.Errorf("invalid SSH signature public key: %w", err)
+	}
+	if !v.trustedSSHKey(pub, email) {
+		return fmt.Errorf("SSH key %s is not an allowed signer for %q", ssh.FingerprintSHA256(pub), email)
+	}
+
+	var h hash.Hash
+	switch sig.HashAlgorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return fmt.Errorf("unsupported SSH signature hash algorithm %q", sig.HashAlgorithm)
+	}
+	h.Write(payload)
+
+	signed := append([]byte(sshSignatureMagic), ssh.Marshal(sshSignedData{
+		Namespace:     sig.Namespace,
+		HashAlgorithm: sig.HashAlgorithm,
+		Hash:          h.Sum(nil),
+	})...)
+	var s ssh.Signature
+	if err := ssh.Unmarshal(sig.Signature, &s); err != nil {
+		return fmt.Errorf("invalid SSH signature: %w", err)
+	}
+	return pub.Verify(signed, &s)
+}
+
+// trustedSSHKey returns true if an allowed signer with the key has a
+// principal matching the email address.
+func (v *Verifier) trustedSSHKey(pub ssh.PublicKey, email string) bool {
+	for _, s := range v.sshSigners {
+		if bytes.Equal(s.key.Marshal(), pub.Marshal()) && matchPatternList(email, s.principals) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPatternList returns true if s matches the comma separated list of
+// patterns, like OpenSSH's match_pattern_list. Patterns may contain "*" and
+// "?" wildcards and are negated by a leading "!". A matching negated pattern
+// takes precedence.
+func matchPatternList(s, list string) bool {
+	matched := false
+	for _, pattern := range strings.Split(list, ",") {
+		negated := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+		if !matchPattern(s, pattern) {
+			continue
+		}
+		if negated {
+			return false
+		}
+		matched = true
+	}
+	return matched
+}
+
+// matchPattern matches s against a pattern with "*" and "?" wildcards.
+func matchPattern(s, pattern string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for i := len(s); i >= 0; i-- {
+				if matchPattern(s[i:], pattern[1:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(s) == 0 {
+				return false
+			}
+		default:
+			if len(s) == 0 || s[0] != pattern[0] {
+				return false
+			}
+		}
+		s, pattern = s[1:], pattern[1:]
+	}
+	return len(s) == 0
+}
+
+// parseAllowedSigners parses an allowed signers file. Each line contains
+// principals, optional options and a public key. Lines with a "namespaces"
+// option not matching "git" are skipped, other options are ignored.
+func parseAllowedSigners(data []byte) ([]allowedSigner, error) {
+	var signers []allowedSigner
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		principals, rest := line, ""
+		if n := strings.IndexAny(line, " \t"); n >= 0 {
+			principals, rest = line[:n], line[n+1:]
+		}
+		key, _, options, _, err := ssh.ParseAuthorizedKey([]byte(rest))
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed signers line %d", i+1)
+		}
+		if !gitNamespace(options) {
+			continue
+		}
+		signers = append(signers, allowedSigner{principals: strings.Trim(principals, `"`), key: key})
+	}
+	return signers, nil
+}
+
+// gitNamespace returns false if the options of an allowed signer contain a
+// "namespaces" option, which does not match the "git" namespace.
+func gitNamespace(options []string) bool {
+	for _, opt := range options {
+		name, value, ok := strings.Cut(opt, "=")
+		if !ok || !strings.EqualFold(name, "namespaces") {
+			continue
+		}
+		return matchPatternList(sshSignatureNamespace, strings.Trim(value, `"`))
+	}
+	return true
+}
@@ -0,0 +1,304 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/pem"
+	"io"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func newCommit() *object.Commit {
+	sig := object.Signature{Name: "fleet", Email: "fleet@example.com", When: time.Unix(1700000000, 0).UTC()}
+	return &object.Commit{
+		Author:    sig,
+		Committer: sig,
+		Message:   "deploy",
+		TreeHash:  plumbing.NewHash("4b825dc642cb6eb9a060e54bf8d69288fbee4904"),
+	}
+}
+
+func payload(c *object.Commit) []byte {
+	encoded := &plumbing.MemoryObject{}
+	Expect(c.EncodeWithoutSignature(encoded)).To(Succeed())
+	r, err := encoded.Reader()
+	Expect(err).ToNot(HaveOccurred())
+	data, err := io.ReadAll(r)
+	Expect(err).ToNot(HaveOccurred())
+	return data
+}
+
+func gpgSign(e *openpgp.Entity, data []byte) string {
+	var b bytes.Buffer
+	Expect(openpgp.ArmoredDetachSign(&b, e, bytes.NewReader(data), nil)).To(Succeed())
+	return b.String()
+}
+
+func gpgPublicKey(e *openpgp.Entity) []byte {
+	var b bytes.Buffer
+	w, err := armor.Encode(&b, openpgp.PublicKeyType, nil)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(e.Serialize(w)).To(Succeed())
+	Expect(w.Close()).To(Succeed())
+	return b.Bytes()
+}
+
+// sshSign creates an armored SSH signature, like "ssh-keygen -Y sign -n git".
+func sshSign(signer ssh.Signer, data []byte) string {
+	h := sha512.Sum512(data)
+	signed := append([]byte(sshSignatureMagic), ssh.Marshal(sshSignedData{
+		Namespace:     sshSignatureNamespace,
+		HashAlgorithm: "sha512",
+		Hash:          h[:],
+	})...)
+	sig, err := signer.Sign(rand.Reader, signed)
+	Expect(err).ToNot(HaveOccurred())
+
+	blob := append([]byte(sshSignatureMagic), ssh.Marshal(sshSignature{
+		Version:       1,
+		PublicKey:     signer.PublicKey().Marshal(),
+		Namespace:     sshSignatureNamespace,
+		HashAlgorithm: "sha512",
+		Signature:     ssh.Marshal(sig),
+	})...)
+	return string(pem.EncodeToMemory(&pem.Block{Type: sshSignatureArmor, Bytes: blob}))
+}
+
+func newSSHSigner() ssh.Signer {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+	signer, err := ssh.NewSignerFromKey(key)
+	Expect(err).ToNot(HaveOccurred())
+	return signer
+}
+
+func allowedSigners(signer ssh.Signer) []byte {
+	return []byte("# trusted\nfleet@example.com namespaces=\"git\" " + string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+}
+
+var _ = Describe("git's signature verification", func() {
+	var (
+		trusted, untrusted *openpgp.Entity
+		trustedSSH         ssh.Signer
+		untrustedSSH       ssh.Signer
+		verifier           *Verifier
+		commit             *object.Commit
+	)
+
+	BeforeEach(func() {
+		var err error
+		trusted, err = openpgp.NewEntity("fleet", "", "fleet@example.com", nil)
+		Expect(err).ToNot(HaveOccurred())
+		untrusted, err = openpgp.NewEntity("mallory", "", "mallory@example.com", nil)
+		Expect(err).ToNot(HaveOccurred())
+		trustedSSH = newSSHSigner()
+		untrustedSSH = newSSHSigner()
+
+		verifier, err = NewVerifier(gpgPublicKey(trusted), allowedSigners(trustedSSH))
+		Expect(err).ToNot(HaveOccurred())
+		commit = newCommit()
+	})
+
+	It("requires trusted keys", func() {
+		_, err := NewVerifier(nil, []byte("\n# no keys\n"))
+		Expect(err).To(HaveOccurred())
+
+		_, err = NewVerifier(nil, []byte("fleet@example.com not-a-key"))
+		Expect(err).To(MatchError(ContainSubstring("invalid allowed signers line 1")))
+	})
+
+	It("rejects unsigned commits", func() {
+		Expect(verifier.VerifyCommit(commit)).To(MatchError(ErrVerificationFailed))
+	})
+
+	It("accepts commits signed by a trusted GPG key", func() {
+		commit.PGPSignature = gpgSign(trusted, payload(commit))
+		Expect(verifier.VerifyCommit(commit)).To(Succeed())
+	})
+
+	It("rejects commits signed by an untrusted GPG key", func() {
+		commit.PGPSignature = gpgSign(untrusted, payload(commit))
+		Expect(verifier.VerifyCommit(commit)).To(MatchError(ErrVerificationFailed))
+	})
+
+	It("accepts commits signed by an allowed SSH signer", func() {
+		commit.PGPSignature = sshSign(trustedSSH, payload(commit))
+		Expect(verifier.VerifyCommit(commit)).To(Succeed())
+	})
+
+	It("rejects commits signed by an unknown SSH key", func() {
+		commit.PGPSignature = sshSign(untrustedSSH, payload(commit))
+		Expect(verifier.VerifyCommit(commit)).To(MatchError(ContainSubstring("is not an allowed signer")))
+	})
+
+	It("rejects commits signed by an allowed SSH signer for another email address", func() {
+		commit.Committer.Email = "mallory@example.com"
+		commit.PGPSignature = sshSign(trustedSSH, payload(commit))
+		Expect(verifier.VerifyCommit(commit)).To(MatchError(ContainSubstring(`is not an allowed signer for "mallory@example.com"`)))
+	})
+
+	It("matches principals against patterns", func() {
+		key := string(ssh.MarshalAuthorizedKey(trustedSSH.PublicKey()))
+		v, err := NewVerifier(nil, []byte("*@example.com,!mallory@example.com "+key))
+		Expect(err).ToNot(HaveOccurred())
+
+		commit.PGPSignature = sshSign(trustedSSH, payload(commit))
+		Expect(v.VerifyCommit(commit)).To(Succeed())
+
+		commit = newCommit()
+		commit.Committer.Email = "mallory@example.com"
+		commit.PGPSignature = sshSign(trustedSSH, payload(commit))
+		Expect(v.VerifyCommit(commit)).To(MatchError(ErrVerificationFailed))
+	})
+
+	It("ignores allowed signers for other namespaces", func() {
+		key := string(ssh.MarshalAuthorizedKey(trustedSSH.PublicKey()))
+		_, err := NewVerifier(nil, []byte(`fleet@example.com namespaces="file" `+key))
+		Expect(err).To(MatchError(ContainSubstring("no trusted keys")))
+
+		v, err := NewVerifier(nil, []byte(`fleet@example.com cert-authority,namespaces="file,git" `+key))
+		Expect(err).ToNot(HaveOccurred())
+		commit.PGPSignature = sshSign(trustedSSH, payload(commit))
+		Expect(v.VerifyCommit(commit)).To(Succeed())
+	})
+
+	It("rejects modified commits", func() {
+		commit.PGPSignature = sshSign(trustedSSH, payload(commit))
+		commit.Message = "tampered"
+		Expect(verifier.VerifyCommit(commit)).To(MatchError(ErrVerificationFailed))
+
+		commit = newCommit()
+		commit.PGPSignature = gpgSign(trusted, payload(commit))
+		commit.Message = "tampered"
+		Expect(verifier.VerifyCommit(commit)).To(MatchError(ErrVerificationFailed))
+	})
+
+	It("verifies annotated tags", func() {
+		tag := &object.Tag{
+			Name:       "v1.0.0",
+			Tagger:     commit.Author,
+			Message:    "release",
+			TargetType: plumbing.CommitObject,
+			Target:     plumbing.NewHash("4b825dc642cb6eb9a060e54bf8d69288fbee4904"),
+		}
+		Expect(verifier.VerifyTag(tag)).To(MatchError(ErrVerificationFailed))
+
+		encoded := &plumbing.MemoryObject{}
+		Expect(tag.EncodeWithoutSignature(encoded)).To(Succeed())
+		r, err := encoded.Reader()
+		Expect(err).ToNot(HaveOccurred())
+		data, err := io.ReadAll(r)
+		Expect(err).ToNot(HaveOccurred())
+
+		tag.PGPSignature = gpgSign(trusted, data)
+		Expect(verifier.VerifyTag(tag)).To(Succeed())
+	})
+})
+
+var _ = Describe("git's remote signature verification", func() {
+	var (
+		trusted  *openpgp.Entity
+		verifier *Verifier
+		dir      string
+		repo     *gogit.Repository
+		server   *httptest.Server
+		remote   *Remote
+	)
+
+	commitOpts := func(key *openpgp.Entity) *gogit.CommitOptions {
+		sig := &object.Signature{Name: "fleet", Email: "fleet@example.com", When: time.Unix(1700000000, 0).UTC()}
+		return &gogit.CommitOptions{AllowEmptyCommits: true, Author: sig, Committer: sig, SignKey: key}
+	}
+
+	BeforeEach(func() {
+		execPath, err := exec.Command("git", "--exec-path").Output()
+		if err != nil {
+			Skip("git is not installed")
+		}
+		backend := filepath.Join(strings.TrimSpace(string(execPath)), "git-http-backend")
+
+		trusted, err = openpgp.NewEntity("fleet", "", "fleet@example.com", nil)
+		Expect(err).ToNot(HaveOccurred())
+		verifier, err = NewVerifier(gpgPublicKey(trusted), nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		dir = GinkgoT().TempDir()
+		repo, err = gogit.PlainInit(dir, false)
+		Expect(err).ToNot(HaveOccurred())
+
+		server = httptest.NewServer(&cgi.Handler{
+			Path: backend,
+			Env:  []string{"GIT_PROJECT_ROOT=" + dir, "GIT_HTTP_EXPORT_ALL=1"},
+		})
+		DeferCleanup(server.Close)
+
+		remote, err = NewRemote(server.URL+"/.git", &options{Credential: &corev1.Secret{}})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	allowFilter := func() {
+		Expect(exec.Command("git", "-C", dir, "config", "uploadpack.allowFilter", "true").Run()).To(Succeed())
+	}
+
+	It("verifies signed commits", func() {
+		allowFilter()
+		wt, err := repo.Worktree()
+		Expect(err).ToNot(HaveOccurred())
+		signed, err := wt.Commit("signed", commitOpts(trusted))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(remote.VerifyObject(context.TODO(), signed.String(), verifier)).To(Succeed())
+
+		unsigned, err := wt.Commit("unsigned", commitOpts(nil))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(remote.VerifyObject(context.TODO(), unsigned.String(), verifier)).To(MatchError(ErrVerificationFailed))
+	})
+
+	It("verifies the tag object of annotated tags", func() {
+		allowFilter()
+		wt, err := repo.Worktree()
+		Expect(err).ToNot(HaveOccurred())
+		commit, err := wt.Commit("unsigned", commitOpts(nil))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = repo.CreateTag("v1.0.0", commit, &gogit.CreateTagOptions{
+			Tagger:  commitOpts(nil).Committer,
+			Message: "release",
+			SignKey: trusted,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		peeled, signed, err := remote.revisionObjects("v1.0.0")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(peeled).To(Equal(commit.String()))
+		Expect(signed).ToNot(Equal(commit.String()))
+		Expect(remote.VerifyObject(context.TODO(), signed, verifier)).To(Succeed())
+	})
+
+	It("reports remotes which cannot send single objects", func() {
+		wt, err := repo.Worktree()
+		Expect(err).ToNot(HaveOccurred())
+		commit, err := wt.Commit("signed", commitOpts(trusted))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(remote.VerifyObject(context.TODO(), commit.String(), verifier)).To(MatchError(errPartialFetchUnsupported))
+	})
+})
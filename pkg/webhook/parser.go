@@ -75,7 +75,7 @@ func parseGogs(r *http.Request, secret *corev1.Secret) (interface{}, error) {
 		return nil, err
 	}
 
-	return hook.Parse(r, gogs.PushEvent)
+	return hook.Parse(r, gogs.PushEvent, gogs.PullRequestEvent)
 }
 
 func parseGithub(r *http.Request, secret *corev1.Secret) (interface{}, error) {
@@ -99,7 +99,7 @@ func parseGithub(r *http.Request, secret *corev1.Secret) (interface{}, error) {
 		}
 	}
 
-	return hook.Parse(r, github.PushEvent)
+	return hook.Parse(r, github.PushEvent, github.PullRequestEvent)
 }
 
 func parseGitlab(r *http.Request, secret *corev1.Secret) (interface{}, error) {
@@ -121,7 +121,7 @@ func parseGitlab(r *http.Request, secret *corev1.Secret) (interface{}, error) {
 		return nil, err
 	}
 
-	return hook.Parse(r, gitlab.PushEvents, gitlab.TagEvents)
+	return hook.Parse(r, gitlab.PushEvents, gitlab.TagEvents, gitlab.MergeRequestEvents)
 }
 
 func parseBitbucket(r *http.Request, secret *corev1.Secret) (interface{}, error) {
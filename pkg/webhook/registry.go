@@ -0,0 +1,344 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// dockerHubKey is the secret key of the token, which Docker Hub
+	// sends as the "token" query parameter of the webhook URL.
+	dockerHubKey = "dockerhub"
+	// harborKey is the secret key of the auth header configured in
+	// Harbor, which is sent as the Authorization header.
+	harborKey = "harbor"
+	// distributionKey is the secret key of the Authorization header,
+	// which is configured for the notification endpoint of a registry.
+	distributionKey = "distribution"
+
+	distributionEventsMediaType = "application/vnd.docker.distribution.events.v1+json"
+	defaultGithubRegistryHost   = "ghcr.io"
+)
+
+var errRegistrySecretVerificationFailed = errors.New("registry webhook secret verification failed")
+
+// registryEvent is a push of images to a container registry.
+type registryEvent struct {
+	// key of the shared secret in the webhook secret
+	key string
+	// images are the names of the pushed image repositories, e.g.
+	// "ghcr.io/rancher/fleet"
+	images []string
+}
+
+// matches returns true, if the event pushed to the repository of image.
+func (e registryEvent) matches(image string) bool {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return false
+	}
+	for _, pushed := range e.images {
+		repo, err := name.NewRepository(strings.ToLower(pushed))
+		if err != nil {
+			continue
+		}
+		if repo.Name() == ref.Context().Name() {
+			return true
+		}
+	}
+	return false
+}
+
+// isRegistryWebhook returns true for push notifications of container
+// registries. Docker Hub and Harbor do not send identifying headers, so
+// their payloads are detected by their fields.
+func isRegistryWebhook(r *http.Request, body []byte) bool {
+	switch {
+	case isGithubPackageEvent(r):
+		return true
+	case strings.HasPrefix(r.Header.Get("Content-Type"), distributionEventsMediaType):
+		return true
+	}
+
+	var probe struct {
+		PushData  json.RawMessage `json:"push_data"`
+		Type      string          `json:"type"`
+		EventData json.RawMessage `json:"event_data"`
+	}
+	if err := json.Unmarshal(body, &probe); err != nil {
+		return false
+	}
+	return probe.PushData != nil || (probe.Type != "" && probe.EventData != nil)
+}
+
+func isGithubPackageEvent(r *http.Request) bool {
+	event := r.Header.Get("X-Github-Event")
+	return event == "package" || event == "registry_package"
+}
+
+// parseRegistryWebhook returns the pushed images of a registry webhook.
+// Events, which do not push images, return no images.
+func parseRegistryWebhook(r *http.Request, body []byte) (registryEvent, error) {
+	switch {
+	case isGithubPackageEvent(r):
+		return parseGithubPackage(body)
+	case strings.HasPrefix(r.Header.Get("Content-Type"), distributionEventsMediaType):
+		return parseDistribution(body)
+	}
+
+	var probe struct {
+		PushData json.RawMessage `json:"push_data"`
+	}
+	if err := json.Unmarshal(body, &probe); err != nil {
+		return registryEvent{}, err
+	}
+	if probe.PushData != nil {
+		return parseDockerHub(body)
+	}
+	return parseHarbor(body)
+}
+
+// parseGithubPackage parses the package and registry_package events of
+// GitHub, which are sent for images pushed to GHCR.
+func parseGithubPackage(body []byte) (registryEvent, error) {
+	type githubPackage struct {
+		Name        string `json:"name"`
+		PackageType string `json:"package_type"`
+		Owner       struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+		Registry struct {
+			URL string `json:"url"`
+		} `json:"registry"`
+	}
+	var payload struct {
+		Package         *githubPackage `json:"package"`
+		RegistryPackage *githubPackage `json:"registry_package"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return registryEvent{}, err
+	}
+
+	event := registryEvent{key: githubKey}
+	pkg := payload.Package
+	if pkg == nil {
+		pkg = payload.RegistryPackage
+	}
+	if pkg == nil || pkg.Name == "" || pkg.Owner.Login == "" {
+		return event, nil
+	}
+	switch strings.ToLower(pkg.PackageType) {
+	case "container", "docker":
+	default:
+		return event, nil
+	}
+
+	host := defaultGithubRegistryHost
+	if u, err := url.Parse(pkg.Registry.URL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	event.images = append(event.images, host+"/"+pkg.Owner.Login+"/"+pkg.Name)
+	return event, nil
+}
+
+// parseDistribution parses the notifications of registries based on the
+// distribution registry, which are sent for manifests and blobs.
+func parseDistribution(body []byte) (registryEvent, error) {
+	var payload struct {
+		Events []struct {
+			Action string `json:"action"`
+			Target struct {
+				MediaType  string `json:"mediaType"`
+				Repository string `json:"repository"`
+				URL        string `json:"url"`
+			} `json:"target"`
+			Request struct {
+				Host string `json:"host"`
+			} `json:"request"`
+		} `json:"events"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return registryEvent{}, err
+	}
+
+	event := registryEvent{key: distributionKey}
+	for _, e := range payload.Events {
+		// blobs are pushed before the manifest of the image
+		if e.Action != "push" || e.Target.Repository == "" ||
+			!(strings.Contains(e.Target.MediaType, "manifest") || strings.Contains(e.Target.MediaType, "index")) {
+			continue
+		}
+		host := e.Request.Host
+		if host == "" {
+			if u, err := url.Parse(e.Target.URL); err == nil {
+				host = u.Host
+			}
+		}
+		if host == "" {
+			continue
+		}
+		event.images = append(event.images, host+"/"+e.Target.Repository)
+	}
+	return event, nil
+}
+
+// parseDockerHub parses the push webhooks of Docker Hub repositories.
+func parseDockerHub(body []byte) (registryEvent, error) {
+	var payload struct {
+		Repository struct {
+			RepoName string `json:"repo_name"`
+		} `json:"repository"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return registryEvent{}, err
+	}
+
+	event := registryEvent{key: dockerHubKey}
+	if payload.Repository.RepoName != "" {
+		event.images = append(event.images, name.DefaultRegistry+"/"+payload.Repository.RepoName)
+	}
+	return event, nil
+}
+
+// parseHarbor parses the webhooks of Harbor projects in the default
+// format. Only PUSH_ARTIFACT events push images.
+func parseHarbor(body []byte) (registryEvent, error) {
+	var payload struct {
+		Type      string `json:"type"`
+		EventData struct {
+			Resources []struct {
+				ResourceURL string `json:"resource_url"`
+			} `json:"resources"`
+		} `json:"event_data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return registryEvent{}, err
+	}
+
+	event := registryEvent{key: harborKey}
+	if payload.Type != "PUSH_ARTIFACT" {
+		return event, nil
+	}
+	for _, res := range payload.EventData.Resources {
+		// the resource URL references the pushed tag or digest
+		ref, err := name.ParseReference(res.ResourceURL)
+		if err != nil {
+			continue
+		}
+		event.images = append(event.images, ref.Context().Name())
+	}
+	return event, nil
+}
+
+// handleRegistryWebhook requests a scan of the image scans, whose images
+// were pushed.
+func (w *Webhook) handleRegistryWebhook(rw http.ResponseWriter, r *http.Request, body []byte) {
+	ctx := r.Context()
+
+	event, err := parseRegistryWebhook(r, body)
+	if err != nil {
+		w.logAndReturn(rw, err)
+		return
+	}
+	w.log.V(1).Info("Registry webhook event", "images", event.images)
+
+	if len(event.images) > 0 {
+		var imageScans fleet.ImageScanList
+		if err := w.client.List(ctx, &imageScans); err != nil {
+			w.logAndReturn(rw, err)
+			return
+		}
+
+		// image scans failing the secret check are skipped, the others
+		// are still scanned
+		var verifyErr error
+		requested := time.Now().UTC().Format(time.RFC3339Nano)
+		for _, image := range imageScans.Items {
+			if image.Spec.Suspend || !event.matches(image.Spec.Image) {
+				continue
+			}
+
+			if err := w.verifyRegistrySecret(ctx, r, body, event.key, image); err != nil {
+				w.log.Info("Skipping image scan, registry webhook secret check failed",
+					"namespace", image.Namespace, "name", image.Name, "error", err)
+				if verifyErr == nil {
+					verifyErr = err
+				}
+				continue
+			}
+
+			if err := w.requestScan(ctx, image, requested); err != nil {
+				w.logAndReturn(rw, err)
+				return
+			}
+		}
+
+		if verifyErr != nil {
+			w.logAndReturn(rw, verifyErr)
+			return
+		}
+	}
+
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write([]byte("succeeded"))
+}
+
+// verifyRegistrySecret checks the request against the shared secret of the
+// registry, if a webhook secret is defined for the image scan.
+func (w *Webhook) verifyRegistrySecret(ctx context.Context, r *http.Request, body []byte, key string, image fleet.ImageScan) error {
+	secret, err := w.getSecret(ctx, image.Namespace, image.Spec.WebhookSecret)
+	if err != nil {
+		return err
+	}
+	if secret == nil {
+		return nil
+	}
+	value, err := getValue(secret, key)
+	if err != nil {
+		return err
+	}
+
+	var expected, actual string
+	switch key {
+	case githubKey:
+		mac := hmac.New(sha256.New, []byte(value))
+		_, _ = mac.Write(body)
+		expected = "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		actual = r.Header.Get("X-Hub-Signature-256")
+	case dockerHubKey:
+		expected = value
+		actual = r.URL.Query().Get("token")
+	default:
+		expected = value
+		actual = r.Header.Get("Authorization")
+	}
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(actual)) != 1 {
+		return errRegistrySecretVerificationFailed
+	}
+	return nil
+}
+
+// requestScan annotates the image scan, so it is scanned immediately.
+func (w *Webhook) requestScan(ctx context.Context, image fleet.ImageScan, requested string) error {
+	orig := image.DeepCopy()
+	if image.Annotations == nil {
+		image.Annotations = map[string]string{}
+	}
+	image.Annotations[fleet.ImageScanRequestedAnnotation] = requested
+	return w.client.Patch(ctx, &image, client.MergeFrom(orig))
+}
@@ -0,0 +1,329 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	v1alpha1 "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	cfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParseRegistryWebhook(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		body    string
+		key     string
+		images  []string
+	}{
+		{
+			name:    "github package",
+			headers: map[string]string{"X-Github-Event": "package"},
+			body:    `{"action":"published","package":{"name":"app","package_type":"CONTAINER","owner":{"login":"example"},"registry":{"url":"https://ghcr.io/example"}}}`,
+			key:     githubKey,
+			images:  []string{"ghcr.io/example/app"},
+		},
+		{
+			name:    "github registry package",
+			headers: map[string]string{"X-Github-Event": "registry_package"},
+			body:    `{"action":"published","registry_package":{"name":"app","package_type":"container","owner":{"login":"example"}}}`,
+			key:     githubKey,
+			images:  []string{"ghcr.io/example/app"},
+		},
+		{
+			name:    "github npm package",
+			headers: map[string]string{"X-Github-Event": "package"},
+			body:    `{"action":"published","package":{"name":"app","package_type":"npm","owner":{"login":"example"}}}`,
+			key:     githubKey,
+		},
+		{
+			name:    "distribution",
+			headers: map[string]string{"Content-Type": distributionEventsMediaType},
+			body: `{"events":[
+				{"action":"push","target":{"mediaType":"application/octet-stream","repository":"blobs"},"request":{"host":"registry.example.com"}},
+				{"action":"pull","target":{"mediaType":"application/vnd.oci.image.manifest.v1+json","repository":"pulled"},"request":{"host":"registry.example.com"}},
+				{"action":"push","target":{"mediaType":"application/vnd.oci.image.manifest.v1+json","repository":"team/app"},"request":{"host":"registry.example.com"}},
+				{"action":"push","target":{"mediaType":"application/vnd.oci.image.index.v1+json","repository":"other","url":"https://mirror.example.com/v2/other/manifests/sha256:abc"}}
+			]}`,
+			key:    distributionKey,
+			images: []string{"registry.example.com/team/app", "mirror.example.com/other"},
+		},
+		{
+			name:   "docker hub",
+			body:   `{"callback_url":"https://registry.hub.docker.com/u/example/app/hook/1/","push_data":{"tag":"1.0"},"repository":{"repo_name":"example/app"}}`,
+			key:    dockerHubKey,
+			images: []string{"index.docker.io/example/app"},
+		},
+		{
+			name:   "harbor",
+			body:   `{"type":"PUSH_ARTIFACT","event_data":{"resources":[{"digest":"sha256:abc","tag":"1.0","resource_url":"harbor.example.com/library/app:1.0"}],"repository":{"repo_full_name":"library/app"}}}`,
+			key:    harborKey,
+			images: []string{"harbor.example.com/library/app"},
+		},
+		{
+			name: "harbor delete",
+			body: `{"type":"DELETE_ARTIFACT","event_data":{"resources":[{"resource_url":"harbor.example.com/library/app:1.0"}]}}`,
+			key:  harborKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(tt.body)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			if !isRegistryWebhook(req, []byte(tt.body)) {
+				t.Fatal("expected request to be a registry webhook")
+			}
+			event, err := parseRegistryWebhook(req, []byte(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if event.key != tt.key {
+				t.Errorf("expected secret key %q, got %q", tt.key, event.key)
+			}
+			if diff := cmp.Diff(tt.images, event.images); diff != "" {
+				t.Errorf("unexpected images (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Github-Event", "push")
+	if isRegistryWebhook(req, []byte(`{"ref":"refs/heads/main","repository":{"html_url":"https://github.com/example/repo"}}`)) {
+		t.Error("expected git push not to be a registry webhook")
+	}
+}
+
+func TestRegistryEventMatches(t *testing.T) {
+	event := registryEvent{images: []string{"index.docker.io/library/nginx", "ghcr.io/Example/app"}}
+
+	for image, want := range map[string]bool{
+		"nginx":                    true,
+		"docker.io/library/nginx":  true,
+		"nginx:1.27":               true,
+		"ghcr.io/example/app":      true,
+		"ghcr.io/example/app:v1.0": true,
+		"ghcr.io/example/other":    false,
+		"registry.example.com/app": false,
+		"invalid image":            false,
+	} {
+		if got := event.matches(image); got != want {
+			t.Errorf("matches(%q) = %v, want %v", image, got, want)
+		}
+	}
+}
+
+func TestRegistryWebhook(t *testing.T) {
+	const (
+		namespace = "fleet-local"
+		body      = `{"action":"published","package":{"name":"app","package_type":"CONTAINER","owner":{"login":"example"}}}`
+	)
+	imageScan := func(name, image, secret string, suspend bool) *v1alpha1.ImageScan {
+		return &v1alpha1.ImageScan{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: v1alpha1.ImageScanSpec{
+				Image:         image,
+				WebhookSecret: secret,
+				Suspend:       suspend,
+			},
+		}
+	}
+	sign := func(secret string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		_, _ = mac.Write([]byte(body))
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+
+	tests := []struct {
+		name      string
+		objects   []runtime.Object
+		signature string
+		code      int
+		requested map[string]bool
+	}{
+		{
+			name: "matching image scans are requested",
+			objects: []runtime.Object{
+				imageScan("app", "ghcr.io/example/app", "", false),
+				imageScan("other", "ghcr.io/example/other", "", false),
+				imageScan("suspended", "ghcr.io/example/app", "", true),
+			},
+			code:      http.StatusOK,
+			requested: map[string]bool{"app": true, "other": false, "suspended": false},
+		},
+		{
+			name: "global secret",
+			objects: []runtime.Object{
+				imageScan("app", "ghcr.io/example/app", "", false),
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "cattle-fleet-system", Name: webhookSecretName},
+					Data:       map[string][]byte{githubKey: []byte("global")},
+				},
+			},
+			signature: sign("global"),
+			code:      http.StatusOK,
+			requested: map[string]bool{"app": true},
+		},
+		{
+			name: "image scan secret takes precedence",
+			objects: []runtime.Object{
+				imageScan("app", "ghcr.io/example/app", "app-webhook", false),
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "cattle-fleet-system", Name: webhookSecretName},
+					Data:       map[string][]byte{githubKey: []byte("global")},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "app-webhook"},
+					Data:       map[string][]byte{githubKey: []byte("app")},
+				},
+			},
+			signature: sign("global"),
+			code:      http.StatusUnauthorized,
+			requested: map[string]bool{"app": false},
+		},
+		{
+			name: "image scans failing the secret check are skipped",
+			objects: []runtime.Object{
+				imageScan("a-other-secret", "ghcr.io/example/app", "other-webhook", false),
+				imageScan("app", "ghcr.io/example/app", "app-webhook", false),
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "app-webhook"},
+					Data:       map[string][]byte{githubKey: []byte("app")},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "other-webhook"},
+					Data:       map[string][]byte{githubKey: []byte("other")},
+				},
+			},
+			signature: sign("app"),
+			code:      http.StatusUnauthorized,
+			requested: map[string]bool{"a-other-secret": false, "app": true},
+		},
+		{
+			name: "missing signature",
+			objects: []runtime.Object{
+				imageScan("app", "ghcr.io/example/app", "app-webhook", false),
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "app-webhook"},
+					Data:       map[string][]byte{githubKey: []byte("app")},
+				},
+			},
+			code:      http.StatusUnauthorized,
+			requested: map[string]bool{"app": false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := cfake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(tt.objects...).Build()
+			w := &Webhook{client: client, namespace: "cattle-fleet-system"}
+
+			req, err := http.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(body)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("X-Github-Event", "package")
+			if tt.signature != "" {
+				req.Header.Set("X-Hub-Signature-256", tt.signature)
+			}
+
+			rr := httptest.NewRecorder()
+			w.ServeHTTP(rr, req)
+			if rr.Code != tt.code {
+				t.Fatalf("handler returned wrong status code: got %v want %v: %s", rr.Code, tt.code, rr.Body)
+			}
+
+			for name, want := range tt.requested {
+				image := &v1alpha1.ImageScan{}
+				if err := client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, image); err != nil {
+					t.Fatal(err)
+				}
+				_, got := image.Annotations[v1alpha1.ImageScanRequestedAnnotation]
+				if got != want {
+					t.Errorf("expected scan of %s requested to be %v, got %v", name, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyRegistrySecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+	client := cfake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-local", Name: "registry-webhook"},
+		Data: map[string][]byte{
+			dockerHubKey:    []byte("token"),
+			harborKey:       []byte("Bearer harbor"),
+			distributionKey: []byte("Bearer distribution"),
+		},
+	}).Build()
+	w := &Webhook{client: client}
+	image := v1alpha1.ImageScan{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-local", Name: "app"},
+		Spec:       v1alpha1.ImageScanSpec{WebhookSecret: "registry-webhook"},
+	}
+
+	tests := []struct {
+		name          string
+		key           string
+		url           string
+		authorization string
+		valid         bool
+	}{
+		{name: "docker hub token", key: dockerHubKey, url: "/?token=token", valid: true},
+		{name: "docker hub wrong token", key: dockerHubKey, url: "/?token=wrong"},
+		{name: "harbor auth header", key: harborKey, url: "/", authorization: "Bearer harbor", valid: true},
+		{name: "harbor wrong auth header", key: harborKey, url: "/", authorization: "Bearer distribution"},
+		{name: "distribution auth header", key: distributionKey, url: "/", authorization: "Bearer distribution", valid: true},
+		{name: "distribution missing auth header", key: distributionKey, url: "/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			err = w.verifyRegistrySecret(context.TODO(), req, nil, tt.key, image)
+			if tt.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tt.valid && err != errRegistrySecretVerificationFailed {
+				t.Errorf("expected verification to fail, got %v", err)
+			}
+		})
+	}
+}
@@ -74,6 +74,9 @@ func (w *Webhook) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	case r.Header.Get("X-Github-Event") == "ping":
 		_, _ = rw.Write([]byte("Webhook received successfully"))
 		return
+	case isRegistryWebhook(r, body):
+		w.handleRegistryWebhook(rw, r, body)
+		return
 	default:
 		r.Body = io.NopCloser(bytes.NewBuffer(body))
 		payload, err = parseWebhook(r, nil)
@@ -91,6 +94,10 @@ func (w *Webhook) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	}
 
 	revision, branch, _, repoURLs := parsePayload(payload)
+	pullRequest, pullRequestRepoURLs := parsePullRequestPayload(payload)
+	if pullRequest != "" {
+		repoURLs = pullRequestRepoURLs
+	}
 
 	var gitRepoList fleet.GitRepoList
 	err = w.client.List(ctx, &gitRepoList, &client.ListOptions{LabelSelector: labels.Everything()})
@@ -116,6 +123,29 @@ func (w *Webhook) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 			return
 		}
 		for _, gitrepo := range gitRepoList.Items {
+			if pullRequest != "" {
+				// pull request events only refresh the previews of
+				// gitrepos, which have them enabled
+				if gitrepo.Spec.PullRequests == nil ||
+					!repoRegexp.MatchString(gitrepo.Spec.Repo) ||
+					gitrepo.Status.WebhookPullRequest == pullRequest {
+					continue
+				}
+
+				if err := w.verifySecret(ctx, r, body, gitrepo); err != nil {
+					w.logAndReturn(rw, err)
+					return
+				}
+
+				if err := w.patchStatus(ctx, gitrepo, func(gitrepo *fleet.GitRepo) {
+					gitrepo.Status.WebhookPullRequest = pullRequest
+				}); err != nil {
+					w.logAndReturn(rw, err)
+					return
+				}
+				continue
+			}
+
 			if gitrepo.Spec.Revision != "" {
 				continue
 			}
@@ -132,50 +162,20 @@ func (w *Webhook) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 			}
 
 			if gitrepo.Status.WebhookCommit != revision && revision != "" {
-				// before updating the gitrepo check if a secret was
-				// defined and, if so, verify that it is correct
-				secret, err := w.getSecret(ctx, gitrepo)
-				if err != nil {
+				if err := w.verifySecret(ctx, r, body, gitrepo); err != nil {
 					w.logAndReturn(rw, err)
 					return
 				}
-				if secret != nil {
-					// At this point we know that a secret is defined and exists.
-					// Parse the request again (this time with secret)
-					// We need to parse twice because in the first parsing we didn't
-					// know the gitrepo associated with the webhook payload.
-					// The first parsing is used to get the gitrepo and, if a secret is
-					// defined in the gitrepo, it takes precedence over the global one.
-					r.Body = io.NopCloser(bytes.NewBuffer(body))
-					_, err = parseWebhook(r, secret)
-					if err != nil {
-						w.logAndReturn(rw, err)
-						return
-					}
-				}
 
-				var gitRepoFromCluster fleet.GitRepo
-				err = w.client.Get(
-					ctx,
-					types.NamespacedName{
-						Name:      gitrepo.Name,
-						Namespace: gitrepo.Namespace,
-					}, &gitRepoFromCluster,
-				)
-				if err != nil {
-					w.logAndReturn(rw, err)
-					return
-				}
-				orig := gitRepoFromCluster.DeepCopy()
-				gitRepoFromCluster.Status.WebhookCommit = revision
-				// if PollingInterval is not set and webhook is configured, set it to 1 hour
-				if gitRepoFromCluster.Spec.PollingInterval == nil {
-					gitRepoFromCluster.Spec.PollingInterval = &metav1.Duration{
-						Duration: webhookDefaultSyncInterval * time.Second,
+				if err := w.patchStatus(ctx, gitrepo, func(gitrepo *fleet.GitRepo) {
+					gitrepo.Status.WebhookCommit = revision
+					// if PollingInterval is not set and webhook is configured, set it to 1 hour
+					if gitrepo.Spec.PollingInterval == nil {
+						gitrepo.Spec.PollingInterval = &metav1.Duration{
+							Duration: webhookDefaultSyncInterval * time.Second,
+						}
 					}
-				}
-				p := client.MergeFrom(orig)
-				if err := w.client.Status().Patch(ctx, &gitRepoFromCluster, p); err != nil {
+				}); err != nil {
 					w.logAndReturn(rw, err)
 					return
 				}
@@ -186,6 +186,48 @@ func (w *Webhook) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	_, _ = rw.Write([]byte("succeeded"))
 }
 
+// verifySecret checks the request against the webhook secret, if a secret is
+// defined for the gitrepo.
+func (w *Webhook) verifySecret(ctx context.Context, r *http.Request, body []byte, gitrepo fleet.GitRepo) error {
+	secret, err := w.getSecret(ctx, gitrepo.Namespace, gitrepo.Spec.WebhookSecret)
+	if err != nil {
+		return err
+	}
+	if secret == nil {
+		return nil
+	}
+
+	// At this point we know that a secret is defined and exists.
+	// Parse the request again (this time with secret)
+	// We need to parse twice because in the first parsing we didn't
+	// know the gitrepo associated with the webhook payload.
+	// The first parsing is used to get the gitrepo and, if a secret is
+	// defined in the gitrepo, it takes precedence over the global one.
+	r.Body = io.NopCloser(bytes.NewBuffer(body))
+	_, err = parseWebhook(r, secret)
+	return err
+}
+
+// patchStatus applies update to the latest version of the gitrepo and
+// patches its status.
+func (w *Webhook) patchStatus(ctx context.Context, gitrepo fleet.GitRepo, update func(*fleet.GitRepo)) error {
+	var gitRepoFromCluster fleet.GitRepo
+	err := w.client.Get(
+		ctx,
+		types.NamespacedName{
+			Name:      gitrepo.Name,
+			Namespace: gitrepo.Namespace,
+		}, &gitRepoFromCluster,
+	)
+	if err != nil {
+		return err
+	}
+	orig := gitRepoFromCluster.DeepCopy()
+	update(&gitRepoFromCluster)
+	p := client.MergeFrom(orig)
+	return w.client.Status().Patch(ctx, &gitRepoFromCluster, p)
+}
+
 func HandleHooks(ctx context.Context, namespace string, client client.Client, clientCache cache.Cache) (http.Handler, error) {
 	root := mux.NewRouter()
 	webhook, err := New(namespace, client)
@@ -204,16 +246,18 @@ func (w *Webhook) logAndReturn(rw http.ResponseWriter, err error) {
 	_, _ = rw.Write([]byte(err.Error()))
 }
 
-func (w *Webhook) getSecret(ctx context.Context, gitrepo fleet.GitRepo) (*corev1.Secret, error) {
+// getSecret returns the webhook secret of a resource in namespace, which
+// defines its own secret by name, or the global webhook secret otherwise.
+func (w *Webhook) getSecret(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
 	// global secret first (for backward compatibility)
 	secretName := webhookSecretName
 	ns := w.namespace
 	mustExist := false
-	if gitrepo.Spec.WebhookSecret != "" {
-		// the gitrepo secret takes preference over the global one
-		secretName = gitrepo.Spec.WebhookSecret
-		ns = gitrepo.Namespace
-		mustExist = true // when the secret has been defined in the GitRepo it must exist
+	if name != "" {
+		// the resource's secret takes preference over the global one
+		secretName = name
+		ns = namespace
+		mustExist = true // when the secret has been defined in the resource it must exist
 	}
 	var secret corev1.Secret
 	err := w.client.Get(ctx, types.NamespacedName{Name: secretName, Namespace: ns}, &secret)
@@ -240,7 +284,8 @@ func getErrorCodeFromErr(err error) int {
 		gitlab.ErrGitLabTokenVerificationFailed,
 		bitbucket.ErrUUIDVerificationFailed,
 		bitbucketserver.ErrHMACVerificationFailed,
-		azuredevops.ErrBasicAuthVerificationFailed:
+		azuredevops.ErrBasicAuthVerificationFailed,
+		errRegistrySecretVerificationFailed:
 
 		return http.StatusUnauthorized
 	case
@@ -352,3 +397,26 @@ func parsePayload(payload interface{}) (revision, branch, tag string, repoURLs [
 
 	return revision, branch, tag, repoURLs
 }
+
+// parsePullRequestPayload extracts the pull request event from a request
+// payload, as "<number>:<action>:<head commit>", and the repo URLs. It returns
+// an empty event for payloads which are not pull request events.
+func parsePullRequestPayload(payload interface{}) (event string, repoURLs []string) {
+	switch t := payload.(type) {
+	case github.PullRequestPayload:
+		event = fmt.Sprintf("%d:%s:%s", t.Number, t.Action, t.PullRequest.Head.Sha)
+		repoURLs = append(repoURLs, t.Repository.HTMLURL)
+	case gitlab.MergeRequestEventPayload:
+		event = fmt.Sprintf("%d:%s:%s", t.ObjectAttributes.IID, t.ObjectAttributes.Action, t.ObjectAttributes.LastCommit.ID)
+		repoURLs = append(repoURLs, t.Project.WebURL)
+	case gogsclient.PullRequestPayload:
+		// the gogs payload, which is also used for gitea, does not
+		// contain the head commit
+		event = fmt.Sprintf("%d:%s:", t.Index, t.Action)
+		if t.Repository != nil {
+			repoURLs = append(repoURLs, t.Repository.HTMLURL)
+		}
+	}
+
+	return event, repoURLs
+}
@@ -782,3 +782,66 @@ func TestErrorReadingRequest(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
 	}
 }
+
+func TestGitHubPullRequestWebhook(t *testing.T) {
+	const repoURL = "https://github.com/example/repo"
+	gitRepo := &v1alpha1.GitRepo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test",
+		},
+		Spec: v1alpha1.GitRepoSpec{
+			Repo:         repoURL,
+			Branch:       "main",
+			PullRequests: &v1alpha1.PullRequestPreviews{Provider: "github"},
+		},
+	}
+	withoutPreviews := &v1alpha1.GitRepo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "no-previews",
+		},
+		Spec: v1alpha1.GitRepoSpec{
+			Repo:   repoURL,
+			Branch: "main",
+		},
+	}
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+
+	client := cfake.NewClientBuilder().WithScheme(scheme).
+		WithRuntimeObjects(gitRepo, withoutPreviews).
+		WithStatusSubresource(gitRepo, withoutPreviews).
+		Build()
+	w := &Webhook{client: client}
+
+	jsonBody := []byte(`{"action":"synchronize","number":5,"pull_request":{"number":5,"head":{"ref":"feature","sha":"abc123"},"base":{"ref":"main"}},"repository":{"html_url":"` + repoURL + `"}}`)
+	req, err := http.NewRequest(http.MethodPost, "/", bytes.NewReader(jsonBody))
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	req.Header.Set("X-Github-Event", "pull_request")
+
+	rr := httptest.NewRecorder()
+	w.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v: %s", rr.Code, http.StatusOK, rr.Body)
+	}
+
+	updated := &v1alpha1.GitRepo{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: gitRepo.Name}, updated); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if updated.Status.WebhookPullRequest != "5:synchronize:abc123" {
+		t.Errorf("expected webhook pull request %q, got %q", "5:synchronize:abc123", updated.Status.WebhookPullRequest)
+	}
+	if updated.Status.WebhookCommit != "" {
+		t.Errorf("expected webhook commit to be unchanged, got %q", updated.Status.WebhookCommit)
+	}
+
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: withoutPreviews.Name}, updated); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if updated.Status.WebhookPullRequest != "" {
+		t.Errorf("expected gitrepo without previews to be unchanged, got %q", updated.Status.WebhookPullRequest)
+	}
+}